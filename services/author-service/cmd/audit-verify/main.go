@@ -0,0 +1,66 @@
+// Command audit-verify walks the audit chain and reports the first broken link.
+// Checkpoint signatures are checked against AUDIT_VERIFY_KEY, or the public half of
+// AUDIT_SIGNING_KEY when only that is set. It exits with status 1 when the chain is broken.
+package main
+
+import (
+	"crypto/ed25519"
+	"gin/internal/config"
+	"gin/internal/database"
+	"gin/internal/repositories"
+	"gin/internal/services"
+	"log"
+	"os"
+)
+
+func main() {
+	config.LoadEnv()
+
+	var publicKey ed25519.PublicKey
+	if value := config.GetEnvOr("AUDIT_VERIFY_KEY", ""); value != "" {
+		key, err := services.ParseAuditVerifyKey(value)
+		if err != nil {
+			log.Fatal("Invalid AUDIT_VERIFY_KEY:", err)
+		}
+		publicKey = key
+	} else if value := config.GetEnvOr("AUDIT_SIGNING_KEY", ""); value != "" {
+		key, err := services.ParseAuditSigningKey(value)
+		if err != nil {
+			log.Fatal("Invalid AUDIT_SIGNING_KEY:", err)
+		}
+		publicKey = key.Public().(ed25519.PublicKey)
+	} else {
+		log.Println("Warning: no audit key configured, checkpoint signatures are not checked")
+	}
+
+	db, err := database.ConnectWithEnv()
+	if err != nil {
+		log.Fatal("Failed to connect to database:", err)
+	}
+
+	sqlDB, err := db.DB()
+	if err != nil {
+		log.Fatal("Failed to get database instance:", err)
+	}
+	defer sqlDB.Close()
+
+	auditLogService := services.NewAuditLogService(repositories.NewAuditLogRepository(db), nil)
+
+	log.Println("🔄 Verifying audit chain...")
+
+	report, err := auditLogService.VerifyChain(publicKey)
+	if err != nil {
+		log.Fatal("❌ Failed to verify audit chain:", err)
+	}
+
+	log.Printf("Checked %d entries (%d recorded before chaining) and %d checkpoints",
+		report.Entries, report.Unchained, report.Checkpoints)
+
+	if report.Break != nil {
+		log.Printf("❌ Audit chain broken at entry %d: %s", report.Break.EntryID, report.Break.Reason)
+		sqlDB.Close()
+		os.Exit(1)
+	}
+
+	log.Println("✅ Audit chain is intact")
+}
@@ -2,12 +2,21 @@ package main
 
 import (
 	"context"
+	"crypto/ed25519"
+	"fmt"
+	"gin/internal/auth"
+	"gin/internal/cache"
+	"gin/internal/conditions"
 	"gin/internal/config"
 	"gin/internal/database"
 	"gin/internal/handlers"
+	"gin/internal/middleware"
+	"gin/internal/outbox"
 	"gin/internal/repositories"
 	"gin/internal/services"
+	"gin/pkg/changes"
 	"log"
+	"strings"
 	"time"
 
 	"github.com/gin-gonic/gin"
@@ -32,11 +41,129 @@ func main() {
 		log.Println("✅ Redis connection established")
 	}
 
+	evaluator, err := conditions.NewEvaluator()
+	if err != nil {
+		log.Fatal("Failed to create condition evaluator:", err)
+	}
+
+	strikePolicy, err := services.ParseStrikePolicy(
+		config.GetEnvOr("STRIKE_HALF_LIFE", ""),
+		config.GetEnvOr("STRIKE_ESCALATION_RULES", ""),
+	)
+	if err != nil {
+		log.Fatal("Invalid strike policy:", err)
+	}
+
+	var auditSigningKey ed25519.PrivateKey
+	if value := config.GetEnvOr("AUDIT_SIGNING_KEY", ""); value != "" {
+		if auditSigningKey, err = services.ParseAuditSigningKey(value); err != nil {
+			log.Fatal("Invalid AUDIT_SIGNING_KEY:", err)
+		}
+	} else {
+		log.Println("Warning: AUDIT_SIGNING_KEY is not set, audit checkpoints are disabled")
+	}
+
+	verifierConfig := auth.VerifierConfig{
+		HMACSecret: []byte(config.GetEnvOr("JWT_HS256_SECRET", "")),
+		JWKSURL:    config.GetEnvOr("JWT_JWKS_URL", ""),
+		Issuer:     config.GetEnvOr("JWT_ISSUER", ""),
+		Audience:   config.GetEnvOr("JWT_AUDIENCE", ""),
+	}
+	if value := config.GetEnvOr("JWT_PUBLIC_KEY_FILES", ""); value != "" {
+		verifierConfig.PublicKeyFiles = strings.Split(value, ",")
+	}
+	if verifierConfig.JWKSRefresh, err = config.GetDurationEnvOr("JWT_JWKS_REFRESH", auth.DefaultJWKSRefresh); err != nil {
+		log.Fatal(err)
+	}
+
+	verifier, err := auth.NewVerifier(verifierConfig)
+	if err != nil {
+		log.Fatal("Failed to configure JWT authentication:", err)
+	}
+
+	tokenTTL, err := config.GetDurationEnvOr("PERMISSION_TOKEN_TTL", services.DefaultPermissionTokenTTL)
+	if err != nil {
+		log.Fatal(err)
+	}
+	keyRotation, err := config.GetDurationEnvOr("PERMISSION_TOKEN_KEY_ROTATION", services.DefaultSigningKeyRotation)
+	if err != nil {
+		log.Fatal(err)
+	}
+	keyOverlap, err := config.GetDurationEnvOr("PERMISSION_TOKEN_KEY_OVERLAP", services.DefaultSigningKeyOverlap)
+	if err != nil {
+		log.Fatal(err)
+	}
+	if tokenTTL >= keyRotation-keyOverlap {
+		log.Fatal("PERMISSION_TOKEN_TTL must be shorter than PERMISSION_TOKEN_KEY_ROTATION less PERMISSION_TOKEN_KEY_OVERLAP")
+	}
+
+	signingKeys, err := auth.OpenKeyStore(config.GetEnvOr("PERMISSION_TOKEN_KEY_DIR", "keys"), keyRotation, keyOverlap)
+	if err != nil {
+		log.Fatal("Failed to open permission token keys:", err)
+	}
+
 	repos := repositories.NewRepositories(db)
-	svc := services.NewServices(repos)
+
+	var authorizationCache *cache.AuthorizationCache
+	var changePublisher *changes.Publisher
+	if database.RedisClient != nil {
+		cacheTTL, err := config.GetDurationEnvOr("AUTHZ_CACHE_TTL", cache.DefaultTTL)
+		if err != nil {
+			log.Fatal(err)
+		}
+		authorizationCache = cache.NewAuthorizationCache(database.RedisClient, cacheTTL)
+		repos.UserBan = cache.NewUserBanRepository(repos.UserBan, authorizationCache)
+		repos.UserRole = cache.NewUserRoleRepository(repos.UserRole, authorizationCache)
+		changePublisher = changes.NewPublisher(database.RedisClient, config.GetEnvOr("CHANGE_EVENTS_CHANNEL", changes.DefaultChannel))
+	} else {
+		log.Println("Warning: Redis is unavailable, authorization checks are not cached and changes are not announced")
+	}
+
+	outboxConfig, err := loadOutboxConfig()
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	svc := services.NewServices(repos, evaluator, services.Config{
+		StrikePolicy:       strikePolicy,
+		AuditSigningKey:    auditSigningKey,
+		AuthorizationCache: authorizationCache,
+		Changes:            changePublisher,
+		Outbox:             outboxConfig,
+		PermissionTokens: services.PermissionTokenConfig{
+			Keys:   signingKeys,
+			Issuer: config.GetEnvOr("PERMISSION_TOKEN_ISSUER", services.DefaultPermissionTokenIssuer),
+			TTL:    tokenTTL,
+		},
+	})
 	h := handlers.NewHandlers(svc)
 
+	sweepInterval, err := config.GetDurationEnvOr("BAN_SWEEP_INTERVAL", services.DefaultBanSweepInterval)
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	sweeperCtx, stopSweeper := context.WithCancel(context.Background())
+	defer stopSweeper()
+	go services.RunBanSweeper(sweeperCtx, svc.UserBan, sweepInterval)
+	go services.RunSigningKeyRotation(sweeperCtx, signingKeys)
+
+	dispatchInterval, err := config.GetDurationEnvOr("OUTBOX_DISPATCH_INTERVAL", services.DefaultOutboxDispatchInterval)
+	if err != nil {
+		log.Fatal(err)
+	}
+	go services.RunOutboxDispatcher(sweeperCtx, svc.Outbox, dispatchInterval)
+
+	if auditSigningKey != nil {
+		checkpointInterval, err := config.GetDurationEnvOr("AUDIT_CHECKPOINT_INTERVAL", services.DefaultAuditCheckpointInterval)
+		if err != nil {
+			log.Fatal(err)
+		}
+		go services.RunAuditCheckpointer(sweeperCtx, svc.AuditLog, checkpointInterval)
+	}
+
 	router := gin.Default()
+	router.Use(middleware.RequestID())
 
 	router.GET("/health/db", func(c *gin.Context) {
 		sqlDB, err := db.DB()
@@ -62,8 +189,59 @@ func main() {
 		c.JSON(200, gin.H{"status": "redis healthy"})
 	})
 
-	config.SetupAPIRoutes(router, h)
+	router.GET("/health/cache", func(c *gin.Context) {
+		stats := authorizationCache.Stats()
+		c.JSON(200, gin.H{"enabled": authorizationCache != nil, "stats": stats})
+	})
+
+	config.SetupAPIRoutes(router, h, middleware.Authenticate(verifier, svc.APIKey), middleware.NewAuthorizer(svc.Authorization))
 
 	log.Printf("🚀 Server starting on :%s", port)
 	router.Run(":" + port)
-}
\ No newline at end of file
+}
+
+// loadOutboxConfig reads the outbox sinks and retry policy. OUTBOX_WEBHOOKS lists webhooks as
+// comma-separated name=url pairs, all signed with OUTBOX_WEBHOOK_SECRET; OUTBOX_REDIS_STREAM
+// names a Redis stream to append events to.
+func loadOutboxConfig() (services.OutboxConfig, error) {
+	var outboxConfig services.OutboxConfig
+	var err error
+
+	if value := config.GetEnvOr("OUTBOX_WEBHOOKS", ""); value != "" {
+		secret := config.GetEnvOr("OUTBOX_WEBHOOK_SECRET", "")
+		if secret == "" {
+			return outboxConfig, fmt.Errorf("OUTBOX_WEBHOOK_SECRET is required when OUTBOX_WEBHOOKS is set")
+		}
+
+		for _, webhook := range strings.Split(value, ",") {
+			name, url, found := strings.Cut(strings.TrimSpace(webhook), "=")
+			if !found || name == "" || url == "" {
+				return outboxConfig, fmt.Errorf("invalid OUTBOX_WEBHOOKS entry '%s', expected name=url", webhook)
+			}
+			outboxConfig.Sinks = append(outboxConfig.Sinks, outbox.NewWebhookSink(name, url, []byte(secret), outbox.DefaultWebhookTimeout))
+		}
+	}
+
+	if stream := config.GetEnvOr("OUTBOX_REDIS_STREAM", ""); stream != "" {
+		if database.RedisClient == nil {
+			return outboxConfig, fmt.Errorf("OUTBOX_REDIS_STREAM is set but Redis is unavailable")
+		}
+		maxLen, err := config.GetIntEnvOr("OUTBOX_REDIS_STREAM_MAX_LEN", outbox.DefaultStreamMaxLen)
+		if err != nil {
+			return outboxConfig, err
+		}
+		outboxConfig.Sinks = append(outboxConfig.Sinks, outbox.NewRedisStreamSink(database.RedisClient, stream, int64(maxLen)))
+	}
+
+	if outboxConfig.MaxAttempts, err = config.GetIntEnvOr("OUTBOX_MAX_ATTEMPTS", services.DefaultOutboxMaxAttempts); err != nil {
+		return outboxConfig, err
+	}
+	if outboxConfig.BaseBackoff, err = config.GetDurationEnvOr("OUTBOX_BASE_BACKOFF", services.DefaultOutboxBaseBackoff); err != nil {
+		return outboxConfig, err
+	}
+	if outboxConfig.MaxBackoff, err = config.GetDurationEnvOr("OUTBOX_MAX_BACKOFF", services.DefaultOutboxMaxBackoff); err != nil {
+		return outboxConfig, err
+	}
+
+	return outboxConfig, nil
+}
@@ -26,5 +26,12 @@ func main() {
 		log.Fatal("❌ Failed to migrate database:", err)
 	}
 
+	if adminSubject := config.GetEnvOr("BOOTSTRAP_ADMIN_SUBJECT", ""); adminSubject != "" {
+		if err := database.GrantBootstrapAdmin(db, adminSubject); err != nil {
+			log.Fatal("❌ Failed to grant bootstrap admin:", err)
+		}
+		log.Printf("✅ Granted admin role to %s", adminSubject)
+	}
+
 	log.Println("✅ Database migration completed successfully!")
 }
\ No newline at end of file
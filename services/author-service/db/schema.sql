@@ -11,15 +11,180 @@ CREATE TABLE "permissions" (
 CREATE TABLE "role_permission" (
   "perm_id" int,
   "role_id" int,
-  PRIMARY KEY ("perm_id", "role_id")
+  "resource_type" string DEFAULT '',
+  "resource_id" string DEFAULT '',
+  "condition" text DEFAULT '',
+  "effect" string DEFAULT 'allow',
+  PRIMARY KEY ("role_id", "perm_id", "resource_type", "resource_id")
 );
 
 CREATE TABLE "user_ban" (
   "user_id" string PRIMARY KEY,
   "perm_id" int,
-  "reason" string
+  "reason" string,
+  "kind" string DEFAULT 'suspension',
+  "strike_points" int DEFAULT 0,
+  "escalation_rule" string DEFAULT '',
+  "reason_code" string,
+  "notes" text DEFAULT '',
+  "resource_type" string DEFAULT '',
+  "resource_id" string DEFAULT '',
+  "expires_at" timestamp,
+  "expired_at" timestamp,
+  "issued_by" string DEFAULT '',
+  "lifted_at" timestamp,
+  "lifted_by" string DEFAULT '',
+  "lift_reason" text DEFAULT ''
+);
+
+CREATE TABLE "ban_reasons" (
+  "code" string PRIMARY KEY,
+  "label" string,
+  "player_text" text,
+  "default_kind" string DEFAULT 'suspension',
+  "default_duration_seconds" bigint DEFAULT 0
+);
+
+CREATE TABLE "ban_appeals" (
+  "id" INT GENERATED BY DEFAULT AS IDENTITY PRIMARY KEY,
+  "ban_id" int UNIQUE,
+  "user_id" string,
+  "message" text,
+  "status" string DEFAULT 'pending',
+  "response" text DEFAULT '',
+  "reviewed_by" string DEFAULT '',
+  "reviewed_at" timestamp,
+  "created_at" timestamp,
+  "updated_at" timestamp
+);
+
+CREATE TABLE "ban_appeal_transitions" (
+  "id" INT GENERATED BY DEFAULT AS IDENTITY PRIMARY KEY,
+  "appeal_id" int,
+  "from_status" string DEFAULT '',
+  "to_status" string,
+  "actor" string,
+  "created_at" timestamp
+);
+
+CREATE TABLE "user_ban_exemptions" (
+  "ban_id" int,
+  "perm_id" int,
+  PRIMARY KEY ("ban_id", "perm_id")
+);
+
+CREATE TABLE "user_roles" (
+  "id" INT GENERATED BY DEFAULT AS IDENTITY PRIMARY KEY,
+  "user_id" string,
+  "role_id" int,
+  "created_at" timestamp,
+  UNIQUE ("user_id", "role_id")
+);
+
+-- Values are JSON; users come from the identity provider, so user_id references no table here
+CREATE TABLE "user_attributes" (
+  "user_id" string,
+  "key" string,
+  "value" text,
+  "updated_at" timestamp,
+  PRIMARY KEY ("user_id", "key")
+);
+
+CREATE TABLE "role_parents" (
+  "role_id" int,
+  "parent_id" int,
+  PRIMARY KEY ("role_id", "parent_id")
+);
+
+-- Append-only: a trigger rejects UPDATE, DELETE and TRUNCATE. Each entry's hash covers the
+-- previous entry's hash and its own contents.
+CREATE TABLE "audit_logs" (
+  "id" INT GENERATED BY DEFAULT AS IDENTITY PRIMARY KEY,
+  "actor" string,
+  "action" string,
+  "target_type" string,
+  "target_id" string DEFAULT '',
+  "before" json,
+  "after" json,
+  "request_id" string DEFAULT '',
+  "created_at" timestamp,
+  "prev_hash" string DEFAULT '',
+  "hash" string DEFAULT ''
+);
+
+-- Append-only; Ed25519 signatures over the hash of the latest entry at the time
+CREATE TABLE "audit_checkpoints" (
+  "id" INT GENERATED BY DEFAULT AS IDENTITY PRIMARY KEY,
+  "entry_id" int,
+  "entry_hash" string,
+  "key_id" string,
+  "signature" string,
+  "created_at" timestamp
+);
+
+-- Only the SHA-256 of each key is stored; scopes are space-separated permission names
+CREATE TABLE "api_keys" (
+  "id" INT GENERATED BY DEFAULT AS IDENTITY PRIMARY KEY,
+  "name" string,
+  "prefix" string,
+  "key_hash" string UNIQUE,
+  "scopes" string,
+  "created_by" string,
+  "rotated_from" int,
+  "expires_at" timestamp,
+  "last_used_at" timestamp,
+  "revoked_at" timestamp,
+  "created_at" timestamp
+);
+
+-- Domain events written in the same transaction as the change they report
+CREATE TABLE "outbox_events" (
+  "id" INT GENERATED BY DEFAULT AS IDENTITY PRIMARY KEY,
+  "type" string,
+  "aggregate_type" string,
+  "aggregate_id" string DEFAULT '',
+  "payload" jsonb,
+  "actor" string DEFAULT '',
+  "request_id" string DEFAULT '',
+  "created_at" timestamp,
+  "fanned_out_at" timestamp
+);
+
+-- One row per event and sink; status is pending, delivered or dead
+CREATE TABLE "outbox_deliveries" (
+  "id" INT GENERATED BY DEFAULT AS IDENTITY PRIMARY KEY,
+  "event_id" int,
+  "sink" string,
+  "status" string,
+  "attempts" int DEFAULT 0,
+  "next_attempt_at" timestamp,
+  "last_error" text DEFAULT '',
+  "delivered_at" timestamp,
+  "created_at" timestamp,
+  "updated_at" timestamp,
+  UNIQUE ("event_id", "sink")
+);
+
+CREATE TABLE "outbox_dead_letters" (
+  "id" INT GENERATED BY DEFAULT AS IDENTITY PRIMARY KEY,
+  "delivery_id" int,
+  "event_id" int,
+  "sink" string,
+  "attempts" int,
+  "last_error" text DEFAULT '',
+  "created_at" timestamp,
+  "retried_at" timestamp
 );
 
 ALTER TABLE "role_permission" ADD FOREIGN KEY ("role_id") REFERENCES "roles" ("role_id");
 ALTER TABLE "role_permission" ADD FOREIGN KEY ("perm_id") REFERENCES "permissions" ("perm_id");
-ALTER TABLE "user_ban" ADD FOREIGN KEY ("perm_id") REFERENCES "permissions" ("perm_id");
\ No newline at end of file
+ALTER TABLE "user_ban" ADD FOREIGN KEY ("perm_id") REFERENCES "permissions" ("perm_id");
+ALTER TABLE "user_ban" ADD FOREIGN KEY ("reason_code") REFERENCES "ban_reasons" ("code");
+ALTER TABLE "ban_appeal_transitions" ADD FOREIGN KEY ("appeal_id") REFERENCES "ban_appeals" ("id") ON DELETE CASCADE;
+ALTER TABLE "user_ban_exemptions" ADD FOREIGN KEY ("perm_id") REFERENCES "permissions" ("perm_id");
+ALTER TABLE "user_roles" ADD FOREIGN KEY ("role_id") REFERENCES "roles" ("role_id") ON DELETE CASCADE;
+ALTER TABLE "role_parents" ADD FOREIGN KEY ("role_id") REFERENCES "roles" ("role_id") ON DELETE CASCADE;
+ALTER TABLE "role_parents" ADD FOREIGN KEY ("parent_id") REFERENCES "roles" ("role_id") ON DELETE CASCADE;
+ALTER TABLE "outbox_deliveries" ADD FOREIGN KEY ("event_id") REFERENCES "outbox_events" ("id");
+ALTER TABLE "outbox_dead_letters" ADD FOREIGN KEY ("delivery_id") REFERENCES "outbox_deliveries" ("id");
+ALTER TABLE "outbox_dead_letters" ADD FOREIGN KEY ("event_id") REFERENCES "outbox_events" ("id");
\ No newline at end of file
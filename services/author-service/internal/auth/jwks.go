@@ -0,0 +1,198 @@
+package auth
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rsa"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"log"
+	"math/big"
+	"net/http"
+	"sync"
+	"time"
+)
+
+// DefaultJWKSRefresh is how long fetched JWKS keys are used before they are fetched again
+const DefaultJWKSRefresh = 15 * time.Minute
+
+// jwksMinRefetch limits how often an unknown key ID can trigger a fetch, and how soon a fetch is
+// retried after failing
+const jwksMinRefetch = time.Minute
+
+// jwksCache holds the keys served at a JWKS URL, fetching them lazily and again once stale
+type jwksCache struct {
+	url     string
+	refresh time.Duration
+	client  *http.Client
+
+	// fetchMu lets one fetch run at a time
+	fetchMu sync.Mutex
+
+	mu        sync.RWMutex
+	cached    map[string]interface{}
+	fetchedAt time.Time
+	failedAt  time.Time
+}
+
+type jwkSet struct {
+	Keys []jwk `json:"keys"`
+}
+
+type jwk struct {
+	Kid string `json:"kid"`
+	Kty string `json:"kty"`
+	Use string `json:"use"`
+	N   string `json:"n"`
+	E   string `json:"e"`
+	Crv string `json:"crv"`
+	X   string `json:"x"`
+	Y   string `json:"y"`
+}
+
+func newJWKSCache(url string, refresh time.Duration) *jwksCache {
+	if refresh <= 0 {
+		refresh = DefaultJWKSRefresh
+	}
+	return &jwksCache{
+		url:     url,
+		refresh: refresh,
+		client:  &http.Client{Timeout: 10 * time.Second},
+	}
+}
+
+// keys returns the cached keys by key ID, fetching them first when they are stale. A failed
+// fetch keeps the previous keys and is not retried for jwksMinRefetch.
+func (c *jwksCache) keys() map[string]interface{} {
+	c.mu.RLock()
+	keys, stale := c.cached, c.stale()
+	c.mu.RUnlock()
+
+	if stale {
+		if err := c.refreshIf(c.stale); err != nil {
+			log.Printf("JWKS: %v", err)
+		}
+		c.mu.RLock()
+		keys = c.cached
+		c.mu.RUnlock()
+	}
+
+	return keys
+}
+
+// refreshForUnknownKey fetches the keys again unless they were fetched, or failed to be, very
+// recently
+func (c *jwksCache) refreshForUnknownKey() error {
+	return c.refreshIf(func() bool {
+		return time.Since(c.fetchedAt) >= jwksMinRefetch && time.Since(c.failedAt) >= jwksMinRefetch
+	})
+}
+
+// stale reports whether the keys are due to be fetched again; c.mu must be held
+func (c *jwksCache) stale() bool {
+	return time.Since(c.fetchedAt) > c.refresh && time.Since(c.failedAt) >= jwksMinRefetch
+}
+
+// refreshIf fetches the keys if due, called with c.mu held, reports that they need it. Callers
+// that arrive while a fetch runs wait for it and then find it has made the fetch unnecessary,
+// so the JWKS URL sees one request at a time however many requests need the keys.
+func (c *jwksCache) refreshIf(due func() bool) error {
+	c.fetchMu.Lock()
+	defer c.fetchMu.Unlock()
+
+	c.mu.RLock()
+	needed := due()
+	c.mu.RUnlock()
+	if !needed {
+		return nil
+	}
+
+	if err := c.fetch(); err != nil {
+		c.mu.Lock()
+		c.failedAt = time.Now()
+		c.mu.Unlock()
+		return err
+	}
+
+	return nil
+}
+
+func (c *jwksCache) fetch() error {
+	resp, err := c.client.Get(c.url)
+	if err != nil {
+		return fmt.Errorf("failed to fetch %s: %w", c.url, err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("failed to fetch %s: status %d", c.url, resp.StatusCode)
+	}
+
+	var set jwkSet
+	if err := json.NewDecoder(resp.Body).Decode(&set); err != nil {
+		return fmt.Errorf("failed to decode %s: %w", c.url, err)
+	}
+
+	keys := make(map[string]interface{}, len(set.Keys))
+	for _, key := range set.Keys {
+		if key.Use != "" && key.Use != "sig" {
+			continue
+		}
+		publicKey, err := key.publicKey()
+		if err != nil {
+			log.Printf("JWKS: skipping key %s: %v", key.Kid, err)
+			continue
+		}
+		keys[key.Kid] = publicKey
+	}
+
+	c.mu.Lock()
+	c.cached = keys
+	c.fetchedAt = time.Now()
+	c.mu.Unlock()
+
+	return nil
+}
+
+func (k jwk) publicKey() (interface{}, error) {
+	switch k.Kty {
+	case "RSA":
+		n, err := decodeBigInt(k.N)
+		if err != nil {
+			return nil, err
+		}
+		e, err := decodeBigInt(k.E)
+		if err != nil {
+			return nil, err
+		}
+		return &rsa.PublicKey{N: n, E: int(e.Int64())}, nil
+	case "EC":
+		if k.Crv != "P-256" {
+			return nil, fmt.Errorf("unsupported curve %s", k.Crv)
+		}
+		x, err := decodeBigInt(k.X)
+		if err != nil {
+			return nil, err
+		}
+		y, err := decodeBigInt(k.Y)
+		if err != nil {
+			return nil, err
+		}
+		key := &ecdsa.PublicKey{Curve: elliptic.P256(), X: x, Y: y}
+		if !key.Curve.IsOnCurve(x, y) {
+			return nil, fmt.Errorf("point is not on curve %s", k.Crv)
+		}
+		return key, nil
+	default:
+		return nil, fmt.Errorf("unsupported key type %s", k.Kty)
+	}
+}
+
+func decodeBigInt(value string) (*big.Int, error) {
+	raw, err := base64.RawURLEncoding.DecodeString(value)
+	if err != nil {
+		return nil, fmt.Errorf("invalid key parameter: %w", err)
+	}
+	return new(big.Int).SetBytes(raw), nil
+}
@@ -0,0 +1,50 @@
+package auth
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestJWKSCacheBacksOffWhileTheURLFails(t *testing.T) {
+	var requests int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&requests, 1)
+		time.Sleep(50 * time.Millisecond)
+		w.WriteHeader(http.StatusServiceUnavailable)
+	}))
+	defer server.Close()
+
+	cache := newJWKSCache(server.URL, time.Minute)
+
+	var wg sync.WaitGroup
+	for i := 0; i < 20; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			cache.keys()
+		}()
+	}
+	wg.Wait()
+
+	if got := atomic.LoadInt32(&requests); got != 1 {
+		t.Fatalf("concurrent keys() made %d requests, want 1", got)
+	}
+
+	cache.keys()
+	if err := cache.refreshForUnknownKey(); err != nil {
+		t.Errorf("refreshForUnknownKey() right after a failure error = %v", err)
+	}
+	if got := atomic.LoadInt32(&requests); got != 1 {
+		t.Errorf("keys() after a failure made %d requests, want none until jwksMinRefetch", got-1)
+	}
+
+	cache.failedAt = time.Now().Add(-jwksMinRefetch)
+	cache.keys()
+	if got := atomic.LoadInt32(&requests); got != 2 {
+		t.Errorf("keys() once the back-off ran out made %d requests, want 1", got-1)
+	}
+}
@@ -0,0 +1,205 @@
+package auth
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/pem"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// signingKeyFilePrefix names key files <prefix><unix creation time>.pem; the base name is the key ID
+const signingKeyFilePrefix = "perm-"
+
+// SigningKey is an ES256 key the service signs its own tokens with
+type SigningKey struct {
+	ID        string
+	Private   *ecdsa.PrivateKey
+	CreatedAt time.Time
+}
+
+// KeyStore keeps the service's signing keys as PEM files in a local directory. A new key is
+// published for Overlap before it is used to sign, so that verifiers fetching the JWKS know it
+// by the time tokens carry it; the key it replaces stays published as the previous key.
+type KeyStore struct {
+	dir      string
+	rotation time.Duration
+	overlap  time.Duration
+
+	mu   sync.RWMutex
+	keys []SigningKey // oldest first
+}
+
+// OpenKeyStore loads the keys in dir, creating the directory and a first key when there are none
+func OpenKeyStore(dir string, rotation, overlap time.Duration) (*KeyStore, error) {
+	if rotation <= overlap {
+		return nil, fmt.Errorf("key rotation interval must be longer than the overlap")
+	}
+
+	if err := os.MkdirAll(dir, 0o700); err != nil {
+		return nil, fmt.Errorf("failed to create key directory: %w", err)
+	}
+
+	store := &KeyStore{dir: dir, rotation: rotation, overlap: overlap}
+	if err := store.load(); err != nil {
+		return nil, err
+	}
+
+	if len(store.keys) == 0 {
+		if _, err := store.generate(time.Now().Add(-overlap)); err != nil {
+			return nil, err
+		}
+	}
+
+	return store, nil
+}
+
+func (s *KeyStore) load() error {
+	paths, err := filepath.Glob(filepath.Join(s.dir, signingKeyFilePrefix+"*.pem"))
+	if err != nil {
+		return err
+	}
+
+	for _, path := range paths {
+		id := strings.TrimSuffix(filepath.Base(path), ".pem")
+		created, err := strconv.ParseInt(strings.TrimPrefix(id, signingKeyFilePrefix), 10, 64)
+		if err != nil {
+			continue
+		}
+
+		data, err := os.ReadFile(path)
+		if err != nil {
+			return fmt.Errorf("failed to read signing key %s: %w", path, err)
+		}
+		block, _ := pem.Decode(data)
+		if block == nil {
+			return fmt.Errorf("signing key %s is not PEM encoded", path)
+		}
+		private, err := x509.ParseECPrivateKey(block.Bytes)
+		if err != nil {
+			return fmt.Errorf("failed to parse signing key %s: %w", path, err)
+		}
+
+		s.keys = append(s.keys, SigningKey{ID: id, Private: private, CreatedAt: time.Unix(created, 0)})
+	}
+
+	sort.Slice(s.keys, func(i, j int) bool { return s.keys[i].CreatedAt.Before(s.keys[j].CreatedAt) })
+	return nil
+}
+
+// generate writes a new key created at the given time
+func (s *KeyStore) generate(createdAt time.Time) (*SigningKey, error) {
+	private, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		return nil, fmt.Errorf("failed to generate signing key: %w", err)
+	}
+
+	der, err := x509.MarshalECPrivateKey(private)
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode signing key: %w", err)
+	}
+
+	key := SigningKey{
+		ID:        signingKeyFilePrefix + strconv.FormatInt(createdAt.Unix(), 10),
+		Private:   private,
+		CreatedAt: time.Unix(createdAt.Unix(), 0),
+	}
+
+	path := filepath.Join(s.dir, key.ID+".pem")
+	data := pem.EncodeToMemory(&pem.Block{Type: "EC PRIVATE KEY", Bytes: der})
+	if err := os.WriteFile(path, data, 0o600); err != nil {
+		return nil, fmt.Errorf("failed to write signing key: %w", err)
+	}
+
+	s.keys = append(s.keys, key)
+	return &key, nil
+}
+
+// currentIndex is the newest key whose overlap has passed, or the oldest key when none has
+func (s *KeyStore) currentIndex(now time.Time) int {
+	for i := len(s.keys) - 1; i >= 0; i-- {
+		if !s.keys[i].CreatedAt.Add(s.overlap).After(now) {
+			return i
+		}
+	}
+	return 0
+}
+
+// Current returns the key tokens are signed with now
+func (s *KeyStore) Current() SigningKey {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s.keys[s.currentIndex(time.Now())]
+}
+
+// Published returns the keys verifiers must know: the previous, current and upcoming keys
+func (s *KeyStore) Published() []SigningKey {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	first := s.currentIndex(time.Now()) - 1
+	if first < 0 {
+		first = 0
+	}
+	return append([]SigningKey(nil), s.keys[first:]...)
+}
+
+// RotateIfDue creates the next key once the newest one is a rotation interval old, less the
+// overlap, and deletes the keys older than the previous key. It reports whether a key was created.
+func (s *KeyStore) RotateIfDue(now time.Time) (bool, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	rotated := false
+	newest := s.keys[len(s.keys)-1]
+	if !newest.CreatedAt.Add(s.rotation - s.overlap).After(now) {
+		if _, err := s.generate(now); err != nil {
+			return false, err
+		}
+		rotated = true
+	}
+
+	for s.currentIndex(now) > 1 {
+		path := filepath.Join(s.dir, s.keys[0].ID+".pem")
+		if err := os.Remove(path); err != nil && !os.IsNotExist(err) {
+			return rotated, fmt.Errorf("failed to remove retired signing key: %w", err)
+		}
+		s.keys = s.keys[1:]
+	}
+
+	return rotated, nil
+}
+
+// JWK is the public half of a signing key in JSON Web Key form
+type JWK struct {
+	Kty string `json:"kty"`
+	Crv string `json:"crv"`
+	X   string `json:"x"`
+	Y   string `json:"y"`
+	Kid string `json:"kid"`
+	Use string `json:"use"`
+	Alg string `json:"alg"`
+}
+
+// PublicJWK returns the key's public half for a JWKS document
+func (k SigningKey) PublicJWK() JWK {
+	size := (k.Private.Curve.Params().BitSize + 7) / 8
+	return JWK{
+		Kty: "EC",
+		Crv: "P-256",
+		X:   base64.RawURLEncoding.EncodeToString(k.Private.X.FillBytes(make([]byte, size))),
+		Y:   base64.RawURLEncoding.EncodeToString(k.Private.Y.FillBytes(make([]byte, size))),
+		Kid: k.ID,
+		Use: "sig",
+		Alg: "ES256",
+	}
+}
@@ -0,0 +1,130 @@
+package auth
+
+import (
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestRotateIfDue(t *testing.T) {
+	start := time.Unix(1700000000, 0)
+	at := func(hours int) time.Time { return start.Add(time.Duration(hours) * time.Hour) }
+
+	tests := []struct {
+		name        string
+		keys        []time.Time // creation times of the existing keys
+		now         time.Time
+		wantRotated bool
+		wantKeys    []time.Time // creation times of the keys left, oldest first
+		wantCurrent time.Time
+	}{
+		{
+			name:        "not due",
+			keys:        []time.Time{at(0)},
+			now:         at(22),
+			wantKeys:    []time.Time{at(0)},
+			wantCurrent: at(0),
+		},
+		{
+			name:        "due an overlap before the rotation interval",
+			keys:        []time.Time{at(0)},
+			now:         at(23),
+			wantRotated: true,
+			wantKeys:    []time.Time{at(0), at(23)},
+			wantCurrent: at(0),
+		},
+		{
+			name:        "upcoming key becomes current after the overlap",
+			keys:        []time.Time{at(0), at(23)},
+			now:         at(24),
+			wantKeys:    []time.Time{at(0), at(23)},
+			wantCurrent: at(23),
+		},
+		{
+			name:        "key older than the previous key is retired",
+			keys:        []time.Time{at(0), at(23), at(46)},
+			now:         at(47),
+			wantKeys:    []time.Time{at(23), at(46)},
+			wantCurrent: at(46),
+		},
+		{
+			name:        "rotation after downtime keeps signing with the old key during the overlap",
+			keys:        []time.Time{at(0)},
+			now:         at(100),
+			wantRotated: true,
+			wantKeys:    []time.Time{at(0), at(100)},
+			wantCurrent: at(0),
+		},
+		{
+			name:        "several overdue keys are retired at once",
+			keys:        []time.Time{at(0), at(23), at(46), at(69)},
+			now:         at(80),
+			wantKeys:    []time.Time{at(46), at(69)},
+			wantCurrent: at(69),
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dir := t.TempDir()
+			store := &KeyStore{dir: dir, rotation: 24 * time.Hour, overlap: time.Hour}
+			for _, createdAt := range tt.keys {
+				if _, err := store.generate(createdAt); err != nil {
+					t.Fatal(err)
+				}
+			}
+
+			rotated, err := store.RotateIfDue(tt.now)
+			if err != nil {
+				t.Fatalf("RotateIfDue() error = %v", err)
+			}
+			if rotated != tt.wantRotated {
+				t.Errorf("RotateIfDue() = %v, want %v", rotated, tt.wantRotated)
+			}
+
+			if len(store.keys) != len(tt.wantKeys) {
+				t.Fatalf("keys = %d, want %d", len(store.keys), len(tt.wantKeys))
+			}
+			for i, want := range tt.wantKeys {
+				if !store.keys[i].CreatedAt.Equal(want) {
+					t.Errorf("key %d created at %v, want %v", i, store.keys[i].CreatedAt, want)
+				}
+			}
+			if current := store.keys[store.currentIndex(tt.now)]; !current.CreatedAt.Equal(tt.wantCurrent) {
+				t.Errorf("current key created at %v, want %v", current.CreatedAt, tt.wantCurrent)
+			}
+
+			// The key files left on disk are the keys a restart loads
+			reopened, err := OpenKeyStore(dir, 24*time.Hour, time.Hour)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if len(reopened.keys) != len(store.keys) {
+				t.Fatalf("reopened keys = %d, want %d", len(reopened.keys), len(store.keys))
+			}
+			for i := range store.keys {
+				if reopened.keys[i].ID != store.keys[i].ID || !reopened.keys[i].Private.Equal(store.keys[i].Private) {
+					t.Errorf("reopened key %d = %s, want %s", i, reopened.keys[i].ID, store.keys[i].ID)
+				}
+			}
+		})
+	}
+}
+
+func TestOpenKeyStore(t *testing.T) {
+	if _, err := OpenKeyStore(t.TempDir(), time.Hour, time.Hour); err == nil {
+		t.Error("OpenKeyStore() error = nil, want an error for a rotation no longer than the overlap")
+	}
+
+	dir := filepath.Join(t.TempDir(), "keys")
+	store, err := OpenKeyStore(dir, 24*time.Hour, time.Hour)
+	if err != nil {
+		t.Fatalf("OpenKeyStore() error = %v", err)
+	}
+	if len(store.keys) != 1 {
+		t.Fatalf("keys = %d, want a first key", len(store.keys))
+	}
+	if current := store.Current(); current.ID != store.keys[0].ID {
+		t.Errorf("Current() = %s, want the first key %s to sign at once", current.ID, store.keys[0].ID)
+	}
+}
@@ -0,0 +1,212 @@
+// Package auth verifies the bearer JWTs that authenticate callers of the admin API
+package auth
+
+import (
+	"crypto/ecdsa"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/pem"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+)
+
+// DefaultLeeway is the clock skew tolerated when checking a token's time claims
+const DefaultLeeway = 30 * time.Second
+
+// VerifierConfig lists the keys tokens may be signed with and the claims they must carry
+type VerifierConfig struct {
+	// HMACSecret verifies HS256 tokens
+	HMACSecret []byte
+	// PublicKeyFiles are PEM public keys or certificates verifying RS256 and ES256 tokens; a
+	// file's name without its extension is the key ID tokens name in their kid header
+	PublicKeyFiles []string
+	// JWKSURL serves further RS256 and ES256 keys, refreshed every JWKSRefresh
+	JWKSURL     string
+	JWKSRefresh time.Duration
+	// Issuer and Audience, when set, must match the iss and aud claims
+	Issuer   string
+	Audience string
+	Leeway   time.Duration
+}
+
+// Principal is the authenticated caller of a request
+type Principal struct {
+	Subject string
+	Claims  jwt.MapClaims
+}
+
+// Verifier checks bearer tokens against the configured keys
+type Verifier struct {
+	hmacSecret []byte
+	localKeys  map[string]interface{}
+	jwks       *jwksCache
+	parser     *jwt.Parser
+}
+
+// NewVerifier loads the configured keys; at least one key source is required
+func NewVerifier(config VerifierConfig) (*Verifier, error) {
+	v := &Verifier{
+		hmacSecret: config.HMACSecret,
+		localKeys:  make(map[string]interface{}),
+	}
+
+	for _, path := range config.PublicKeyFiles {
+		key, err := loadPublicKey(path)
+		if err != nil {
+			return nil, err
+		}
+		kid := strings.TrimSuffix(filepath.Base(path), filepath.Ext(path))
+		v.localKeys[kid] = key
+	}
+
+	if config.JWKSURL != "" {
+		v.jwks = newJWKSCache(config.JWKSURL, config.JWKSRefresh)
+	}
+
+	if len(v.hmacSecret) == 0 && len(v.localKeys) == 0 && v.jwks == nil {
+		return nil, fmt.Errorf("no JWT verification key configured")
+	}
+
+	var methods []string
+	if len(v.hmacSecret) > 0 {
+		methods = append(methods, jwt.SigningMethodHS256.Alg())
+	}
+	if len(v.localKeys) > 0 || v.jwks != nil {
+		methods = append(methods, jwt.SigningMethodRS256.Alg(), jwt.SigningMethodES256.Alg())
+	}
+
+	leeway := config.Leeway
+	if leeway == 0 {
+		leeway = DefaultLeeway
+	}
+
+	options := []jwt.ParserOption{
+		jwt.WithValidMethods(methods),
+		jwt.WithExpirationRequired(),
+		jwt.WithLeeway(leeway),
+	}
+	if config.Issuer != "" {
+		options = append(options, jwt.WithIssuer(config.Issuer))
+	}
+	if config.Audience != "" {
+		options = append(options, jwt.WithAudience(config.Audience))
+	}
+	v.parser = jwt.NewParser(options...)
+
+	return v, nil
+}
+
+// Verify parses a token, checks its signature and claims, and returns the caller it names
+func (v *Verifier) Verify(tokenString string) (*Principal, error) {
+	claims := jwt.MapClaims{}
+	if _, err := v.parser.ParseWithClaims(tokenString, claims, v.keyFor); err != nil {
+		return nil, err
+	}
+
+	subject, err := claims.GetSubject()
+	if err != nil || subject == "" {
+		return nil, fmt.Errorf("token has no subject")
+	}
+
+	return &Principal{Subject: subject, Claims: claims}, nil
+}
+
+// keyFor picks the keys that may have signed a token: the shared secret for HS256, and for
+// RS256/ES256 the key named by the kid header, or every key of the right type when kid is absent
+func (v *Verifier) keyFor(token *jwt.Token) (interface{}, error) {
+	if token.Method.Alg() == jwt.SigningMethodHS256.Alg() {
+		return v.hmacSecret, nil
+	}
+
+	kid, _ := token.Header["kid"].(string)
+	keys := v.publicKeys(kid)
+	if len(keys) == 0 && kid != "" && v.jwks != nil {
+		// The issuer may have rotated its keys since the last refresh
+		if err := v.jwks.refreshForUnknownKey(); err != nil {
+			return nil, err
+		}
+		keys = v.publicKeys(kid)
+	}
+
+	var candidates jwt.VerificationKeySet
+	for _, key := range keys {
+		if matchesMethod(key, token.Method) {
+			candidates.Keys = append(candidates.Keys, key)
+		}
+	}
+
+	if len(candidates.Keys) == 0 {
+		return nil, fmt.Errorf("no key found for token")
+	}
+	return candidates, nil
+}
+
+func (v *Verifier) publicKeys(kid string) []jwt.VerificationKey {
+	var keys []jwt.VerificationKey
+
+	addMatching := func(set map[string]interface{}) {
+		for id, key := range set {
+			if kid == "" || id == kid {
+				keys = append(keys, key)
+			}
+		}
+	}
+
+	addMatching(v.localKeys)
+	if v.jwks != nil {
+		addMatching(v.jwks.keys())
+	}
+
+	return keys
+}
+
+func matchesMethod(key interface{}, method jwt.SigningMethod) bool {
+	switch key.(type) {
+	case *rsa.PublicKey:
+		return method.Alg() == jwt.SigningMethodRS256.Alg()
+	case *ecdsa.PublicKey:
+		return method.Alg() == jwt.SigningMethodES256.Alg()
+	default:
+		return false
+	}
+}
+
+// loadPublicKey reads an RSA or ECDSA public key from a PEM public key or certificate file
+func loadPublicKey(path string) (interface{}, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read JWT public key %s: %w", path, err)
+	}
+
+	block, _ := pem.Decode(data)
+	if block == nil {
+		return nil, fmt.Errorf("JWT public key %s is not PEM encoded", path)
+	}
+
+	var key interface{}
+	switch block.Type {
+	case "CERTIFICATE":
+		cert, err := x509.ParseCertificate(block.Bytes)
+		if err != nil {
+			return nil, fmt.Errorf("failed to parse JWT certificate %s: %w", path, err)
+		}
+		key = cert.PublicKey
+	default:
+		key, err = x509.ParsePKIXPublicKey(block.Bytes)
+		if err != nil {
+			return nil, fmt.Errorf("failed to parse JWT public key %s: %w", path, err)
+		}
+	}
+
+	switch key.(type) {
+	case *rsa.PublicKey, *ecdsa.PublicKey:
+		return key, nil
+	default:
+		return nil, fmt.Errorf("JWT public key %s must be an RSA or ECDSA key", path)
+	}
+}
@@ -0,0 +1,259 @@
+package auth
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/json"
+	"encoding/pem"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+)
+
+// writePublicKey writes public to dir as the PEM file <kid>.pem
+func writePublicKey(t *testing.T, dir, kid string, public interface{}) string {
+	der, err := x509.MarshalPKIXPublicKey(public)
+	if err != nil {
+		t.Fatal(err)
+	}
+	path := filepath.Join(dir, kid+".pem")
+	data := pem.EncodeToMemory(&pem.Block{Type: "PUBLIC KEY", Bytes: der})
+	if err := os.WriteFile(path, data, 0o600); err != nil {
+		t.Fatal(err)
+	}
+	return path
+}
+
+func TestVerify(t *testing.T) {
+	secret := []byte("test-secret")
+	rsaKey, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatal(err)
+	}
+	ecKey, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	otherECKey, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	jwksKey, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	jwks := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		json.NewEncoder(w).Encode(map[string]interface{}{"keys": []jwk{{
+			Kid: "remote",
+			Kty: "EC",
+			Use: "sig",
+			Crv: "P-256",
+			X:   base64.RawURLEncoding.EncodeToString(jwksKey.X.FillBytes(make([]byte, 32))),
+			Y:   base64.RawURLEncoding.EncodeToString(jwksKey.Y.FillBytes(make([]byte, 32))),
+		}}})
+	}))
+	defer jwks.Close()
+
+	dir := t.TempDir()
+	rsaPath := writePublicKey(t, dir, "rsa-1", &rsaKey.PublicKey)
+	ecPath := writePublicKey(t, dir, "ec-1", &ecKey.PublicKey)
+	rsaPEM, err := os.ReadFile(rsaPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	all := VerifierConfig{
+		HMACSecret:     secret,
+		PublicKeyFiles: []string{rsaPath, ecPath},
+		JWKSURL:        jwks.URL,
+		Issuer:         "https://issuer.example",
+		Audience:       "author-service",
+	}
+	publicOnly := VerifierConfig{PublicKeyFiles: []string{rsaPath, ecPath}}
+
+	now := time.Now()
+	claims := func() jwt.MapClaims {
+		return jwt.MapClaims{
+			"sub": "alice",
+			"iss": "https://issuer.example",
+			"aud": "author-service",
+			"exp": now.Add(time.Hour).Unix(),
+		}
+	}
+	sign := func(method jwt.SigningMethod, kid string, key interface{}, claims jwt.MapClaims) string {
+		token := jwt.NewWithClaims(method, claims)
+		if kid != "" {
+			token.Header["kid"] = kid
+		}
+		signed, err := token.SignedString(key)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return signed
+	}
+	without := func(claim string) jwt.MapClaims {
+		c := claims()
+		delete(c, claim)
+		return c
+	}
+	with := func(claim string, value interface{}) jwt.MapClaims {
+		c := claims()
+		c[claim] = value
+		return c
+	}
+
+	tests := []struct {
+		name    string
+		config  VerifierConfig
+		token   string
+		wantErr bool
+	}{
+		{
+			name:   "HS256",
+			config: all,
+			token:  sign(jwt.SigningMethodHS256, "", secret, claims()),
+		},
+		{
+			name:    "HS256 with the wrong secret",
+			config:  all,
+			token:   sign(jwt.SigningMethodHS256, "", []byte("other"), claims()),
+			wantErr: true,
+		},
+		{
+			name:    "HS256 without a configured secret",
+			config:  publicOnly,
+			token:   sign(jwt.SigningMethodHS256, "", []byte{}, claims()),
+			wantErr: true,
+		},
+		{
+			name:    "HS256 signed with a public key",
+			config:  publicOnly,
+			token:   sign(jwt.SigningMethodHS256, "rsa-1", rsaPEM, claims()),
+			wantErr: true,
+		},
+		{
+			name:    "alg none",
+			config:  all,
+			token:   sign(jwt.SigningMethodNone, "", jwt.UnsafeAllowNoneSignatureType, claims()),
+			wantErr: true,
+		},
+		{
+			name:    "unsupported alg",
+			config:  all,
+			token:   sign(jwt.SigningMethodHS512, "", secret, claims()),
+			wantErr: true,
+		},
+		{
+			name:   "RS256 with kid",
+			config: all,
+			token:  sign(jwt.SigningMethodRS256, "rsa-1", rsaKey, claims()),
+		},
+		{
+			name:   "ES256 with kid",
+			config: all,
+			token:  sign(jwt.SigningMethodES256, "ec-1", ecKey, claims()),
+		},
+		{
+			name:   "ES256 without kid",
+			config: publicOnly,
+			token:  sign(jwt.SigningMethodES256, "", ecKey, without("iss")),
+		},
+		{
+			name:   "ES256 from the JWKS",
+			config: all,
+			token:  sign(jwt.SigningMethodES256, "remote", jwksKey, claims()),
+		},
+		{
+			name:    "kid naming a key of another type",
+			config:  all,
+			token:   sign(jwt.SigningMethodES256, "rsa-1", ecKey, claims()),
+			wantErr: true,
+		},
+		{
+			name:    "kid naming another key",
+			config:  all,
+			token:   sign(jwt.SigningMethodES256, "remote", ecKey, claims()),
+			wantErr: true,
+		},
+		{
+			name:    "unknown kid",
+			config:  all,
+			token:   sign(jwt.SigningMethodES256, "ec-2", ecKey, claims()),
+			wantErr: true,
+		},
+		{
+			name:    "unknown key without kid",
+			config:  publicOnly,
+			token:   sign(jwt.SigningMethodES256, "", otherECKey, without("iss")),
+			wantErr: true,
+		},
+		{
+			name:    "expired",
+			config:  all,
+			token:   sign(jwt.SigningMethodHS256, "", secret, with("exp", now.Add(-time.Hour).Unix())),
+			wantErr: true,
+		},
+		{
+			name:   "expired within the leeway",
+			config: all,
+			token:  sign(jwt.SigningMethodHS256, "", secret, with("exp", now.Add(-10*time.Second).Unix())),
+		},
+		{
+			name:    "no expiry",
+			config:  all,
+			token:   sign(jwt.SigningMethodHS256, "", secret, without("exp")),
+			wantErr: true,
+		},
+		{
+			name:    "no subject",
+			config:  all,
+			token:   sign(jwt.SigningMethodHS256, "", secret, without("sub")),
+			wantErr: true,
+		},
+		{
+			name:    "wrong issuer",
+			config:  all,
+			token:   sign(jwt.SigningMethodHS256, "", secret, with("iss", "https://other.example")),
+			wantErr: true,
+		},
+		{
+			name:    "wrong audience",
+			config:  all,
+			token:   sign(jwt.SigningMethodHS256, "", secret, with("aud", "other-service")),
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			verifier, err := NewVerifier(tt.config)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			principal, err := verifier.Verify(tt.token)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("Verify() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if err == nil && principal.Subject != "alice" {
+				t.Errorf("Verify() subject = %q, want alice", principal.Subject)
+			}
+		})
+	}
+}
+
+func TestNewVerifierRequiresAKey(t *testing.T) {
+	if _, err := NewVerifier(VerifierConfig{}); err == nil {
+		t.Error("NewVerifier() error = nil, want an error without keys")
+	}
+}
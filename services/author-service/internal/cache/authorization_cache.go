@@ -0,0 +1,366 @@
+package cache
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log"
+	"sort"
+	"strconv"
+	"strings"
+	"sync/atomic"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+	"golang.org/x/sync/singleflight"
+)
+
+// DefaultTTL bounds how long a cached entry can outlive a change it missed
+const DefaultTTL = time.Minute
+
+// redisTimeout bounds each round trip to Redis; a slow cache falls back to the database
+const redisTimeout = 250 * time.Millisecond
+
+// generationKey holds the generation every other key is namespaced under; bumping it drops
+// the whole cache at once
+const generationKey = "authz:generation"
+
+// storeScript writes a user's hash only if no invalidation of the user happened since the rows
+// were loaded, i.e. if the user's version is still the one read before the load. Otherwise the
+// rows may predate the change and writing them would undo the invalidation.
+var storeScript = redis.NewScript(`
+if (redis.call('GET', KEYS[1]) or '') ~= ARGV[1] then
+	return 0
+end
+redis.call('HSET', KEYS[2], unpack(ARGV, 3))
+redis.call('PEXPIRE', KEYS[2], ARGV[2])
+return 1
+`)
+
+// Kinds of cached entries
+const (
+	kindBans   = "bans"
+	kindGrants = "grants"
+)
+
+// Stats are the counters of a cache since it was created
+type Stats struct {
+	// Hits and Misses count user and permission pairs
+	Hits   int64 `json:"hits"`
+	Misses int64 `json:"misses"`
+	// Loads counts database queries made to fill misses
+	Loads int64 `json:"loads"`
+	// Coalesced counts lookups that shared the result of a load already in flight
+	Coalesced int64 `json:"coalesced"`
+	// Invalidations counts the keys removed by changes
+	Invalidations int64 `json:"invalidations"`
+	// Errors counts failed Redis calls; each one falls back to the database
+	Errors int64 `json:"errors"`
+}
+
+// AuthorizationCache keeps the bans and permission grants that apply to a user and permission
+// pair in Redis. Each user has one hash per kind, keyed by permission ID, so a change to a user
+// removes exactly the hashes of that user. Changes to the permission catalogue bump the
+// generation instead, which drops every entry at once. Each hash has a version that every
+// invalidation bumps, so that a load that raced with a change is not cached.
+//
+// A nil AuthorizationCache caches nothing and its invalidations do nothing.
+type AuthorizationCache struct {
+	client *redis.Client
+	ttl    time.Duration
+	group  singleflight.Group
+
+	hits          atomic.Int64
+	misses        atomic.Int64
+	loads         atomic.Int64
+	coalesced     atomic.Int64
+	invalidations atomic.Int64
+	errors        atomic.Int64
+}
+
+// NewAuthorizationCache creates a cache whose entries expire after ttl
+func NewAuthorizationCache(client *redis.Client, ttl time.Duration) *AuthorizationCache {
+	if ttl <= 0 {
+		ttl = DefaultTTL
+	}
+	return &AuthorizationCache{client: client, ttl: ttl}
+}
+
+// Stats returns the counters of the cache
+func (c *AuthorizationCache) Stats() Stats {
+	if c == nil {
+		return Stats{}
+	}
+	return Stats{
+		Hits:          c.hits.Load(),
+		Misses:        c.misses.Load(),
+		Loads:         c.loads.Load(),
+		Coalesced:     c.coalesced.Load(),
+		Invalidations: c.invalidations.Load(),
+		Errors:        c.errors.Load(),
+	}
+}
+
+// InvalidateBans removes the cached bans of the given users
+func (c *AuthorizationCache) InvalidateBans(ctx context.Context, userIDs ...string) {
+	c.invalidate(ctx, kindBans, userIDs)
+}
+
+// InvalidateGrants removes the cached permission grants of the given users
+func (c *AuthorizationCache) InvalidateGrants(ctx context.Context, userIDs ...string) {
+	c.invalidate(ctx, kindGrants, userIDs)
+}
+
+// InvalidateAll drops every cached entry by moving to a new generation
+func (c *AuthorizationCache) InvalidateAll(ctx context.Context) {
+	if c == nil {
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, redisTimeout)
+	defer cancel()
+
+	if err := c.client.Incr(ctx, generationKey).Err(); err != nil {
+		c.fail("bump generation", err)
+		return
+	}
+	c.invalidations.Add(1)
+}
+
+func (c *AuthorizationCache) invalidate(ctx context.Context, kind string, userIDs []string) {
+	if c == nil || len(userIDs) == 0 {
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, redisTimeout)
+	defer cancel()
+
+	generation, err := c.generation(ctx)
+	if err != nil {
+		c.fail("invalidate "+kind, err)
+		return
+	}
+
+	pipe := c.client.TxPipeline()
+	for _, userID := range userIDs {
+		userKey := key(generation, kind, userID)
+		pipe.Incr(ctx, versionKey(userKey))
+		pipe.Expire(ctx, versionKey(userKey), c.ttl)
+		pipe.Del(ctx, userKey)
+	}
+
+	if _, err := pipe.Exec(ctx); err != nil {
+		c.fail("invalidate "+kind, err)
+		return
+	}
+	c.invalidations.Add(int64(len(userIDs)))
+}
+
+func (c *AuthorizationCache) generation(ctx context.Context) (string, error) {
+	generation, err := c.client.Get(ctx, generationKey).Result()
+	if err == redis.Nil {
+		return "0", nil
+	}
+	return generation, err
+}
+
+func (c *AuthorizationCache) fail(operation string, err error) {
+	c.errors.Add(1)
+	log.Printf("Authorization cache: failed to %s: %v", operation, err)
+}
+
+func key(generation, kind, userID string) string {
+	return "authz:" + generation + ":" + kind + ":" + userID
+}
+
+// versionKey holds the version of the hash at userKey. It outlives the hash by up to the TTL so
+// that a load in flight when the hash is removed still sees the bump.
+func versionKey(userKey string) string {
+	return userKey + ":version"
+}
+
+// table describes how rows of one kind are cached
+type table[T any] struct {
+	kind string
+	// pair returns the user and permission a row applies to
+	pair func(row T) (string, uint)
+	// fresh, if set, reports whether a cached row still applies
+	fresh func(row T, now time.Time) bool
+}
+
+// lookup returns the rows of every pair of userIDs and permIDs, reading the cached pairs from
+// Redis and loading the others with a single call to load. Rows are returned ordered by
+// permission ID, as the database returns them. Redis errors fall back to load.
+func lookup[T any](c *AuthorizationCache, t table[T], userIDs []string, permIDs []uint, load func(userIDs []string, permIDs []uint) ([]T, error)) ([]T, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), redisTimeout)
+	defer cancel()
+
+	generation, err := c.generation(ctx)
+	if err != nil {
+		c.fail("read generation", err)
+		return load(userIDs, permIDs)
+	}
+
+	fields := make([]string, len(permIDs))
+	for i, permID := range permIDs {
+		fields[i] = strconv.FormatUint(uint64(permID), 10)
+	}
+
+	pipe := c.client.Pipeline()
+	cmds := make([]*redis.SliceCmd, len(userIDs))
+	versionCmds := make([]*redis.StringCmd, len(userIDs))
+	for i, userID := range userIDs {
+		userKey := key(generation, t.kind, userID)
+		cmds[i] = pipe.HMGet(ctx, userKey, fields...)
+		versionCmds[i] = pipe.Get(ctx, versionKey(userKey))
+	}
+	// A user that was never invalidated has no version, which reads as redis.Nil
+	pipe.Exec(ctx)
+	for i := range userIDs {
+		err := cmds[i].Err()
+		if err == nil {
+			err = versionCmds[i].Err()
+		}
+		if err != nil && err != redis.Nil {
+			c.fail("read "+t.kind, err)
+			return load(userIDs, permIDs)
+		}
+	}
+
+	now := time.Now()
+	var rows []T
+	var missingUsers []string
+	versions := map[string]string{}
+	missingPerms := map[uint]bool{}
+	missingPairs := map[string]bool{}
+	for i, cmd := range cmds {
+		userMissing := false
+		for j, value := range cmd.Val() {
+			var cached []T
+			encoded, ok := value.(string)
+			if ok {
+				if err := json.Unmarshal([]byte(encoded), &cached); err != nil {
+					c.fail("decode "+t.kind, err)
+					ok = false
+				}
+			}
+
+			if !ok {
+				c.misses.Add(1)
+				userMissing = true
+				missingPerms[permIDs[j]] = true
+				missingPairs[pairKey(userIDs[i], permIDs[j])] = true
+				continue
+			}
+
+			c.hits.Add(1)
+			for _, row := range cached {
+				if t.fresh == nil || t.fresh(row, now) {
+					rows = append(rows, row)
+				}
+			}
+		}
+		if userMissing {
+			missingUsers = append(missingUsers, userIDs[i])
+			versions[userIDs[i]] = versionCmds[i].Val()
+		}
+	}
+
+	if len(missingUsers) > 0 {
+		loaded, err := fill(c, t, generation, versions, missingUsers, sortedIDs(missingPerms), load)
+		if err != nil {
+			return nil, err
+		}
+
+		// Every missing user is loaded for every missing permission; keep only the pairs that
+		// were not read from the cache
+		for _, row := range loaded {
+			if missingPairs[pairKey(t.pair(row))] {
+				rows = append(rows, row)
+			}
+		}
+	}
+
+	sort.SliceStable(rows, func(i, j int) bool {
+		_, a := t.pair(rows[i])
+		_, b := t.pair(rows[j])
+		return a < b
+	})
+	return rows, nil
+}
+
+// fill loads the rows of every pair of userIDs and permIDs and caches them, pairs without rows
+// included, so that looking them up again is a hit. versions are the versions of the users'
+// hashes read before the load; a user invalidated since is not cached. Concurrent fills of the
+// same pairs at the same versions share one load, so a burst of misses for a user costs a
+// single query.
+func fill[T any](c *AuthorizationCache, t table[T], generation string, versions map[string]string, userIDs []string, permIDs []uint, load func(userIDs []string, permIDs []uint) ([]T, error)) ([]T, error) {
+	userVersions := make([]string, len(userIDs))
+	for i, userID := range userIDs {
+		userVersions[i] = userID + "@" + versions[userID]
+	}
+
+	flight := fmt.Sprintf("%s:%s:%s:%v", generation, t.kind, strings.Join(userVersions, ","), permIDs)
+	loaded, err, shared := c.group.Do(flight, func() (interface{}, error) {
+		c.loads.Add(1)
+		rows, err := load(userIDs, permIDs)
+		if err != nil {
+			return nil, err
+		}
+		store(c, t, generation, versions, userIDs, permIDs, rows)
+		return rows, nil
+	})
+	if shared {
+		c.coalesced.Add(1)
+	}
+	if err != nil {
+		return nil, err
+	}
+	return loaded.([]T), nil
+}
+
+// store caches rows under one field per permission in the hash of each user whose version is
+// still the one given
+func store[T any](c *AuthorizationCache, t table[T], generation string, versions map[string]string, userIDs []string, permIDs []uint, rows []T) {
+	ctx, cancel := context.WithTimeout(context.Background(), redisTimeout)
+	defer cancel()
+
+	byPair := map[string][]T{}
+	for _, row := range rows {
+		pair := pairKey(t.pair(row))
+		byPair[pair] = append(byPair[pair], row)
+	}
+
+	pipe := c.client.Pipeline()
+	for _, userID := range userIDs {
+		args := make([]interface{}, 0, 2+2*len(permIDs))
+		args = append(args, versions[userID], c.ttl.Milliseconds())
+		for _, permID := range permIDs {
+			encoded, err := json.Marshal(byPair[pairKey(userID, permID)])
+			if err != nil {
+				c.fail("encode "+t.kind, err)
+				return
+			}
+			args = append(args, strconv.FormatUint(uint64(permID), 10), encoded)
+		}
+
+		userKey := key(generation, t.kind, userID)
+		storeScript.Eval(ctx, pipe, []string{versionKey(userKey), userKey}, args...)
+	}
+	if _, err := pipe.Exec(ctx); err != nil {
+		c.fail("store "+t.kind, err)
+	}
+}
+
+func sortedIDs(set map[uint]bool) []uint {
+	ids := make([]uint, 0, len(set))
+	for id := range set {
+		ids = append(ids, id)
+	}
+	sort.Slice(ids, func(i, j int) bool { return ids[i] < ids[j] })
+	return ids
+}
+
+func pairKey(userID string, permID uint) string {
+	return fmt.Sprintf("%s:%d", userID, permID)
+}
@@ -0,0 +1,64 @@
+package cache
+
+import (
+	"gin/internal/repositories"
+	"time"
+)
+
+var bansTable = table[repositories.MatchedBan]{
+	kind: kindBans,
+	pair: func(match repositories.MatchedBan) (string, uint) { return match.Ban.UserID, match.PermID },
+	// Bans that run out stay cached until the sweeper finalises them; drop them on read
+	fresh: func(match repositories.MatchedBan, now time.Time) bool { return match.Ban.IsActive(now) },
+}
+
+var grantsTable = table[repositories.PermissionGrant]{
+	kind: kindGrants,
+	pair: func(grant repositories.PermissionGrant) (string, uint) { return grant.UserID, grant.PermID },
+}
+
+// UserBanRepository serves ban checks from the cache
+type UserBanRepository struct {
+	repositories.UserBanRepositoryInterface
+	cache *AuthorizationCache
+}
+
+// NewUserBanRepository wraps repo so that GetMatchingBans is cached; a nil cache returns repo
+func NewUserBanRepository(repo repositories.UserBanRepositoryInterface, cache *AuthorizationCache) repositories.UserBanRepositoryInterface {
+	if cache == nil {
+		return repo
+	}
+	return &UserBanRepository{UserBanRepositoryInterface: repo, cache: cache}
+}
+
+// GetMatchingBans returns the active bans covering the given permissions, as the wrapped
+// repository does. Listings of every permission, with a nil permIDs, are not cached.
+func (r *UserBanRepository) GetMatchingBans(userIDs []string, permIDs []uint) ([]repositories.MatchedBan, error) {
+	if len(userIDs) == 0 || len(permIDs) == 0 {
+		return r.UserBanRepositoryInterface.GetMatchingBans(userIDs, permIDs)
+	}
+	return lookup(r.cache, bansTable, userIDs, permIDs, r.UserBanRepositoryInterface.GetMatchingBans)
+}
+
+// UserRoleRepository serves permission grants resolved through roles from the cache
+type UserRoleRepository struct {
+	repositories.UserRoleRepositoryInterface
+	cache *AuthorizationCache
+}
+
+// NewUserRoleRepository wraps repo so that GetPermissionGrants is cached; a nil cache returns repo
+func NewUserRoleRepository(repo repositories.UserRoleRepositoryInterface, cache *AuthorizationCache) repositories.UserRoleRepositoryInterface {
+	if cache == nil {
+		return repo
+	}
+	return &UserRoleRepository{UserRoleRepositoryInterface: repo, cache: cache}
+}
+
+// GetPermissionGrants returns the grants of the given permissions, as the wrapped repository
+// does. Listings of every permission, with a nil permIDs, are not cached.
+func (r *UserRoleRepository) GetPermissionGrants(userIDs []string, permIDs []uint) ([]repositories.PermissionGrant, error) {
+	if len(userIDs) == 0 || len(permIDs) == 0 {
+		return r.UserRoleRepositoryInterface.GetPermissionGrants(userIDs, permIDs)
+	}
+	return lookup(r.cache, grantsTable, userIDs, permIDs, r.UserRoleRepositoryInterface.GetPermissionGrants)
+}
@@ -0,0 +1,197 @@
+package conditions
+
+import (
+	"container/list"
+	"encoding/json"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/google/cel-go/cel"
+)
+
+// MaxConditionCost bounds the work a single condition may do when evaluated
+const MaxConditionCost = 10000
+
+// programCacheSize bounds the number of compiled conditions kept; the least recently used
+// program is dropped first
+const programCacheSize = 256
+
+// Input holds the attributes a condition is evaluated against. Conditions see them as
+// `user` (stored for the user), `request` (supplied with the check) and `attrs` (both merged,
+// request attributes taking precedence), plus the evaluation time as `now`.
+type Input struct {
+	User    map[string]interface{}
+	Request map[string]interface{}
+	Now     time.Time
+}
+
+// Evaluator compiles and evaluates CEL conditions attached to role grants.
+// Compiled programs are cached by expression, up to programCacheSize of them.
+type Evaluator struct {
+	env *cel.Env
+
+	mu       sync.Mutex
+	programs map[string]*list.Element
+	recent   *list.List
+}
+
+type cachedProgram struct {
+	expr    string
+	program cel.Program
+}
+
+// NewEvaluator creates an evaluator with the condition environment
+func NewEvaluator() (*Evaluator, error) {
+	env, err := cel.NewEnv(
+		cel.Variable("user", cel.MapType(cel.StringType, cel.DynType)),
+		cel.Variable("request", cel.MapType(cel.StringType, cel.DynType)),
+		cel.Variable("attrs", cel.MapType(cel.StringType, cel.DynType)),
+		cel.Variable("now", cel.TimestampType),
+	)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create condition environment: %w", err)
+	}
+
+	return &Evaluator{
+		env:      env,
+		programs: map[string]*list.Element{},
+		recent:   list.New(),
+	}, nil
+}
+
+// Validate reports whether expr compiles to a boolean condition
+func (e *Evaluator) Validate(expr string) error {
+	_, err := e.compile(expr)
+	return err
+}
+
+// Evaluate runs the condition against the input. Any evaluation error, such as a missing
+// attribute, is returned so callers can treat the condition as unmet.
+func (e *Evaluator) Evaluate(expr string, input Input) (bool, error) {
+	program, err := e.compile(expr)
+	if err != nil {
+		return false, err
+	}
+
+	user := normalizeMap(input.User)
+	request := normalizeMap(input.Request)
+	attrs := make(map[string]interface{}, len(user)+len(request))
+	for key, value := range user {
+		attrs[key] = value
+	}
+	for key, value := range request {
+		attrs[key] = value
+	}
+
+	now := input.Now
+	if now.IsZero() {
+		now = time.Now()
+	}
+
+	out, _, err := program.Eval(map[string]interface{}{
+		"user":    user,
+		"request": request,
+		"attrs":   attrs,
+		"now":     now,
+	})
+	if err != nil {
+		return false, fmt.Errorf("failed to evaluate condition: %w", err)
+	}
+
+	result, ok := out.Value().(bool)
+	if !ok {
+		return false, fmt.Errorf("condition did not evaluate to a bool")
+	}
+	return result, nil
+}
+
+func (e *Evaluator) compile(expr string) (cel.Program, error) {
+	if program, ok := e.cached(expr); ok {
+		return program, nil
+	}
+
+	ast, issues := e.env.Compile(expr)
+	if issues != nil && issues.Err() != nil {
+		return nil, fmt.Errorf("invalid condition: %w", issues.Err())
+	}
+
+	if outputType := ast.OutputType(); outputType != cel.BoolType && outputType != cel.DynType {
+		return nil, fmt.Errorf("condition must evaluate to a bool, not %s", outputType)
+	}
+
+	program, err := e.env.Program(ast, cel.CostLimit(MaxConditionCost))
+	if err != nil {
+		return nil, fmt.Errorf("invalid condition: %w", err)
+	}
+
+	e.store(expr, program)
+	return program, nil
+}
+
+func (e *Evaluator) cached(expr string) (cel.Program, bool) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	element, ok := e.programs[expr]
+	if !ok {
+		return nil, false
+	}
+	e.recent.MoveToFront(element)
+	return element.Value.(*cachedProgram).program, true
+}
+
+func (e *Evaluator) store(expr string, program cel.Program) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	if element, ok := e.programs[expr]; ok {
+		e.recent.MoveToFront(element)
+		return
+	}
+
+	e.programs[expr] = e.recent.PushFront(&cachedProgram{expr: expr, program: program})
+	if e.recent.Len() > programCacheSize {
+		oldest := e.recent.Back()
+		e.recent.Remove(oldest)
+		delete(e.programs, oldest.Value.(*cachedProgram).expr)
+	}
+}
+
+// normalizeMap turns whole JSON numbers into integers so that conditions such as
+// `attrs.account_age_days >= 3` compare as expected
+func normalizeMap(values map[string]interface{}) map[string]interface{} {
+	normalized := make(map[string]interface{}, len(values))
+	for key, value := range values {
+		normalized[key] = normalizeValue(value)
+	}
+	return normalized
+}
+
+func normalizeValue(value interface{}) interface{} {
+	switch v := value.(type) {
+	case float64:
+		if v == float64(int64(v)) {
+			return int64(v)
+		}
+		return v
+	case json.Number:
+		if i, err := v.Int64(); err == nil {
+			return i
+		}
+		if f, err := v.Float64(); err == nil {
+			return f
+		}
+		return v.String()
+	case map[string]interface{}:
+		return normalizeMap(v)
+	case []interface{}:
+		items := make([]interface{}, len(v))
+		for i, item := range v {
+			items[i] = normalizeValue(item)
+		}
+		return items
+	default:
+		return v
+	}
+}
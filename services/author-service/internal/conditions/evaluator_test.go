@@ -0,0 +1,176 @@
+package conditions
+
+import (
+	"encoding/json"
+	"fmt"
+	"testing"
+	"time"
+)
+
+func TestEvaluate(t *testing.T) {
+	now := time.Date(2026, 3, 14, 12, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		name    string
+		expr    string
+		input   Input
+		want    bool
+		wantErr bool
+	}{
+		{
+			name:  "stored attribute met",
+			expr:  "user.account_age_days >= 3",
+			input: Input{User: map[string]interface{}{"account_age_days": float64(5)}},
+			want:  true,
+		},
+		{
+			name:  "stored attribute not met",
+			expr:  "user.account_age_days >= 3",
+			input: Input{User: map[string]interface{}{"account_age_days": float64(1)}},
+			want:  false,
+		},
+		{
+			name:  "whole json number compares as an integer",
+			expr:  "attrs.account_age_days == 3",
+			input: Input{User: map[string]interface{}{"account_age_days": json.Number("3")}},
+			want:  true,
+		},
+		{
+			name:  "fractional number stays a double",
+			expr:  "attrs.score > 2.5",
+			input: Input{Request: map[string]interface{}{"score": 2.75}},
+			want:  true,
+		},
+		{
+			name: "request attribute overrides stored",
+			expr: `attrs.region == "eu"`,
+			input: Input{
+				User:    map[string]interface{}{"region": "us"},
+				Request: map[string]interface{}{"region": "eu"},
+			},
+			want: true,
+		},
+		{
+			name: "stored attribute still visible as user",
+			expr: `user.region == "us"`,
+			input: Input{
+				User:    map[string]interface{}{"region": "us"},
+				Request: map[string]interface{}{"region": "eu"},
+			},
+			want: true,
+		},
+		{
+			name:  "nested map and list",
+			expr:  `"vip" in attrs.profile.tags`,
+			input: Input{User: map[string]interface{}{"profile": map[string]interface{}{"tags": []interface{}{"vip", float64(1)}}}},
+			want:  true,
+		},
+		{
+			name:  "has guards a missing attribute",
+			expr:  "has(attrs.region) && attrs.region == 'eu'",
+			input: Input{},
+			want:  false,
+		},
+		{
+			name:  "evaluation time",
+			expr:  "now < timestamp('2026-06-01T00:00:00Z')",
+			input: Input{Now: now},
+			want:  true,
+		},
+		{
+			name:    "missing attribute",
+			expr:    "attrs.region == 'eu'",
+			input:   Input{},
+			wantErr: true,
+		},
+		{
+			name:    "not a bool",
+			expr:    "attrs.region",
+			input:   Input{Request: map[string]interface{}{"region": "eu"}},
+			wantErr: true,
+		},
+		{
+			name:    "does not compile",
+			expr:    "attrs.region ==",
+			wantErr: true,
+		},
+		{
+			name:    "unknown variable",
+			expr:    "account.age > 3",
+			wantErr: true,
+		},
+	}
+
+	evaluator, err := NewEvaluator()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := evaluator.Evaluate(tt.expr, tt.input)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("Evaluate() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if got != tt.want {
+				t.Errorf("Evaluate() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestValidate(t *testing.T) {
+	tests := []struct {
+		name    string
+		expr    string
+		wantErr bool
+	}{
+		{name: "bool", expr: "attrs.account_age_days >= 3"},
+		{name: "dynamic", expr: "attrs.verified"},
+		{name: "string", expr: "'eu'", wantErr: true},
+		{name: "int", expr: "1 + 2", wantErr: true},
+		{name: "syntax error", expr: "attrs.(", wantErr: true},
+		{name: "unknown variable", expr: "region == 'eu'", wantErr: true},
+	}
+
+	evaluator, err := NewEvaluator()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := evaluator.Validate(tt.expr)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("Validate() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestProgramCacheIsBounded(t *testing.T) {
+	evaluator, err := NewEvaluator()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	kept := "attrs.account_age_days >= 3"
+	for i := 0; i < 2*programCacheSize; i++ {
+		if err := evaluator.Validate(fmt.Sprintf("attrs.level >= %d", i)); err != nil {
+			t.Fatal(err)
+		}
+		if _, err := evaluator.Evaluate(kept, Input{User: map[string]interface{}{"account_age_days": 5}}); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	if got := len(evaluator.programs); got != programCacheSize || evaluator.recent.Len() != programCacheSize {
+		t.Errorf("cached %d programs, want %d", got, programCacheSize)
+	}
+	if _, ok := evaluator.cached(kept); !ok {
+		t.Error("recently used program was evicted")
+	}
+	if _, ok := evaluator.cached("attrs.level >= 0"); ok {
+		t.Error("least recently used program was kept")
+	}
+}
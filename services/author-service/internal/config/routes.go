@@ -2,66 +2,188 @@ package config
 
 import (
 	"gin/internal/handlers"
+	"gin/internal/middleware"
+	"gin/internal/models"
 
 	"github.com/gin-gonic/gin"
 )
 
-func SetupRoleRoutes(rg *gin.RouterGroup, h *handlers.RoleHandler) {
+func SetupRoleRoutes(rg *gin.RouterGroup, h *handlers.RoleHandler, authz *middleware.Authorizer) {
 	roles := rg.Group("/roles")
 	{
-		roles.POST("", h.CreateRole)
-		roles.GET("", h.GetRoles)
-		roles.GET("/:id", h.GetRole)
-		roles.PUT("/:id", h.UpdateRole)
-		roles.DELETE("/:id", h.DeleteRole)
-		roles.GET("/:id/permissions", h.GetRoleWithPermissions)
-		roles.POST("/:id/permissions", h.AddPermissionToRole)
-		roles.DELETE("/:id/permissions/:permission_id", h.RemovePermissionFromRole)
+		roles.POST("", authz.Require(models.PermissionRoleCreate), h.CreateRole)
+		roles.GET("", authz.Require(models.PermissionRoleView), h.GetRoles)
+		roles.GET("/:id", authz.Require(models.PermissionRoleView), h.GetRole)
+		roles.PUT("/:id", authz.Require(models.PermissionRoleUpdate), h.UpdateRole)
+		roles.DELETE("/:id", authz.Require(models.PermissionRoleDelete), h.DeleteRole)
+		roles.GET("/:id/permissions", authz.Require(models.PermissionRoleView), h.GetRoleWithPermissions)
+		roles.POST("/:id/permissions", authz.Require(models.PermissionPermissionAssign), h.AddPermissionToRole)
+		roles.DELETE("/:id/permissions/:permission_id", authz.Require(models.PermissionPermissionRemove), h.RemovePermissionFromRole)
+		roles.GET("/:id/parents", authz.Require(models.PermissionRoleView), h.GetParentRoles)
+		roles.POST("/:id/parents", authz.Require(models.PermissionRoleUpdate), h.AddParentRole)
+		roles.DELETE("/:id/parents/:parent_id", authz.Require(models.PermissionRoleUpdate), h.RemoveParentRole)
 	}
 }
 
-func SetupPermissionRoutes(rg *gin.RouterGroup, h *handlers.PermissionHandler) {
+func SetupPermissionRoutes(rg *gin.RouterGroup, h *handlers.PermissionHandler, authz *middleware.Authorizer) {
 	permissions := rg.Group("/permissions")
 	{
-		permissions.POST("", h.CreatePermission)
-		permissions.GET("", h.GetPermissions)
-		permissions.GET("/:id", h.GetPermission)
-		permissions.PUT("/:id", h.UpdatePermission)
-		permissions.DELETE("/:id", h.DeletePermission)
-		permissions.GET("/:id/roles", h.GetPermissionWithRoles)
+		permissions.POST("", authz.Require(models.PermissionPermissionCreate), h.CreatePermission)
+		permissions.GET("", authz.Require(models.PermissionPermissionView), h.GetPermissions)
+		permissions.GET("/:id", authz.Require(models.PermissionPermissionView), h.GetPermission)
+		permissions.PUT("/:id", authz.Require(models.PermissionPermissionUpdate), h.UpdatePermission)
+		permissions.DELETE("/:id", authz.Require(models.PermissionPermissionDelete), h.DeletePermission)
+		permissions.GET("/:id/roles", authz.Require(models.PermissionPermissionView), h.GetPermissionWithRoles)
 	}
 }
 
-func SetupUserBanRoutes(rg *gin.RouterGroup, h *handlers.UserBanHandler) {
+func SetupUserBanRoutes(rg *gin.RouterGroup, h *handlers.UserBanHandler, authz *middleware.Authorizer) {
 	users := rg.Group("/users")
 	{
-		users.POST("/:user_id/bans", h.BanUser)
-		users.GET("/:user_id/bans", h.GetUserBans)
-		users.DELETE("/:user_id/bans/:permission_id", h.UnbanUser)
-		users.GET("/:user_id/bans/check", h.CheckUserBan)
+		users.POST("/:user_id/bans", authz.Require(models.PermissionModerationBan), h.BanUser)
+		// Users can see their own bans, e.g. to appeal them
+		users.GET("/:user_id/bans", authz.RequireSelfOr("user_id", models.PermissionModerationViewBans), h.GetUserBans)
+		users.DELETE("/:user_id/bans/:permission_id", authz.Require(models.PermissionModerationUnban), h.UnbanUser)
+		users.GET("/:user_id/bans/check", authz.RequireSelfOr("user_id", models.PermissionModerationViewBans), h.CheckUserBan)
 	}
 
 	bans := rg.Group("/bans")
 	{
-		bans.GET("", h.GetAllUserBans)
-		bans.GET("/:id", h.GetUserBan)
-		bans.PUT("/:id", h.UpdateBanReason)
+		bans.GET("", authz.Require(models.PermissionModerationViewBans), h.GetAllUserBans)
+		bans.GET("/:id", authz.Require(models.PermissionModerationViewBans), h.GetUserBan)
+		bans.PUT("/:id", authz.Require(models.PermissionModerationBan), h.UpdateBanReason)
 	}
 }
 
+func SetupUserRoleRoutes(rg *gin.RouterGroup, h *handlers.UserRoleHandler, authz *middleware.Authorizer) {
+	users := rg.Group("/users")
+	{
+		users.POST("/:user_id/roles", authz.Require(models.PermissionRoleAssign), h.AssignRole)
+		users.GET("/:user_id/roles", authz.RequireSelfOr("user_id", models.PermissionRoleView), h.GetUserRoles)
+		users.DELETE("/:user_id/roles/:role_id", authz.Require(models.PermissionRoleRemove), h.RemoveRole)
+	}
+
+	roles := rg.Group("/roles")
+	{
+		roles.GET("/:id/users", authz.Require(models.PermissionRoleView), h.GetRoleUsers)
+	}
+}
+
+func SetupAuthorizationRoutes(rg *gin.RouterGroup, h *handlers.AuthorizationHandler, authz *middleware.Authorizer) {
+	authorize := rg.Group("/authorize")
+	{
+		authorize.POST("", authz.Require(models.PermissionAuthorizationCheck), h.Authorize)
+		authorize.POST("/batch", authz.Require(models.PermissionAuthorizationCheck), h.AuthorizeBatch)
+	}
+
+	users := rg.Group("/users")
+	{
+		users.GET("/:user_id/permissions", authz.RequireSelfOr("user_id", models.PermissionAuthorizationCheck), h.GetEffectivePermissions)
+	}
+}
+
+func SetupUserAttributeRoutes(rg *gin.RouterGroup, h *handlers.UserAttributeHandler, authz *middleware.Authorizer) {
+	users := rg.Group("/users")
+	{
+		users.GET("/:user_id/attributes", authz.Require(models.PermissionAttributeView), h.GetAttributes)
+		users.PUT("/:user_id/attributes", authz.Require(models.PermissionAttributeWrite), h.SetAttributes)
+		users.DELETE("/:user_id/attributes/:key", authz.Require(models.PermissionAttributeWrite), h.DeleteAttribute)
+	}
+}
+
+func SetupBanReasonRoutes(rg *gin.RouterGroup, h *handlers.BanReasonHandler, authz *middleware.Authorizer) {
+	reasons := rg.Group("/ban-reasons")
+	{
+		reasons.POST("", authz.Require(models.PermissionModerationReasons), h.CreateReason)
+		reasons.GET("", authz.Require(models.PermissionModerationViewBans), h.GetReasons)
+		reasons.GET("/:code", authz.Require(models.PermissionModerationViewBans), h.GetReason)
+		reasons.PUT("/:code", authz.Require(models.PermissionModerationReasons), h.UpdateReason)
+		reasons.DELETE("/:code", authz.Require(models.PermissionModerationReasons), h.DeleteReason)
+	}
+}
+
+func SetupBanAppealRoutes(rg *gin.RouterGroup, h *handlers.BanAppealHandler, authz *middleware.Authorizer) {
+	users := rg.Group("/users")
+	{
+		// Appeals are submitted by the banned user; a global ban must exempt appeal:submit
+		users.POST("/:user_id/appeals", authz.RequireSelf("user_id", models.PermissionAppealSubmit), h.SubmitAppeal)
+		users.GET("/:user_id/appeals", authz.RequireSelfOr("user_id", models.PermissionModerationAppeals), h.GetUserAppeals)
+	}
+
+	appeals := rg.Group("/appeals")
+	{
+		appeals.GET("", authz.Require(models.PermissionModerationAppeals), h.GetAppeals)
+		appeals.GET("/:id", authz.Require(models.PermissionModerationAppeals), h.GetAppeal)
+		appeals.POST("/:id/accept", authz.Require(models.PermissionModerationAppeals), h.AcceptAppeal)
+		appeals.POST("/:id/reject", authz.Require(models.PermissionModerationAppeals), h.RejectAppeal)
+	}
+}
+
+func SetupAuditLogRoutes(rg *gin.RouterGroup, h *handlers.AuditLogHandler, authz *middleware.Authorizer) {
+	audit := rg.Group("/audit")
+	{
+		audit.GET("", authz.Require(models.PermissionAuditView), h.GetEntries)
+	}
+}
+
+func SetupAPIKeyRoutes(rg *gin.RouterGroup, h *handlers.APIKeyHandler, authz *middleware.Authorizer) {
+	keys := rg.Group("/api-keys")
+	{
+		keys.POST("", authz.Require(models.PermissionAPIKeyManage), h.CreateKey)
+		keys.GET("", authz.Require(models.PermissionAPIKeyManage), h.GetKeys)
+		keys.GET("/:id", authz.Require(models.PermissionAPIKeyManage), h.GetKey)
+		keys.DELETE("/:id", authz.Require(models.PermissionAPIKeyManage), h.RevokeKey)
+		keys.POST("/:id/rotate", authz.Require(models.PermissionAPIKeyManage), h.RotateKey)
+	}
+}
+
+func SetupPermissionTokenRoutes(rg *gin.RouterGroup, h *handlers.PermissionTokenHandler, authz *middleware.Authorizer) {
+	tokens := rg.Group("/permission-tokens")
+	{
+		tokens.POST("", authz.Require(models.PermissionTokenIssue), h.IssueToken)
+	}
+}
+
+func SetupOutboxRoutes(rg *gin.RouterGroup, h *handlers.OutboxHandler, authz *middleware.Authorizer) {
+	outbox := rg.Group("/outbox")
+	{
+		outbox.GET("/events", authz.Require(models.PermissionOutboxView), h.GetEvents)
+		outbox.GET("/events/:id", authz.Require(models.PermissionOutboxView), h.GetEvent)
+		outbox.GET("/dead-letters", authz.Require(models.PermissionOutboxView), h.GetDeadLetters)
+		outbox.POST("/dead-letters/:id/retry", authz.Require(models.PermissionOutboxManage), h.RetryDeadLetter)
+	}
+}
+
+// SetupWellKnownRoutes registers the public documents other services discover this one through
+func SetupWellKnownRoutes(router *gin.Engine, h *handlers.PermissionTokenHandler) {
+	router.GET("/.well-known/jwks.json", h.GetJWKS)
+}
+
 func SetupHealthRoutes(router *gin.Engine) {
 	router.GET("/ping", func(c *gin.Context) {
 		c.JSON(200, gin.H{"message": "pong", "status": "healthy"})
 	})
 }
 
-func SetupAPIRoutes(router *gin.Engine, h *handlers.Handlers) {
+// SetupAPIRoutes registers the health routes and the /api/v1 routes. Every /api/v1 route requires
+// authenticate to pass and the caller to hold the permission its route names.
+func SetupAPIRoutes(router *gin.Engine, h *handlers.Handlers, authenticate gin.HandlerFunc, authz *middleware.Authorizer) {
 	SetupHealthRoutes(router)
+	SetupWellKnownRoutes(router, h.PermissionToken)
 
-	api := router.Group("/api/v1")
+	api := router.Group("/api/v1", authenticate)
 	{
-		SetupRoleRoutes(api, h.Role)
-		SetupPermissionRoutes(api, h.Permission)
-		SetupUserBanRoutes(api, h.UserBan)
+		SetupRoleRoutes(api, h.Role, authz)
+		SetupPermissionRoutes(api, h.Permission, authz)
+		SetupUserBanRoutes(api, h.UserBan, authz)
+		SetupUserRoleRoutes(api, h.UserRole, authz)
+		SetupAuthorizationRoutes(api, h.Authorization, authz)
+		SetupUserAttributeRoutes(api, h.UserAttribute, authz)
+		SetupBanReasonRoutes(api, h.BanReason, authz)
+		SetupBanAppealRoutes(api, h.BanAppeal, authz)
+		SetupAuditLogRoutes(api, h.AuditLog, authz)
+		SetupAPIKeyRoutes(api, h.APIKey, authz)
+		SetupPermissionTokenRoutes(api, h.PermissionToken, authz)
+		SetupOutboxRoutes(api, h.Outbox, authz)
 	}
-}
\ No newline at end of file
+}
@@ -3,17 +3,26 @@ package database
 import (
 	"fmt"
 	"gin/internal/config"
+	"gin/internal/models"
 
 	"gorm.io/driver/postgres"
 	"gorm.io/gorm"
 )
 
-
 func Connect(connectionString string) (*gorm.DB, error) {
 	db, err := gorm.Open(postgres.Open(connectionString), &gorm.Config{})
 	if err != nil {
 		return nil, fmt.Errorf("error connecting to database: %w", err)
 	}
+
+	// role_permission carries the resource scope of each grant
+	if err := db.SetupJoinTable(&models.Role{}, "Permissions", &models.RolePermission{}); err != nil {
+		return nil, fmt.Errorf("error setting up role_permission join table: %w", err)
+	}
+	if err := db.SetupJoinTable(&models.Permission{}, "Roles", &models.RolePermission{}); err != nil {
+		return nil, fmt.Errorf("error setting up role_permission join table: %w", err)
+	}
+
 	return db, nil
 }
 
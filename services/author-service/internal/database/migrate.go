@@ -6,14 +6,24 @@ import (
 	"gin/internal/models"
 
 	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
 )
 
 func AutoMigrate(db *gorm.DB) error {
+	if err := renameLegacyJoinColumns(db); err != nil {
+		return err
+	}
+
 	// Migrate in order to avoid foreign key constraints issues
 	// First create tables without foreign keys
 	err := db.AutoMigrate(
 		&models.Role{},
 		&models.Permission{},
+		&models.BanReason{},
+		&models.AuditLog{},
+		&models.AuditCheckpoint{},
+		&models.APIKey{},
+		&models.OutboxEvent{},
 	)
 	if err != nil {
 		return fmt.Errorf("failed to auto-migrate base tables: %w", err)
@@ -22,27 +32,379 @@ func AutoMigrate(db *gorm.DB) error {
 	// Then create tables with foreign keys
 	err = db.AutoMigrate(
 		&models.UserBan{},
+		&models.UserRole{},
+		&models.RoleParent{},
+		&models.RolePermission{},
+		&models.UserAttribute{},
+		&models.BanAppeal{},
+		&models.BanAppealTransition{},
+		&models.OutboxDelivery{},
+		&models.OutboxDeadLetter{},
 	)
 	if err != nil {
 		return fmt.Errorf("failed to auto-migrate dependent tables: %w", err)
 	}
 
+	if err := updateRolePermissionKey(db); err != nil {
+		return err
+	}
+
+	// Global bans have no permission
+	if err := db.Exec("ALTER TABLE user_bans ALTER COLUMN perm_id DROP NOT NULL").Error; err != nil {
+		return fmt.Errorf("failed to make user_bans.perm_id nullable: %w", err)
+	}
+
+	if err := protectAuditLog(db); err != nil {
+		return err
+	}
+
 	fmt.Println("Database migration completed successfully")
 	return nil
 }
 
+// rolePermissionKey is the primary key of role_permission; scoped grants need the scope in it
+const rolePermissionKey = "role_id,perm_id,resource_type,resource_id"
+
+// updateRolePermissionKey gives role_permission the primary key of scoped grants. AutoMigrate does
+// not alter existing primary keys, and rebuilding it locks the table, so it is only replaced when
+// its columns differ.
+func updateRolePermissionKey(db *gorm.DB) error {
+	var columns string
+	err := db.Raw(`
+		SELECT COALESCE(string_agg(a.attname, ',' ORDER BY k.ord), '')
+		FROM pg_constraint c
+		CROSS JOIN LATERAL unnest(c.conkey) WITH ORDINALITY AS k(attnum, ord)
+		JOIN pg_attribute a ON a.attrelid = c.conrelid AND a.attnum = k.attnum
+		WHERE c.conrelid = 'role_permission'::regclass AND c.contype = 'p'`).
+		Scan(&columns).Error
+	if err != nil {
+		return fmt.Errorf("failed to read role_permission primary key: %w", err)
+	}
+	if columns == rolePermissionKey {
+		return nil
+	}
+
+	err = db.Exec(`ALTER TABLE role_permission
+		DROP CONSTRAINT IF EXISTS role_permission_pkey,
+		ADD PRIMARY KEY (` + rolePermissionKey + `)`).Error
+	if err != nil {
+		return fmt.Errorf("failed to update role_permission primary key: %w", err)
+	}
+
+	return nil
+}
+
+// protectAuditLog makes audit_logs and audit_checkpoints append-only: rows can be inserted but
+// never changed or removed
+func protectAuditLog(db *gorm.DB) error {
+	err := db.Exec(`CREATE OR REPLACE FUNCTION reject_audit_log_change() RETURNS trigger AS $$
+		BEGIN
+			RAISE EXCEPTION '% is append-only', TG_TABLE_NAME;
+		END;
+		$$ LANGUAGE plpgsql`).Error
+	if err != nil {
+		return fmt.Errorf("failed to create audit log guard: %w", err)
+	}
+
+	for _, table := range []string{"audit_logs", "audit_checkpoints"} {
+		err = db.Exec(fmt.Sprintf(`DROP TRIGGER IF EXISTS %[1]s_append_only ON %[1]s`, table)).Error
+		if err != nil {
+			return fmt.Errorf("failed to replace %s trigger: %w", table, err)
+		}
+
+		err = db.Exec(fmt.Sprintf(`CREATE TRIGGER %[1]s_append_only
+			BEFORE UPDATE OR DELETE OR TRUNCATE ON %[1]s
+			FOR EACH STATEMENT EXECUTE FUNCTION reject_audit_log_change()`, table)).Error
+		if err != nil {
+			return fmt.Errorf("failed to create %s trigger: %w", table, err)
+		}
+	}
+
+	return nil
+}
+
+// renameLegacyJoinColumns renames the role_permission columns created with GORM's default
+// many2many naming to the role_id/perm_id names used by the schema and the raw queries
+func renameLegacyJoinColumns(db *gorm.DB) error {
+	renames := map[string]string{
+		"role_role_id":       "role_id",
+		"permission_perm_id": "perm_id",
+	}
+
+	for oldName, newName := range renames {
+		if !db.Migrator().HasColumn("role_permission", oldName) {
+			continue
+		}
+		if err := db.Migrator().RenameColumn("role_permission", oldName, newName); err != nil {
+			return fmt.Errorf("failed to rename role_permission.%s: %w", oldName, err)
+		}
+	}
+
+	return nil
+}
+
 func MigrateAndSeed(db *gorm.DB) error {
 	if err := AutoMigrate(db); err != nil {
 		return err
 	}
 
+	if err := renameLegacyPermissions(db); err != nil {
+		return fmt.Errorf("failed to rename legacy permissions: %w", err)
+	}
+
+	if err := seedAPIPermissions(db); err != nil {
+		return fmt.Errorf("failed to seed admin API permissions: %w", err)
+	}
+
 	if err := seedInitialData(db); err != nil {
 		return fmt.Errorf("failed to seed data: %w", err)
 	}
 
+	if err := seedDefaultRoles(db); err != nil {
+		return fmt.Errorf("failed to seed default roles: %w", err)
+	}
+
+	if err := seedBanReasons(db); err != nil {
+		return fmt.Errorf("failed to seed ban reasons: %w", err)
+	}
+
+	return nil
+}
+
+// GrantBootstrapAdmin gives a user the admin role, so that a fresh deployment has someone who
+// can assign roles through the API
+func GrantBootstrapAdmin(db *gorm.DB, userID string) error {
+	var admin models.Role
+	if err := db.Where("name = ?", "admin").First(&admin).Error; err != nil {
+		return fmt.Errorf("failed to find admin role: %w", err)
+	}
+
+	userRole := models.UserRole{UserID: userID, RoleID: admin.RoleID}
+	if err := db.Where(userRole).FirstOrCreate(&userRole).Error; err != nil {
+		return fmt.Errorf("failed to grant admin role to %s: %w", userID, err)
+	}
+
 	return nil
 }
 
+// legacyPermissionNames maps the permission names seeded before the resource:action scheme to
+// their current names
+var legacyPermissionNames = map[string]string{
+	"create_game_room":   "room:create",
+	"admin":              models.PermissionWildcard,
+	"ban_user":           "moderation:ban",
+	"unban_user":         "moderation:unban",
+	"view_banned_users":  "moderation:view_bans",
+	"assign_roles":       "role:assign",
+	"remove_roles":       "role:remove",
+	"view_roles":         "role:view",
+	"create_permissions": "permission:create",
+	"delete_permissions": "permission:delete",
+	"view_permissions":   "permission:view",
+	"assign_permissions": "permission:assign",
+	"remove_permissions": "permission:remove",
+}
+
+// renameLegacyPermissions brings a database seeded before the resource:action scheme up to date.
+// A legacy permission is renamed, or merged into its current name when both exist, and admin and
+// player get the "*" and room:create grants they are seeded with. It is a no-op once applied.
+func renameLegacyPermissions(db *gorm.DB) error {
+	return db.Transaction(func(tx *gorm.DB) error {
+		for oldName, newName := range legacyPermissionNames {
+			var legacy models.Permission
+			if err := tx.Where("name = ?", oldName).Limit(1).Find(&legacy).Error; err != nil {
+				return fmt.Errorf("failed to find permission %s: %w", oldName, err)
+			}
+			if legacy.PermID == 0 {
+				continue
+			}
+
+			var current models.Permission
+			if err := tx.Where("name = ?", newName).Limit(1).Find(&current).Error; err != nil {
+				return fmt.Errorf("failed to find permission %s: %w", newName, err)
+			}
+			if current.PermID == 0 {
+				if err := tx.Model(&legacy).Update("name", newName).Error; err != nil {
+					return fmt.Errorf("failed to rename permission %s to %s: %w", oldName, newName, err)
+				}
+				continue
+			}
+
+			if err := mergePermission(tx, legacy.PermID, current.PermID); err != nil {
+				return fmt.Errorf("failed to merge permission %s into %s: %w", oldName, newName, err)
+			}
+		}
+
+		defaults := map[string]string{
+			"admin":  models.PermissionWildcard,
+			"player": "room:create",
+		}
+
+		for roleName, permName := range defaults {
+			var role models.Role
+			if err := tx.Where("name = ?", roleName).Limit(1).Find(&role).Error; err != nil {
+				return fmt.Errorf("failed to find role %s: %w", roleName, err)
+			}
+			if role.RoleID == 0 {
+				// Not seeded yet; seedInitialData grants it
+				continue
+			}
+
+			permission := models.Permission{Name: permName}
+			if err := tx.FirstOrCreate(&permission, models.Permission{Name: permName}).Error; err != nil {
+				return fmt.Errorf("failed to create permission %s: %w", permName, err)
+			}
+
+			// An existing unscoped grant of the permission, allow or deny, is left as it is
+			grant := models.RolePermission{RoleID: role.RoleID, PermID: permission.PermID, Effect: models.GrantEffectAllow}
+			if err := tx.Clauses(clause.OnConflict{DoNothing: true}).Create(&grant).Error; err != nil {
+				return fmt.Errorf("failed to grant %s to role %s: %w", permName, roleName, err)
+			}
+		}
+
+		return nil
+	})
+}
+
+// mergePermission moves the grants, bans and ban exemptions of one permission to another and
+// deletes it
+func mergePermission(tx *gorm.DB, fromID, toID uint) error {
+	statements := []string{
+		`INSERT INTO role_permission (role_id, perm_id, resource_type, resource_id, condition, effect)
+			SELECT role_id, @to, resource_type, resource_id, condition, effect
+			FROM role_permission WHERE perm_id = @from
+			ON CONFLICT DO NOTHING`,
+		`DELETE FROM role_permission WHERE perm_id = @from`,
+		`INSERT INTO user_ban_exemptions (ban_id, perm_id)
+			SELECT ban_id, @to FROM user_ban_exemptions WHERE perm_id = @from
+			ON CONFLICT DO NOTHING`,
+		`DELETE FROM user_ban_exemptions WHERE perm_id = @from`,
+		`UPDATE user_bans SET perm_id = @to WHERE perm_id = @from`,
+		`DELETE FROM permissions WHERE perm_id = @from`,
+	}
+
+	args := map[string]interface{}{"from": fromID, "to": toID}
+	for _, statement := range statements {
+		if err := tx.Exec(statement, args).Error; err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// seedAPIPermissions adds the permissions the admin API checks that are missing, so that routes
+// added since the database was first seeded can be granted
+func seedAPIPermissions(db *gorm.DB) error {
+	for _, name := range models.AdminAPIPermissions {
+		permission := models.Permission{Name: name}
+		if err := db.FirstOrCreate(&permission, models.Permission{Name: name}).Error; err != nil {
+			return fmt.Errorf("failed to create permission %s: %w", name, err)
+		}
+	}
+
+	return nil
+}
+
+// seedBanReasons adds the standard reason codes that are missing; existing entries are left as
+// moderators edited them
+func seedBanReasons(db *gorm.DB) error {
+	const day = 24 * 60 * 60
+
+	reasons := []models.BanReason{
+		{
+			Code:                   "cheating",
+			Label:                  "Cheating",
+			PlayerText:             "Your account was suspended for using cheats or exploits.",
+			DefaultKind:            models.BanKindSuspension,
+			DefaultDurationSeconds: 30 * day,
+		},
+		{
+			Code:                   "harassment",
+			Label:                  "Harassment",
+			PlayerText:             "You were muted for harassing other players.",
+			DefaultKind:            models.BanKindMute,
+			DefaultDurationSeconds: 7 * day,
+		},
+		{
+			Code:                   "afk_abuse",
+			Label:                  "AFK abuse",
+			PlayerText:             "You were placed in the low-priority queue for repeatedly leaving matches idle.",
+			DefaultKind:            models.BanKindRestriction,
+			DefaultDurationSeconds: day,
+		},
+		{
+			Code:        "offensive_name",
+			Label:       "Offensive name",
+			PlayerText:  "Your display name breaks the naming rules. Please change it.",
+			DefaultKind: models.BanKindWarning,
+		},
+	}
+
+	for i := range reasons {
+		if err := db.FirstOrCreate(&reasons[i], models.BanReason{Code: reasons[i].Code}).Error; err != nil {
+			return fmt.Errorf("failed to create ban reason %s: %w", reasons[i].Code, err)
+		}
+	}
+
+	return nil
+}
+
+// defaultRoleGrants are the grants seedDefaultRoles makes sure of: moderator can ban, review
+// appeals and read the audit log, but not change roles or permissions, and restricted cannot
+// create rooms, whatever its holder's other roles grant
+var defaultRoleGrants = []struct {
+	role       string
+	permission string
+	effect     string
+}{
+	{"player", models.PermissionAppealSubmit, models.GrantEffectAllow},
+	{"moderator", "moderation:*", models.GrantEffectAllow},
+	{"moderator", models.PermissionRoleView, models.GrantEffectAllow},
+	{"moderator", models.PermissionPermissionView, models.GrantEffectAllow},
+	{"moderator", models.PermissionAttributeView, models.GrantEffectAllow},
+	{"moderator", models.PermissionAuthorizationCheck, models.GrantEffectAllow},
+	{"moderator", models.PermissionAuditView, models.GrantEffectAllow},
+	{"restricted", "room:create", models.GrantEffectDeny},
+}
+
+// seedDefaultRoles adds the default roles, grants and hierarchy that are missing, so that
+// databases seeded before they were introduced get them too. Existing rows are left as they
+// are, and it is a no-op once applied.
+func seedDefaultRoles(db *gorm.DB) error {
+	return db.Transaction(func(tx *gorm.DB) error {
+		roles := map[string]*models.Role{}
+		for _, name := range []string{"player", "admin", "moderator", "restricted"} {
+			role := models.Role{Name: name}
+			if err := tx.FirstOrCreate(&role, models.Role{Name: name}).Error; err != nil {
+				return fmt.Errorf("failed to create role %s: %w", name, err)
+			}
+			roles[name] = &role
+		}
+
+		for _, grant := range defaultRoleGrants {
+			permission := models.Permission{Name: grant.permission}
+			if err := tx.FirstOrCreate(&permission, models.Permission{Name: grant.permission}).Error; err != nil {
+				return fmt.Errorf("failed to create permission %s: %w", grant.permission, err)
+			}
+
+			// An existing unscoped grant of the permission, allow or deny, is left as it is
+			rolePermission := models.RolePermission{RoleID: roles[grant.role].RoleID, PermID: permission.PermID, Effect: grant.effect}
+			if err := tx.Clauses(clause.OnConflict{DoNothing: true}).Create(&rolePermission).Error; err != nil {
+				return fmt.Errorf("failed to grant %s to role %s: %w", grant.permission, grant.role, err)
+			}
+		}
+
+		// admin inherits everything player can do
+		adminInheritsPlayer := models.RoleParent{RoleID: roles["admin"].RoleID, ParentID: roles["player"].RoleID}
+		if err := tx.Clauses(clause.OnConflict{DoNothing: true}).Create(&adminInheritsPlayer).Error; err != nil {
+			return fmt.Errorf("failed to create role hierarchy: %w", err)
+		}
+
+		return nil
+	})
+}
+
 func seedInitialData(db *gorm.DB) error {
 	var count int64
 	db.Model(&models.Role{}).Count(&count)
@@ -52,25 +414,27 @@ func seedInitialData(db *gorm.DB) error {
 		return nil
 	}
 
+	// Permission names follow the resource:action scheme; "*" grants everything
 	permissions := []models.Permission{
-		{Name: "create_game_room"},
-		{Name: "admin"},
-		{Name: "ban_user"},
-		{Name: "unban_user"},
-		{Name: "view_banned_users"},
-		{Name: "assign_roles"},
-		{Name: "remove_roles"},
-		{Name: "view_roles"},
-		{Name: "create_permissions"},
-		{Name: "delete_permissions"},
-		{Name: "view_permissions"},
-		{Name: "assign_permissions"},
-		{Name: "remove_permissions"},
+		{Name: "room:create"},
+		{Name: models.PermissionWildcard},
+		{Name: "moderation:*"},
+		{Name: "moderation:ban"},
+		{Name: "moderation:unban"},
+		{Name: "moderation:view_bans"},
+		{Name: "role:assign"},
+		{Name: "role:remove"},
+		{Name: "role:view"},
+		{Name: "permission:create"},
+		{Name: "permission:delete"},
+		{Name: "permission:view"},
+		{Name: "permission:assign"},
+		{Name: "permission:remove"},
 	}
 
-	for _, perm := range permissions {
-		if err := db.FirstOrCreate(&perm, models.Permission{Name: perm.Name}).Error; err != nil {
-			return fmt.Errorf("failed to create permission %s: %w", perm.Name, err)
+	for i := range permissions {
+		if err := db.FirstOrCreate(&permissions[i], models.Permission{Name: permissions[i].Name}).Error; err != nil {
+			return fmt.Errorf("failed to create permission %s: %w", permissions[i].Name, err)
 		}
 	}
 
@@ -79,12 +443,29 @@ func seedInitialData(db *gorm.DB) error {
 		{Name: "admin"},
 	}
 
+	for i := range roles {
+		if err := db.FirstOrCreate(&roles[i], models.Role{Name: roles[i].Name}).Error; err != nil {
+			return fmt.Errorf("failed to create role %s: %w", roles[i].Name, err)
+		}
+	}
+
+	grants := map[string][]string{
+		"player": {"room:create"},
+		"admin":  {models.PermissionWildcard},
+	}
+
 	for _, role := range roles {
-		if err := db.FirstOrCreate(&role, models.Role{Name: role.Name}).Error; err != nil {
-			return fmt.Errorf("failed to create role %s: %w", role.Name, err)
+		for _, permName := range grants[role.Name] {
+			var permission models.Permission
+			if err := db.Where("name = ?", permName).First(&permission).Error; err != nil {
+				return fmt.Errorf("failed to find permission %s: %w", permName, err)
+			}
+			if err := db.Model(&role).Association("Permissions").Append(&permission); err != nil {
+				return fmt.Errorf("failed to grant %s to role %s: %w", permName, role.Name, err)
+			}
 		}
 	}
 
 	fmt.Println("Initial data seeded successfully")
 	return nil
-}
\ No newline at end of file
+}
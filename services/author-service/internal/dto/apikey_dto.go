@@ -0,0 +1,31 @@
+package dto
+
+// API Key DTOs
+type CreateAPIKeyRequest struct {
+	Name      string   `json:"name" binding:"required"`
+	Scopes    []string `json:"scopes" binding:"required"` // permission names, e.g. "authorization:check" or "moderation:*"
+	ExpiresIn string   `json:"expires_in"`                // e.g. "90d"; omitted for a key that never expires
+}
+
+type RotateAPIKeyRequest struct {
+	Grace string `json:"grace"` // how long the old key keeps working, e.g. "24h"; omitted revokes it at once
+}
+
+type APIKeyResponse struct {
+	ID          uint     `json:"id"`
+	Name        string   `json:"name"`
+	Prefix      string   `json:"prefix"`
+	Scopes      []string `json:"scopes"`
+	CreatedBy   string   `json:"created_by,omitempty"`
+	RotatedFrom *uint    `json:"rotated_from,omitempty"`
+	Active      bool     `json:"active"`
+	ExpiresAt   string   `json:"expires_at,omitempty"`
+	LastUsedAt  string   `json:"last_used_at,omitempty"`
+	RevokedAt   string   `json:"revoked_at,omitempty"`
+	CreatedAt   string   `json:"created_at"`
+}
+
+type IssuedAPIKeyResponse struct {
+	APIKey APIKeyResponse `json:"api_key"`
+	Key    string         `json:"key"` // shown only once
+}
@@ -0,0 +1,23 @@
+package dto
+
+import "encoding/json"
+
+// Audit Log DTOs
+type AuditLogResponse struct {
+	ID         uint            `json:"id"`
+	Actor      string          `json:"actor"`
+	Action     string          `json:"action"`
+	TargetType string          `json:"target_type"`
+	TargetID   string          `json:"target_id,omitempty"`
+	Before     json.RawMessage `json:"before,omitempty"`
+	After      json.RawMessage `json:"after,omitempty"`
+	RequestID  string          `json:"request_id,omitempty"`
+	CreatedAt  string          `json:"created_at"`
+}
+
+type AuditLogPageResponse struct {
+	Entries  []AuditLogResponse `json:"entries"`
+	Page     int                `json:"page"`
+	PageSize int                `json:"page_size"`
+	Total    int64              `json:"total"`
+}
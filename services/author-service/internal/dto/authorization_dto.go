@@ -0,0 +1,60 @@
+package dto
+
+// Authorization DTOs
+type AuthorizeRequest struct {
+	UserID       string                 `json:"user_id" binding:"required"`
+	Permission   string                 `json:"permission"`
+	PermissionID uint                   `json:"permission_id"`
+	ResourceType string                 `json:"resource_type"`
+	ResourceID   string                 `json:"resource_id"`
+	Attributes   map[string]interface{} `json:"attributes"`
+}
+
+type AuthorizeCheck struct {
+	UserID       string                 `json:"user_id"`
+	Permission   string                 `json:"permission"`
+	PermissionID uint                   `json:"permission_id"`
+	ResourceType string                 `json:"resource_type"`
+	ResourceID   string                 `json:"resource_id"`
+	Attributes   map[string]interface{} `json:"attributes"`
+}
+
+type BatchAuthorizeRequest struct {
+	Checks []AuthorizeCheck `json:"checks" binding:"required,min=1"`
+}
+
+type AuthorizeResponse struct {
+	UserID        string             `json:"user_id"`
+	Permission    PermissionResponse `json:"permission"`
+	ResourceType  string             `json:"resource_type,omitempty"`
+	ResourceID    string             `json:"resource_id,omitempty"`
+	Allowed       bool               `json:"allowed"`
+	Reason        string             `json:"reason"`
+	Role          *RoleResponse      `json:"role,omitempty"`
+	GrantedAs     string             `json:"granted_as,omitempty"`
+	InheritedFrom *RoleResponse      `json:"inherited_from,omitempty"`
+	Condition     string             `json:"condition,omitempty"`
+	Ban           *UserBanResponse   `json:"ban,omitempty"`
+}
+
+type BatchAuthorizeResult struct {
+	Index    int                `json:"index"`
+	Decision *AuthorizeResponse `json:"decision,omitempty"`
+	Error    string             `json:"error,omitempty"`
+}
+
+type PermissionSourceResponse struct {
+	RoleID        uint          `json:"role_id"`
+	Name          string        `json:"name"`
+	InheritedFrom *RoleResponse `json:"inherited_from,omitempty"`
+	GrantedAs     string        `json:"granted_as"`
+	ResourceType  string        `json:"resource_type,omitempty"`
+	ResourceID    string        `json:"resource_id,omitempty"`
+	Condition     string        `json:"condition,omitempty"`
+}
+
+type EffectivePermissionResponse struct {
+	PermID    uint                       `json:"perm_id"`
+	Name      string                     `json:"name"`
+	GrantedBy []PermissionSourceResponse `json:"granted_by"`
+}
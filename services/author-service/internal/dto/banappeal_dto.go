@@ -0,0 +1,31 @@
+package dto
+
+// Ban Appeal DTOs
+type SubmitAppealRequest struct {
+	BanID   uint   `json:"ban_id" binding:"required"`
+	Message string `json:"message" binding:"required" validate:"min=1,max=2000"`
+}
+
+type ReviewAppealRequest struct {
+	Response string `json:"response" validate:"max=2000"`
+}
+
+type AppealTransitionResponse struct {
+	FromStatus string `json:"from_status,omitempty"`
+	ToStatus   string `json:"to_status"`
+	Actor      string `json:"actor"`
+	CreatedAt  string `json:"created_at"`
+}
+
+type AppealResponse struct {
+	ID          uint                       `json:"id"`
+	BanID       uint                       `json:"ban_id"`
+	UserID      string                     `json:"user_id"`
+	Message     string                     `json:"message"`
+	Status      string                     `json:"status"`
+	Response    string                     `json:"response,omitempty"`
+	ReviewedBy  string                     `json:"reviewed_by,omitempty"`
+	ReviewedAt  string                     `json:"reviewed_at,omitempty"`
+	CreatedAt   string                     `json:"created_at"`
+	Transitions []AppealTransitionResponse `json:"transitions,omitempty"`
+}
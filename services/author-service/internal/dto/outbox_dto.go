@@ -0,0 +1,53 @@
+package dto
+
+import "encoding/json"
+
+// Outbox DTOs
+type OutboxDeliveryResponse struct {
+	ID            uint   `json:"id"`
+	Sink          string `json:"sink"`
+	Status        string `json:"status"`
+	Attempts      int    `json:"attempts"`
+	NextAttemptAt string `json:"next_attempt_at,omitempty"` // only for pending deliveries
+	LastError     string `json:"last_error,omitempty"`
+	DeliveredAt   string `json:"delivered_at,omitempty"`
+}
+
+type OutboxEventResponse struct {
+	ID            uint                     `json:"id"`
+	Type          string                   `json:"type"`
+	AggregateType string                   `json:"aggregate_type"`
+	AggregateID   string                   `json:"aggregate_id,omitempty"`
+	Payload       json.RawMessage          `json:"payload"`
+	Actor         string                   `json:"actor,omitempty"`
+	RequestID     string                   `json:"request_id,omitempty"`
+	CreatedAt     string                   `json:"created_at"`
+	FannedOutAt   string                   `json:"fanned_out_at,omitempty"`
+	Deliveries    []OutboxDeliveryResponse `json:"deliveries"`
+}
+
+type OutboxEventPageResponse struct {
+	Events   []OutboxEventResponse `json:"events"`
+	Page     int                   `json:"page"`
+	PageSize int                   `json:"page_size"`
+	Total    int64                 `json:"total"`
+}
+
+type OutboxDeadLetterResponse struct {
+	ID         uint   `json:"id"`
+	DeliveryID uint   `json:"delivery_id"`
+	EventID    uint   `json:"event_id"`
+	EventType  string `json:"event_type"`
+	Sink       string `json:"sink"`
+	Attempts   int    `json:"attempts"`
+	LastError  string `json:"last_error"`
+	CreatedAt  string `json:"created_at"`
+	RetriedAt  string `json:"retried_at,omitempty"`
+}
+
+type OutboxDeadLetterPageResponse struct {
+	DeadLetters []OutboxDeadLetterResponse `json:"dead_letters"`
+	Page        int                        `json:"page"`
+	PageSize    int                        `json:"page_size"`
+	Total       int64                      `json:"total"`
+}
@@ -0,0 +1,14 @@
+package dto
+
+// Permission Token DTOs
+type IssuePermissionTokenRequest struct {
+	UserID       string `json:"user_id" binding:"required"`
+	ResourceType string `json:"resource_type"`
+	ResourceID   string `json:"resource_id"`
+}
+
+type PermissionTokenResponse struct {
+	Token     string `json:"token"`
+	TokenType string `json:"token_type"`
+	ExpiresAt string `json:"expires_at"`
+}
@@ -10,7 +10,15 @@ type UpdateRoleRequest struct {
 }
 
 type AddPermissionToRoleRequest struct {
-	PermissionID uint `json:"permission_id" binding:"required"`
+	PermissionID uint   `json:"permission_id" binding:"required"`
+	ResourceType string `json:"resource_type"`
+	ResourceID   string `json:"resource_id"`
+	Condition    string `json:"condition"`
+	Effect       string `json:"effect"`
+}
+
+type AddParentRoleRequest struct {
+	ParentID uint `json:"parent_id" binding:"required"`
 }
 
 type RoleResponse struct {
@@ -18,8 +26,28 @@ type RoleResponse struct {
 	Name   string `json:"name"`
 }
 
+type RoleGrantResponse struct {
+	PermID       uint   `json:"perm_id"`
+	Name         string `json:"name"`
+	ResourceType string `json:"resource_type,omitempty"`
+	ResourceID   string `json:"resource_id,omitempty"`
+	Condition    string `json:"condition,omitempty"`
+	Effect       string `json:"effect"`
+}
+
+type InheritedPermissionResponse struct {
+	PermID        uint         `json:"perm_id"`
+	Name          string       `json:"name"`
+	ResourceType  string       `json:"resource_type,omitempty"`
+	ResourceID    string       `json:"resource_id,omitempty"`
+	Condition     string       `json:"condition,omitempty"`
+	Effect        string       `json:"effect"`
+	InheritedFrom RoleResponse `json:"inherited_from"`
+}
+
 type RoleWithPermissionsResponse struct {
-	RoleID      uint                 `json:"role_id"`
-	Name        string               `json:"name"`
-	Permissions []PermissionResponse `json:"permissions"`
-}
\ No newline at end of file
+	RoleID               uint                          `json:"role_id"`
+	Name                 string                        `json:"name"`
+	Permissions          []RoleGrantResponse           `json:"permissions"`
+	InheritedPermissions []InheritedPermissionResponse `json:"inherited_permissions"`
+}
@@ -0,0 +1,11 @@
+package dto
+
+// User Attribute DTOs
+type SetUserAttributesRequest struct {
+	Attributes map[string]interface{} `json:"attributes" binding:"required"`
+}
+
+type UserAttributesResponse struct {
+	UserID     string                 `json:"user_id"`
+	Attributes map[string]interface{} `json:"attributes"`
+}
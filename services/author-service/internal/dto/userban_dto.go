@@ -2,26 +2,93 @@ package dto
 
 // User Ban DTOs
 type BanUserRequest struct {
-	PermissionID uint   `json:"permission_id" binding:"required"`
-	Reason       string `json:"reason" binding:"required" validate:"min=1,max=500"`
+	PermissionID        uint   `json:"permission_id"`             // omitted for a global ban
+	Reason              string `json:"reason" validate:"max=500"` // defaults to the label of reason_code
+	ReasonCode          string `json:"reason_code"`
+	Notes               string `json:"notes"`
+	Kind                string `json:"kind"`          // warning, restriction, shadow_ban, mute or suspension (default)
+	Duration            string `json:"duration"`      // e.g. "24h" or "7d"; omitted for a permanent ban
+	StrikePoints        *int   `json:"strike_points"` // defaults to the points of the kind
+	ResourceType        string `json:"resource_type"`
+	ResourceID          string `json:"resource_id"`
+	Global              bool   `json:"global"`
+	ExemptPermissionIDs []uint `json:"exempt_permission_ids"`
 }
 
 type UpdateBanReasonRequest struct {
 	Reason string `json:"reason" binding:"required" validate:"min=1,max=500"`
 }
 
+type CreateReasonCodeRequest struct {
+	Code            string `json:"code" binding:"required"`
+	Label           string `json:"label" binding:"required"`
+	PlayerText      string `json:"player_text"`
+	DefaultKind     string `json:"default_kind"`
+	DefaultDuration string `json:"default_duration"` // e.g. "7d"; omitted for permanent bans
+}
+
+type UpdateReasonCodeRequest struct {
+	Label           string `json:"label" binding:"required"`
+	PlayerText      string `json:"player_text"`
+	DefaultKind     string `json:"default_kind"`
+	DefaultDuration string `json:"default_duration"`
+}
+
+type ReasonCodeResponse struct {
+	Code            string `json:"code"`
+	Label           string `json:"label"`
+	PlayerText      string `json:"player_text"`
+	DefaultKind     string `json:"default_kind"`
+	DefaultDuration string `json:"default_duration,omitempty"`
+}
+
 type UserBanResponse struct {
-	ID         uint                `json:"id"`
-	UserID     string              `json:"user_id"`
-	PermID     uint                `json:"perm_id"`
-	Reason     string              `json:"reason"`
-	Permission *PermissionResponse `json:"permission,omitempty"`
-	CreatedAt  string              `json:"created_at"`
-	UpdatedAt  string              `json:"updated_at"`
+	ID           uint                 `json:"id"`
+	UserID       string               `json:"user_id"`
+	PermID       *uint                `json:"perm_id"`
+	BanType      string               `json:"ban_type"`
+	Kind         string               `json:"kind"`
+	StrikePoints int                  `json:"strike_points"`
+	Escalation   string               `json:"escalation_rule,omitempty"`
+	Reason       string               `json:"reason"`
+	ReasonCode   string               `json:"reason_code,omitempty"`
+	Notes        string               `json:"notes,omitempty"`
+	PlayerText   string               `json:"player_text,omitempty"`
+	ResourceType string               `json:"resource_type,omitempty"`
+	ResourceID   string               `json:"resource_id,omitempty"`
+	Permission   *PermissionResponse  `json:"permission,omitempty"`
+	Exemptions   []PermissionResponse `json:"exemptions,omitempty"`
+	ExpiresAt    string               `json:"expires_at,omitempty"`
+	ExpiredAt    string               `json:"expired_at,omitempty"`
+	IssuedBy     string               `json:"issued_by,omitempty"`
+	LiftedAt     string               `json:"lifted_at,omitempty"`
+	LiftedBy     string               `json:"lifted_by,omitempty"`
+	LiftReason   string               `json:"lift_reason,omitempty"`
+	CreatedAt    string               `json:"created_at"`
+	UpdatedAt    string               `json:"updated_at"`
+}
+
+type EscalationRuleResponse struct {
+	Name      string  `json:"name"`
+	Threshold float64 `json:"threshold"`
+	Kind      string  `json:"kind,omitempty"`
+	Duration  string  `json:"duration,omitempty"`
+	Permanent bool    `json:"permanent,omitempty"`
+	Global    bool    `json:"global,omitempty"`
+}
+
+type BanUserResponse struct {
+	UserBan     UserBanResponse         `json:"user_ban"`
+	StrikeTotal float64                 `json:"strike_total"`
+	Escalation  *EscalationRuleResponse `json:"escalation,omitempty"`
 }
 
 type CheckUserBanResponse struct {
-	UserID       string `json:"user_id"`
-	PermissionID uint   `json:"permission_id"`
-	IsBanned     bool   `json:"is_banned"`
-}
\ No newline at end of file
+	UserID       string           `json:"user_id"`
+	PermissionID uint             `json:"permission_id"`
+	ResourceType string           `json:"resource_type,omitempty"`
+	ResourceID   string           `json:"resource_id,omitempty"`
+	IsBanned     bool             `json:"is_banned"`      // true for a mute or suspension
+	Kind         string           `json:"kind,omitempty"` // most severe kind that applies
+	Ban          *UserBanResponse `json:"ban,omitempty"`
+}
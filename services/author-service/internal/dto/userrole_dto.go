@@ -0,0 +1,12 @@
+package dto
+
+// User Role DTOs
+type AssignRoleRequest struct {
+	RoleID uint `json:"role_id" binding:"required"`
+}
+
+type UserRoleResponse struct {
+	UserID    string       `json:"user_id"`
+	Role      RoleResponse `json:"role"`
+	CreatedAt string       `json:"created_at"`
+}
@@ -0,0 +1,168 @@
+package handlers
+
+import (
+	"net/http"
+	"strconv"
+	"time"
+
+	"gin/internal/dto"
+	"gin/internal/models"
+	"gin/internal/services"
+
+	"github.com/gin-gonic/gin"
+)
+
+// APIKeyHandler handles API key management HTTP requests
+type APIKeyHandler struct {
+	apiKeyService services.APIKeyServiceInterface
+}
+
+// NewAPIKeyHandler creates a new API key handler
+func NewAPIKeyHandler(apiKeyService services.APIKeyServiceInterface) *APIKeyHandler {
+	return &APIKeyHandler{
+		apiKeyService: apiKeyService,
+	}
+}
+
+func toAPIKeyResponse(key models.APIKey) dto.APIKeyResponse {
+	response := dto.APIKeyResponse{
+		ID:          key.ID,
+		Name:        key.Name,
+		Prefix:      key.Prefix,
+		Scopes:      key.ScopeList(),
+		CreatedBy:   key.CreatedBy,
+		RotatedFrom: key.RotatedFrom,
+		Active:      key.IsActive(time.Now()),
+		CreatedAt:   key.CreatedAt.Format(time.RFC3339),
+	}
+
+	if key.ExpiresAt != nil {
+		response.ExpiresAt = key.ExpiresAt.Format(time.RFC3339)
+	}
+
+	if key.LastUsedAt != nil {
+		response.LastUsedAt = key.LastUsedAt.Format(time.RFC3339)
+	}
+
+	if key.RevokedAt != nil {
+		response.RevokedAt = key.RevokedAt.Format(time.RFC3339)
+	}
+
+	return response
+}
+
+func toIssuedAPIKeyResponse(issued *services.IssuedAPIKey) dto.IssuedAPIKeyResponse {
+	return dto.IssuedAPIKeyResponse{
+		APIKey: toAPIKeyResponse(*issued.Key),
+		Key:    issued.Secret,
+	}
+}
+
+// CreateKey handles POST /api-keys
+func (h *APIKeyHandler) CreateKey(c *gin.Context) {
+	var req dto.CreateAPIKeyRequest
+
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, dto.ErrorResponse{Error: err.Error()})
+		return
+	}
+
+	var expiresIn time.Duration
+	if req.ExpiresIn != "" {
+		var err error
+		if expiresIn, err = services.ParseBanDuration(req.ExpiresIn); err != nil {
+			c.JSON(http.StatusBadRequest, dto.ErrorResponse{Error: "Invalid expires_in"})
+			return
+		}
+	}
+
+	issued, err := h.apiKeyService.CreateKey(c.Request.Context(), req.Name, req.Scopes, expiresIn)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, dto.ErrorResponse{Error: err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusCreated, toIssuedAPIKeyResponse(issued))
+}
+
+// GetKeys handles GET /api-keys
+func (h *APIKeyHandler) GetKeys(c *gin.Context) {
+	keys, err := h.apiKeyService.GetKeys()
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, dto.ErrorResponse{Error: err.Error()})
+		return
+	}
+
+	response := make([]dto.APIKeyResponse, 0, len(keys))
+	for _, key := range keys {
+		response = append(response, toAPIKeyResponse(key))
+	}
+
+	c.JSON(http.StatusOK, gin.H{"api_keys": response})
+}
+
+// GetKey handles GET /api-keys/:id
+func (h *APIKeyHandler) GetKey(c *gin.Context) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, dto.ErrorResponse{Error: "Invalid API key ID"})
+		return
+	}
+
+	key, err := h.apiKeyService.GetKey(uint(id))
+	if err != nil {
+		c.JSON(http.StatusNotFound, dto.ErrorResponse{Error: err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"api_key": toAPIKeyResponse(*key)})
+}
+
+// RevokeKey handles DELETE /api-keys/:id
+func (h *APIKeyHandler) RevokeKey(c *gin.Context) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, dto.ErrorResponse{Error: "Invalid API key ID"})
+		return
+	}
+
+	if err := h.apiKeyService.RevokeKey(c.Request.Context(), uint(id)); err != nil {
+		c.JSON(http.StatusBadRequest, dto.ErrorResponse{Error: err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, dto.MessageResponse{Message: "API key revoked successfully"})
+}
+
+// RotateKey handles POST /api-keys/:id/rotate
+func (h *APIKeyHandler) RotateKey(c *gin.Context) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, dto.ErrorResponse{Error: "Invalid API key ID"})
+		return
+	}
+
+	var req dto.RotateAPIKeyRequest
+	if c.Request.ContentLength > 0 {
+		if err := c.ShouldBindJSON(&req); err != nil {
+			c.JSON(http.StatusBadRequest, dto.ErrorResponse{Error: err.Error()})
+			return
+		}
+	}
+
+	var grace time.Duration
+	if req.Grace != "" {
+		if grace, err = services.ParseBanDuration(req.Grace); err != nil {
+			c.JSON(http.StatusBadRequest, dto.ErrorResponse{Error: "Invalid grace period"})
+			return
+		}
+	}
+
+	issued, err := h.apiKeyService.RotateKey(c.Request.Context(), uint(id), grace)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, dto.ErrorResponse{Error: err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusCreated, toIssuedAPIKeyResponse(issued))
+}
@@ -0,0 +1,110 @@
+package handlers
+
+import (
+	"encoding/json"
+	"net/http"
+	"strconv"
+	"time"
+
+	"gin/internal/dto"
+	"gin/internal/models"
+	"gin/internal/repositories"
+	"gin/internal/services"
+
+	"github.com/gin-gonic/gin"
+)
+
+// defaultAuditPageSize is the page size used when none is requested
+const defaultAuditPageSize = 50
+
+// AuditLogHandler handles audit log HTTP requests
+type AuditLogHandler struct {
+	auditLogService services.AuditLogServiceInterface
+}
+
+// NewAuditLogHandler creates a new audit log handler
+func NewAuditLogHandler(auditLogService services.AuditLogServiceInterface) *AuditLogHandler {
+	return &AuditLogHandler{
+		auditLogService: auditLogService,
+	}
+}
+
+func toAuditLogResponse(entry models.AuditLog) dto.AuditLogResponse {
+	response := dto.AuditLogResponse{
+		ID:         entry.ID,
+		Actor:      entry.Actor,
+		Action:     entry.Action,
+		TargetType: entry.TargetType,
+		TargetID:   entry.TargetID,
+		RequestID:  entry.RequestID,
+		CreatedAt:  entry.CreatedAt.Format(time.RFC3339),
+	}
+
+	if entry.Before != nil {
+		response.Before = json.RawMessage(*entry.Before)
+	}
+
+	if entry.After != nil {
+		response.After = json.RawMessage(*entry.After)
+	}
+
+	return response
+}
+
+// GetEntries handles GET /audit?actor=&action=&target_type=&target_id=&request_id=&since=&until=&page=&page_size=
+// since and until are RFC 3339 times; pages start at 1
+func (h *AuditLogHandler) GetEntries(c *gin.Context) {
+	page, err := strconv.Atoi(c.DefaultQuery("page", "1"))
+	if err != nil || page < 1 {
+		c.JSON(http.StatusBadRequest, dto.ErrorResponse{Error: "Invalid page"})
+		return
+	}
+
+	pageSize, err := strconv.Atoi(c.DefaultQuery("page_size", strconv.Itoa(defaultAuditPageSize)))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, dto.ErrorResponse{Error: "Invalid page size"})
+		return
+	}
+
+	filter := repositories.AuditLogFilter{
+		Actor:      c.Query("actor"),
+		Action:     c.Query("action"),
+		TargetType: c.Query("target_type"),
+		TargetID:   c.Query("target_id"),
+		RequestID:  c.Query("request_id"),
+		Limit:      pageSize,
+		Offset:     (page - 1) * pageSize,
+	}
+
+	if since := c.Query("since"); since != "" {
+		if filter.Since, err = time.Parse(time.RFC3339, since); err != nil {
+			c.JSON(http.StatusBadRequest, dto.ErrorResponse{Error: "Invalid since time"})
+			return
+		}
+	}
+
+	if until := c.Query("until"); until != "" {
+		if filter.Until, err = time.Parse(time.RFC3339, until); err != nil {
+			c.JSON(http.StatusBadRequest, dto.ErrorResponse{Error: "Invalid until time"})
+			return
+		}
+	}
+
+	entries, total, err := h.auditLogService.GetEntries(filter)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, dto.ErrorResponse{Error: err.Error()})
+		return
+	}
+
+	response := dto.AuditLogPageResponse{
+		Entries:  make([]dto.AuditLogResponse, 0, len(entries)),
+		Page:     page,
+		PageSize: pageSize,
+		Total:    total,
+	}
+	for _, entry := range entries {
+		response.Entries = append(response.Entries, toAuditLogResponse(entry))
+	}
+
+	c.JSON(http.StatusOK, response)
+}
@@ -0,0 +1,178 @@
+package handlers
+
+import (
+	"net/http"
+
+	"gin/internal/dto"
+	"gin/internal/models"
+	"gin/internal/services"
+
+	"github.com/gin-gonic/gin"
+)
+
+// AuthorizationHandler handles authorization decision HTTP requests
+type AuthorizationHandler struct {
+	authorizationService services.AuthorizationServiceInterface
+}
+
+// NewAuthorizationHandler creates a new authorization handler
+func NewAuthorizationHandler(authorizationService services.AuthorizationServiceInterface) *AuthorizationHandler {
+	return &AuthorizationHandler{
+		authorizationService: authorizationService,
+	}
+}
+
+func toAuthorizeResponse(decision *services.AuthorizationDecision) dto.AuthorizeResponse {
+	response := dto.AuthorizeResponse{
+		UserID: decision.UserID,
+		Permission: dto.PermissionResponse{
+			PermID: decision.Permission.PermID,
+			Name:   decision.Permission.Name,
+		},
+		ResourceType: decision.Scope.ResourceType,
+		ResourceID:   decision.Scope.ResourceID,
+		Allowed:      decision.Allowed,
+		Reason:       decision.Reason,
+		GrantedAs:    decision.GrantedAs,
+		Condition:    decision.Condition,
+	}
+
+	if decision.Role != nil {
+		response.Role = &dto.RoleResponse{
+			RoleID: decision.Role.RoleID,
+			Name:   decision.Role.Name,
+		}
+	}
+
+	if decision.InheritedFrom != nil {
+		response.InheritedFrom = &dto.RoleResponse{
+			RoleID: decision.InheritedFrom.RoleID,
+			Name:   decision.InheritedFrom.Name,
+		}
+	}
+
+	if decision.Ban != nil {
+		ban := toUserBanResponse(*decision.Ban)
+		response.Ban = &ban
+	}
+
+	return response
+}
+
+// Authorize handles POST /authorize
+func (h *AuthorizationHandler) Authorize(c *gin.Context) {
+	var req dto.AuthorizeRequest
+
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, dto.ErrorResponse{Error: err.Error()})
+		return
+	}
+
+	decision, err := h.authorizationService.Authorize(services.AuthorizationQuery{
+		UserID:         req.UserID,
+		PermissionName: req.Permission,
+		PermissionID:   req.PermissionID,
+		Scope: models.ResourceScope{
+			ResourceType: req.ResourceType,
+			ResourceID:   req.ResourceID,
+		},
+		Attributes: req.Attributes,
+	})
+	if err != nil {
+		c.JSON(http.StatusBadRequest, dto.ErrorResponse{Error: err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, toAuthorizeResponse(decision))
+}
+
+// AuthorizeBatch handles POST /authorize/batch
+func (h *AuthorizationHandler) AuthorizeBatch(c *gin.Context) {
+	var req dto.BatchAuthorizeRequest
+
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, dto.ErrorResponse{Error: err.Error()})
+		return
+	}
+
+	queries := make([]services.AuthorizationQuery, len(req.Checks))
+	for i, check := range req.Checks {
+		queries[i] = services.AuthorizationQuery{
+			UserID:         check.UserID,
+			PermissionName: check.Permission,
+			PermissionID:   check.PermissionID,
+			Scope: models.ResourceScope{
+				ResourceType: check.ResourceType,
+				ResourceID:   check.ResourceID,
+			},
+			Attributes: check.Attributes,
+		}
+	}
+
+	results, err := h.authorizationService.AuthorizeBatch(queries)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, dto.ErrorResponse{Error: err.Error()})
+		return
+	}
+
+	response := make([]dto.BatchAuthorizeResult, len(results))
+	for i, result := range results {
+		response[i].Index = i
+		if result.Err != nil {
+			response[i].Error = result.Err.Error()
+			continue
+		}
+		decision := toAuthorizeResponse(result.Decision)
+		response[i].Decision = &decision
+	}
+
+	c.JSON(http.StatusOK, gin.H{"results": response})
+}
+
+// GetEffectivePermissions handles GET /users/:user_id/permissions
+func (h *AuthorizationHandler) GetEffectivePermissions(c *gin.Context) {
+	userID := c.Param("user_id")
+	scope := models.ResourceScope{
+		ResourceType: c.Query("resource_type"),
+		ResourceID:   c.Query("resource_id"),
+	}
+
+	effective, err := h.authorizationService.GetEffectivePermissions(userID, scope)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, dto.ErrorResponse{Error: err.Error()})
+		return
+	}
+
+	response := []dto.EffectivePermissionResponse{}
+	for _, permission := range effective {
+		item := dto.EffectivePermissionResponse{
+			PermID: permission.Permission.PermID,
+			Name:   permission.Permission.Name,
+		}
+		for _, source := range permission.GrantedBy {
+			grantedBy := dto.PermissionSourceResponse{
+				RoleID:       source.Role.RoleID,
+				Name:         source.Role.Name,
+				GrantedAs:    source.GrantedAs,
+				ResourceType: source.Scope.ResourceType,
+				ResourceID:   source.Scope.ResourceID,
+				Condition:    source.Condition,
+			}
+			if source.InheritedFrom != nil {
+				grantedBy.InheritedFrom = &dto.RoleResponse{
+					RoleID: source.InheritedFrom.RoleID,
+					Name:   source.InheritedFrom.Name,
+				}
+			}
+			item.GrantedBy = append(item.GrantedBy, grantedBy)
+		}
+		response = append(response, item)
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"user_id":       userID,
+		"resource_type": scope.ResourceType,
+		"resource_id":   scope.ResourceID,
+		"permissions":   response,
+	})
+}
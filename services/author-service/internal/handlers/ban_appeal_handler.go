@@ -0,0 +1,153 @@
+package handlers
+
+import (
+	"context"
+	"net/http"
+	"strconv"
+	"time"
+
+	"gin/internal/dto"
+	"gin/internal/models"
+	"gin/internal/services"
+
+	"github.com/gin-gonic/gin"
+)
+
+// BanAppealHandler handles ban appeal HTTP requests
+type BanAppealHandler struct {
+	banAppealService services.BanAppealServiceInterface
+}
+
+// NewBanAppealHandler creates a new ban appeal handler
+func NewBanAppealHandler(banAppealService services.BanAppealServiceInterface) *BanAppealHandler {
+	return &BanAppealHandler{
+		banAppealService: banAppealService,
+	}
+}
+
+func toAppealResponse(appeal models.BanAppeal) dto.AppealResponse {
+	response := dto.AppealResponse{
+		ID:         appeal.ID,
+		BanID:      appeal.BanID,
+		UserID:     appeal.UserID,
+		Message:    appeal.Message,
+		Status:     appeal.Status,
+		Response:   appeal.Response,
+		ReviewedBy: appeal.ReviewedBy,
+		CreatedAt:  appeal.CreatedAt.Format(time.RFC3339),
+	}
+
+	if appeal.ReviewedAt != nil {
+		response.ReviewedAt = appeal.ReviewedAt.Format(time.RFC3339)
+	}
+
+	for _, transition := range appeal.Transitions {
+		response.Transitions = append(response.Transitions, dto.AppealTransitionResponse{
+			FromStatus: transition.FromStatus,
+			ToStatus:   transition.ToStatus,
+			Actor:      transition.Actor,
+			CreatedAt:  transition.CreatedAt.Format(time.RFC3339),
+		})
+	}
+
+	return response
+}
+
+func toAppealResponses(appeals []models.BanAppeal) []dto.AppealResponse {
+	response := []dto.AppealResponse{}
+	for _, appeal := range appeals {
+		response = append(response, toAppealResponse(appeal))
+	}
+	return response
+}
+
+// SubmitAppeal handles POST /users/:user_id/appeals
+func (h *BanAppealHandler) SubmitAppeal(c *gin.Context) {
+	userID := c.Param("user_id")
+
+	var req dto.SubmitAppealRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, dto.ErrorResponse{Error: err.Error()})
+		return
+	}
+
+	appeal, err := h.banAppealService.SubmitAppeal(c.Request.Context(), userID, req.BanID, req.Message)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, dto.ErrorResponse{Error: err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusCreated, gin.H{"appeal": toAppealResponse(*appeal)})
+}
+
+// GetUserAppeals handles GET /users/:user_id/appeals
+func (h *BanAppealHandler) GetUserAppeals(c *gin.Context) {
+	appeals, err := h.banAppealService.GetUserAppeals(c.Param("user_id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, dto.ErrorResponse{Error: err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"appeals": toAppealResponses(appeals)})
+}
+
+// GetAppeals handles GET /appeals?status=pending
+func (h *BanAppealHandler) GetAppeals(c *gin.Context) {
+	appeals, err := h.banAppealService.GetAppeals(c.Query("status"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, dto.ErrorResponse{Error: err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"appeals": toAppealResponses(appeals)})
+}
+
+// GetAppeal handles GET /appeals/:id
+func (h *BanAppealHandler) GetAppeal(c *gin.Context) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, dto.ErrorResponse{Error: "Invalid appeal ID"})
+		return
+	}
+
+	appeal, err := h.banAppealService.GetAppeal(uint(id))
+	if err != nil {
+		c.JSON(http.StatusNotFound, dto.ErrorResponse{Error: err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"appeal": toAppealResponse(*appeal)})
+}
+
+// AcceptAppeal handles POST /appeals/:id/accept
+func (h *BanAppealHandler) AcceptAppeal(c *gin.Context) {
+	h.reviewAppeal(c, h.banAppealService.AcceptAppeal)
+}
+
+// RejectAppeal handles POST /appeals/:id/reject
+func (h *BanAppealHandler) RejectAppeal(c *gin.Context) {
+	h.reviewAppeal(c, h.banAppealService.RejectAppeal)
+}
+
+// reviewAppeal reviews the appeal on behalf of the caller
+func (h *BanAppealHandler) reviewAppeal(c *gin.Context, review func(ctx context.Context, id uint, response string) (*models.BanAppeal, error)) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, dto.ErrorResponse{Error: "Invalid appeal ID"})
+		return
+	}
+
+	var req dto.ReviewAppealRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, dto.ErrorResponse{Error: err.Error()})
+		return
+	}
+
+	appeal, err := review(c.Request.Context(), uint(id), req.Response)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, dto.ErrorResponse{Error: err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"appeal": toAppealResponse(*appeal)})
+}
@@ -0,0 +1,148 @@
+package handlers
+
+import (
+	"net/http"
+	"time"
+
+	"gin/internal/dto"
+	"gin/internal/models"
+	"gin/internal/services"
+
+	"github.com/gin-gonic/gin"
+)
+
+// BanReasonHandler handles HTTP requests for the ban reason catalogue
+type BanReasonHandler struct {
+	banReasonService services.BanReasonServiceInterface
+}
+
+// NewBanReasonHandler creates a new ban reason handler
+func NewBanReasonHandler(banReasonService services.BanReasonServiceInterface) *BanReasonHandler {
+	return &BanReasonHandler{
+		banReasonService: banReasonService,
+	}
+}
+
+func toReasonCodeResponse(reason models.BanReason) dto.ReasonCodeResponse {
+	response := dto.ReasonCodeResponse{
+		Code:        reason.Code,
+		Label:       reason.Label,
+		PlayerText:  reason.PlayerText,
+		DefaultKind: reason.DefaultKind,
+	}
+
+	if reason.DefaultDurationSeconds > 0 {
+		response.DefaultDuration = services.FormatBanDuration(reason.DefaultDuration())
+	}
+
+	return response
+}
+
+func parseDefaultDuration(value string) (int64, error) {
+	if value == "" {
+		return 0, nil
+	}
+
+	duration, err := services.ParseBanDuration(value)
+	if err != nil {
+		return 0, err
+	}
+	return int64(duration / time.Second), nil
+}
+
+// CreateReason handles POST /ban-reasons
+func (h *BanReasonHandler) CreateReason(c *gin.Context) {
+	var req dto.CreateReasonCodeRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, dto.ErrorResponse{Error: err.Error()})
+		return
+	}
+
+	seconds, err := parseDefaultDuration(req.DefaultDuration)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, dto.ErrorResponse{Error: err.Error()})
+		return
+	}
+
+	reason := &models.BanReason{
+		Code:                   req.Code,
+		Label:                  req.Label,
+		PlayerText:             req.PlayerText,
+		DefaultKind:            req.DefaultKind,
+		DefaultDurationSeconds: seconds,
+	}
+
+	if err := h.banReasonService.CreateReason(reason); err != nil {
+		c.JSON(http.StatusBadRequest, dto.ErrorResponse{Error: err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusCreated, gin.H{"ban_reason": toReasonCodeResponse(*reason)})
+}
+
+// GetReasons handles GET /ban-reasons
+func (h *BanReasonHandler) GetReasons(c *gin.Context) {
+	reasons, err := h.banReasonService.GetAllReasons()
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, dto.ErrorResponse{Error: err.Error()})
+		return
+	}
+
+	response := []dto.ReasonCodeResponse{}
+	for _, reason := range reasons {
+		response = append(response, toReasonCodeResponse(reason))
+	}
+
+	c.JSON(http.StatusOK, gin.H{"ban_reasons": response})
+}
+
+// GetReason handles GET /ban-reasons/:code
+func (h *BanReasonHandler) GetReason(c *gin.Context) {
+	reason, err := h.banReasonService.GetReason(c.Param("code"))
+	if err != nil {
+		c.JSON(http.StatusNotFound, dto.ErrorResponse{Error: err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"ban_reason": toReasonCodeResponse(*reason)})
+}
+
+// UpdateReason handles PUT /ban-reasons/:code
+func (h *BanReasonHandler) UpdateReason(c *gin.Context) {
+	var req dto.UpdateReasonCodeRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, dto.ErrorResponse{Error: err.Error()})
+		return
+	}
+
+	seconds, err := parseDefaultDuration(req.DefaultDuration)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, dto.ErrorResponse{Error: err.Error()})
+		return
+	}
+
+	reason := &models.BanReason{
+		Code:                   c.Param("code"),
+		Label:                  req.Label,
+		PlayerText:             req.PlayerText,
+		DefaultKind:            req.DefaultKind,
+		DefaultDurationSeconds: seconds,
+	}
+
+	if err := h.banReasonService.UpdateReason(reason); err != nil {
+		c.JSON(http.StatusBadRequest, dto.ErrorResponse{Error: err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"ban_reason": toReasonCodeResponse(*reason)})
+}
+
+// DeleteReason handles DELETE /ban-reasons/:code
+func (h *BanReasonHandler) DeleteReason(c *gin.Context) {
+	if err := h.banReasonService.DeleteReason(c.Param("code")); err != nil {
+		c.JSON(http.StatusBadRequest, dto.ErrorResponse{Error: err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, dto.MessageResponse{Message: "Ban reason deleted successfully"})
+}
@@ -5,15 +5,33 @@ import (
 )
 
 type Handlers struct {
-	Role       *RoleHandler
-	Permission *PermissionHandler
-	UserBan    *UserBanHandler
+	Role            *RoleHandler
+	Permission      *PermissionHandler
+	UserBan         *UserBanHandler
+	UserRole        *UserRoleHandler
+	Authorization   *AuthorizationHandler
+	UserAttribute   *UserAttributeHandler
+	BanReason       *BanReasonHandler
+	BanAppeal       *BanAppealHandler
+	AuditLog        *AuditLogHandler
+	APIKey          *APIKeyHandler
+	PermissionToken *PermissionTokenHandler
+	Outbox          *OutboxHandler
 }
 
 func NewHandlers(services *services.Services) *Handlers {
 	return &Handlers{
-		Role:       NewRoleHandler(services.Role),
-		Permission: NewPermissionHandler(services.Permission),
-		UserBan:    NewUserBanHandler(services.UserBan),
+		Role:            NewRoleHandler(services.Role),
+		Permission:      NewPermissionHandler(services.Permission),
+		UserBan:         NewUserBanHandler(services.UserBan),
+		UserRole:        NewUserRoleHandler(services.UserRole),
+		Authorization:   NewAuthorizationHandler(services.Authorization),
+		UserAttribute:   NewUserAttributeHandler(services.UserAttribute),
+		BanReason:       NewBanReasonHandler(services.BanReason),
+		BanAppeal:       NewBanAppealHandler(services.BanAppeal),
+		AuditLog:        NewAuditLogHandler(services.AuditLog),
+		APIKey:          NewAPIKeyHandler(services.APIKey),
+		PermissionToken: NewPermissionTokenHandler(services.PermissionToken),
+		Outbox:          NewOutboxHandler(services.Outbox),
 	}
-}
\ No newline at end of file
+}
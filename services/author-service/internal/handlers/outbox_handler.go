@@ -0,0 +1,199 @@
+package handlers
+
+import (
+	"encoding/json"
+	"net/http"
+	"strconv"
+	"time"
+
+	"gin/internal/dto"
+	"gin/internal/models"
+	"gin/internal/repositories"
+	"gin/internal/services"
+
+	"github.com/gin-gonic/gin"
+)
+
+// defaultOutboxPageSize is the page size used when none is requested
+const defaultOutboxPageSize = 50
+
+// OutboxHandler handles outbox delivery status HTTP requests
+type OutboxHandler struct {
+	outboxService services.OutboxServiceInterface
+}
+
+// NewOutboxHandler creates a new outbox handler
+func NewOutboxHandler(outboxService services.OutboxServiceInterface) *OutboxHandler {
+	return &OutboxHandler{
+		outboxService: outboxService,
+	}
+}
+
+func toOutboxEventResponse(event models.OutboxEvent) dto.OutboxEventResponse {
+	response := dto.OutboxEventResponse{
+		ID:            event.ID,
+		Type:          event.Type,
+		AggregateType: event.AggregateType,
+		AggregateID:   event.AggregateID,
+		Payload:       json.RawMessage(event.Payload),
+		Actor:         event.Actor,
+		RequestID:     event.RequestID,
+		CreatedAt:     event.CreatedAt.Format(time.RFC3339),
+		Deliveries:    make([]dto.OutboxDeliveryResponse, 0, len(event.Deliveries)),
+	}
+
+	if event.FannedOutAt != nil {
+		response.FannedOutAt = event.FannedOutAt.Format(time.RFC3339)
+	}
+
+	for _, delivery := range event.Deliveries {
+		deliveryResponse := dto.OutboxDeliveryResponse{
+			ID:        delivery.ID,
+			Sink:      delivery.Sink,
+			Status:    delivery.Status,
+			Attempts:  delivery.Attempts,
+			LastError: delivery.LastError,
+		}
+		if delivery.Status == models.DeliveryStatusPending {
+			deliveryResponse.NextAttemptAt = delivery.NextAttemptAt.Format(time.RFC3339)
+		}
+		if delivery.DeliveredAt != nil {
+			deliveryResponse.DeliveredAt = delivery.DeliveredAt.Format(time.RFC3339)
+		}
+		response.Deliveries = append(response.Deliveries, deliveryResponse)
+	}
+
+	return response
+}
+
+func toOutboxDeadLetterResponse(deadLetter models.OutboxDeadLetter) dto.OutboxDeadLetterResponse {
+	response := dto.OutboxDeadLetterResponse{
+		ID:         deadLetter.ID,
+		DeliveryID: deadLetter.DeliveryID,
+		EventID:    deadLetter.EventID,
+		EventType:  deadLetter.Event.Type,
+		Sink:       deadLetter.Sink,
+		Attempts:   deadLetter.Attempts,
+		LastError:  deadLetter.LastError,
+		CreatedAt:  deadLetter.CreatedAt.Format(time.RFC3339),
+	}
+
+	if deadLetter.RetriedAt != nil {
+		response.RetriedAt = deadLetter.RetriedAt.Format(time.RFC3339)
+	}
+
+	return response
+}
+
+// pagination reads the page and page_size query parameters; pages start at 1
+func pagination(c *gin.Context, defaultPageSize int) (page, pageSize int, ok bool) {
+	page, err := strconv.Atoi(c.DefaultQuery("page", "1"))
+	if err != nil || page < 1 {
+		c.JSON(http.StatusBadRequest, dto.ErrorResponse{Error: "Invalid page"})
+		return 0, 0, false
+	}
+
+	pageSize, err = strconv.Atoi(c.DefaultQuery("page_size", strconv.Itoa(defaultPageSize)))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, dto.ErrorResponse{Error: "Invalid page size"})
+		return 0, 0, false
+	}
+
+	return page, pageSize, true
+}
+
+// GetEvents handles GET /outbox/events?type=&aggregate_type=&aggregate_id=&status=&page=&page_size=
+// status keeps the events with a delivery in that status: pending, delivered or dead
+func (h *OutboxHandler) GetEvents(c *gin.Context) {
+	page, pageSize, ok := pagination(c, defaultOutboxPageSize)
+	if !ok {
+		return
+	}
+
+	filter := repositories.OutboxEventFilter{
+		Type:           c.Query("type"),
+		AggregateType:  c.Query("aggregate_type"),
+		AggregateID:    c.Query("aggregate_id"),
+		DeliveryStatus: c.Query("status"),
+		Limit:          pageSize,
+		Offset:         (page - 1) * pageSize,
+	}
+
+	events, total, err := h.outboxService.GetEvents(filter)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, dto.ErrorResponse{Error: err.Error()})
+		return
+	}
+
+	response := dto.OutboxEventPageResponse{
+		Events:   make([]dto.OutboxEventResponse, 0, len(events)),
+		Page:     page,
+		PageSize: pageSize,
+		Total:    total,
+	}
+	for _, event := range events {
+		response.Events = append(response.Events, toOutboxEventResponse(event))
+	}
+
+	c.JSON(http.StatusOK, response)
+}
+
+// GetEvent handles GET /outbox/events/:id
+func (h *OutboxHandler) GetEvent(c *gin.Context) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, dto.ErrorResponse{Error: "Invalid event ID"})
+		return
+	}
+
+	event, err := h.outboxService.GetEvent(uint(id))
+	if err != nil {
+		c.JSON(http.StatusNotFound, dto.ErrorResponse{Error: err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, toOutboxEventResponse(*event))
+}
+
+// GetDeadLetters handles GET /outbox/dead-letters?include_retried=&page=&page_size=
+func (h *OutboxHandler) GetDeadLetters(c *gin.Context) {
+	page, pageSize, ok := pagination(c, defaultOutboxPageSize)
+	if !ok {
+		return
+	}
+
+	includeRetried := c.Query("include_retried") == "true"
+	deadLetters, total, err := h.outboxService.GetDeadLetters(includeRetried, pageSize, (page-1)*pageSize)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, dto.ErrorResponse{Error: err.Error()})
+		return
+	}
+
+	response := dto.OutboxDeadLetterPageResponse{
+		DeadLetters: make([]dto.OutboxDeadLetterResponse, 0, len(deadLetters)),
+		Page:        page,
+		PageSize:    pageSize,
+		Total:       total,
+	}
+	for _, deadLetter := range deadLetters {
+		response.DeadLetters = append(response.DeadLetters, toOutboxDeadLetterResponse(deadLetter))
+	}
+
+	c.JSON(http.StatusOK, response)
+}
+
+// RetryDeadLetter handles POST /outbox/dead-letters/:id/retry
+func (h *OutboxHandler) RetryDeadLetter(c *gin.Context) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, dto.ErrorResponse{Error: "Invalid dead letter ID"})
+		return
+	}
+
+	if err := h.outboxService.RetryDeadLetter(uint(id)); err != nil {
+		c.JSON(http.StatusNotFound, dto.ErrorResponse{Error: err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, dto.MessageResponse{Message: "Delivery queued for retry"})
+}
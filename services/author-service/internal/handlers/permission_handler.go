@@ -30,7 +30,7 @@ func (h *PermissionHandler) CreatePermission(c *gin.Context) {
 		return
 	}
 
-	permission, err := h.permissionService.CreatePermission(req.Name)
+	permission, err := h.permissionService.CreatePermission(c.Request.Context(), req.Name)
 	if err != nil {
 		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
 		return
@@ -90,7 +90,7 @@ func (h *PermissionHandler) UpdatePermission(c *gin.Context) {
 		Name:   req.Name,
 	}
 
-	if err := h.permissionService.UpdatePermission(permission); err != nil {
+	if err := h.permissionService.UpdatePermission(c.Request.Context(), permission); err != nil {
 		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
 		return
 	}
@@ -107,7 +107,7 @@ func (h *PermissionHandler) DeletePermission(c *gin.Context) {
 		return
 	}
 
-	if err := h.permissionService.DeletePermission(uint(id)); err != nil {
+	if err := h.permissionService.DeletePermission(c.Request.Context(), uint(id)); err != nil {
 		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
 		return
 	}
@@ -0,0 +1,55 @@
+package handlers
+
+import (
+	"net/http"
+	"time"
+
+	"gin/internal/dto"
+	"gin/internal/models"
+	"gin/internal/services"
+
+	"github.com/gin-gonic/gin"
+)
+
+// PermissionTokenHandler handles permission token HTTP requests
+type PermissionTokenHandler struct {
+	permissionTokenService services.PermissionTokenServiceInterface
+}
+
+// NewPermissionTokenHandler creates a new permission token handler
+func NewPermissionTokenHandler(permissionTokenService services.PermissionTokenServiceInterface) *PermissionTokenHandler {
+	return &PermissionTokenHandler{
+		permissionTokenService: permissionTokenService,
+	}
+}
+
+// IssueToken handles POST /permission-tokens
+func (h *PermissionTokenHandler) IssueToken(c *gin.Context) {
+	var req dto.IssuePermissionTokenRequest
+
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, dto.ErrorResponse{Error: err.Error()})
+		return
+	}
+
+	issued, err := h.permissionTokenService.IssueToken(req.UserID, models.ResourceScope{
+		ResourceType: req.ResourceType,
+		ResourceID:   req.ResourceID,
+	})
+	if err != nil {
+		c.JSON(http.StatusBadRequest, dto.ErrorResponse{Error: err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusCreated, dto.PermissionTokenResponse{
+		Token:     issued.Token,
+		TokenType: "Bearer",
+		ExpiresAt: issued.ExpiresAt.Format(time.RFC3339),
+	})
+}
+
+// GetJWKS handles GET /.well-known/jwks.json
+func (h *PermissionTokenHandler) GetJWKS(c *gin.Context) {
+	c.Header("Cache-Control", "public, max-age=300")
+	c.JSON(http.StatusOK, gin.H{"keys": h.permissionTokenService.PublicKeys()})
+}
@@ -32,7 +32,7 @@ func (h *RoleHandler) CreateRole(c *gin.Context) {
 		return
 	}
 
-	role, err := h.roleService.CreateRole(req.Name)
+	role, err := h.roleService.CreateRole(c.Request.Context(), req.Name)
 	if err != nil {
 		c.JSON(http.StatusBadRequest, dto.ErrorResponse{Error: err.Error()})
 		return
@@ -108,7 +108,7 @@ func (h *RoleHandler) UpdateRole(c *gin.Context) {
 		Name:   req.Name,
 	}
 
-	if err := h.roleService.UpdateRole(role); err != nil {
+	if err := h.roleService.UpdateRole(c.Request.Context(), role); err != nil {
 		c.JSON(http.StatusBadRequest, dto.ErrorResponse{Error: err.Error()})
 		return
 	}
@@ -125,7 +125,7 @@ func (h *RoleHandler) DeleteRole(c *gin.Context) {
 		return
 	}
 
-	if err := h.roleService.DeleteRole(uint(id)); err != nil {
+	if err := h.roleService.DeleteRole(c.Request.Context(), uint(id)); err != nil {
 		c.JSON(http.StatusNotFound, dto.ErrorResponse{Error: err.Error()})
 		return
 	}
@@ -148,18 +148,45 @@ func (h *RoleHandler) GetRoleWithPermissions(c *gin.Context) {
 		return
 	}
 
-	var permissions []dto.PermissionResponse
-	for _, perm := range role.Permissions {
-		permissions = append(permissions, dto.PermissionResponse{
-			PermID: perm.PermID,
-			Name:   perm.Name,
+	inherited, err := h.roleService.GetInheritedPermissions(role.RoleID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, dto.ErrorResponse{Error: err.Error()})
+		return
+	}
+
+	var permissions []dto.RoleGrantResponse
+	for _, grant := range role.Grants {
+		permissions = append(permissions, dto.RoleGrantResponse{
+			PermID:       grant.PermID,
+			Name:         grant.Permission.Name,
+			ResourceType: grant.ResourceType,
+			ResourceID:   grant.ResourceID,
+			Condition:    grant.Condition,
+			Effect:       grant.Effect,
+		})
+	}
+
+	inheritedPermissions := []dto.InheritedPermissionResponse{}
+	for _, perm := range inherited {
+		inheritedPermissions = append(inheritedPermissions, dto.InheritedPermissionResponse{
+			PermID:       perm.PermID,
+			Name:         perm.PermName,
+			ResourceType: perm.ResourceType,
+			ResourceID:   perm.ResourceID,
+			Condition:    perm.Condition,
+			Effect:       perm.Effect,
+			InheritedFrom: dto.RoleResponse{
+				RoleID: perm.SourceRoleID,
+				Name:   perm.SourceRoleName,
+			},
 		})
 	}
 
 	response := dto.RoleWithPermissionsResponse{
-		RoleID:      role.RoleID,
-		Name:        role.Name,
-		Permissions: permissions,
+		RoleID:               role.RoleID,
+		Name:                 role.Name,
+		Permissions:          permissions,
+		InheritedPermissions: inheritedPermissions,
 	}
 
 	c.JSON(http.StatusOK, gin.H{"role": response})
@@ -180,7 +207,16 @@ func (h *RoleHandler) AddPermissionToRole(c *gin.Context) {
 		return
 	}
 
-	if err := h.roleService.AddPermissionToRole(uint(roleID), req.PermissionID); err != nil {
+	grant := &models.RolePermission{
+		RoleID:       uint(roleID),
+		PermID:       req.PermissionID,
+		ResourceType: req.ResourceType,
+		ResourceID:   req.ResourceID,
+		Condition:    req.Condition,
+		Effect:       req.Effect,
+	}
+
+	if err := h.roleService.AddPermissionToRole(c.Request.Context(), grant); err != nil {
 		c.JSON(http.StatusBadRequest, dto.ErrorResponse{Error: err.Error()})
 		return
 	}
@@ -188,7 +224,7 @@ func (h *RoleHandler) AddPermissionToRole(c *gin.Context) {
 	c.JSON(http.StatusOK, dto.MessageResponse{Message: "Permission added to role successfully"})
 }
 
-// RemovePermissionFromRole handles DELETE /roles/:id/permissions/:permission_id
+// RemovePermissionFromRole handles DELETE /roles/:id/permissions/:permission_id?resource_type=&resource_id=
 func (h *RoleHandler) RemovePermissionFromRole(c *gin.Context) {
 	idStr := c.Param("id")
 	roleID, err := strconv.ParseUint(idStr, 10, 32)
@@ -204,10 +240,88 @@ func (h *RoleHandler) RemovePermissionFromRole(c *gin.Context) {
 		return
 	}
 
-	if err := h.roleService.RemovePermissionFromRole(uint(roleID), uint(permissionID)); err != nil {
+	scope := models.ResourceScope{
+		ResourceType: c.Query("resource_type"),
+		ResourceID:   c.Query("resource_id"),
+	}
+
+	if err := h.roleService.RemovePermissionFromRole(c.Request.Context(), uint(roleID), uint(permissionID), scope); err != nil {
 		c.JSON(http.StatusBadRequest, dto.ErrorResponse{Error: err.Error()})
 		return
 	}
 
 	c.JSON(http.StatusOK, dto.MessageResponse{Message: "Permission removed from role successfully"})
-}
\ No newline at end of file
+}
+
+// GetParentRoles handles GET /roles/:id/parents
+func (h *RoleHandler) GetParentRoles(c *gin.Context) {
+	idStr := c.Param("id")
+	roleID, err := strconv.ParseUint(idStr, 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, dto.ErrorResponse{Error: "Invalid role ID"})
+		return
+	}
+
+	parents, err := h.roleService.GetParentRoles(uint(roleID))
+	if err != nil {
+		c.JSON(http.StatusNotFound, dto.ErrorResponse{Error: err.Error()})
+		return
+	}
+
+	response := []dto.RoleResponse{}
+	for _, parent := range parents {
+		response = append(response, dto.RoleResponse{
+			RoleID: parent.RoleID,
+			Name:   parent.Name,
+		})
+	}
+
+	c.JSON(http.StatusOK, gin.H{"parents": response})
+}
+
+// AddParentRole handles POST /roles/:id/parents
+func (h *RoleHandler) AddParentRole(c *gin.Context) {
+	idStr := c.Param("id")
+	roleID, err := strconv.ParseUint(idStr, 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, dto.ErrorResponse{Error: "Invalid role ID"})
+		return
+	}
+
+	var req dto.AddParentRoleRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, dto.ErrorResponse{Error: err.Error()})
+		return
+	}
+
+	if err := h.roleService.AddParentRole(c.Request.Context(), uint(roleID), req.ParentID); err != nil {
+		c.JSON(http.StatusBadRequest, dto.ErrorResponse{Error: err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, dto.MessageResponse{Message: "Parent role added successfully"})
+}
+
+// RemoveParentRole handles DELETE /roles/:id/parents/:parent_id
+func (h *RoleHandler) RemoveParentRole(c *gin.Context) {
+	idStr := c.Param("id")
+	roleID, err := strconv.ParseUint(idStr, 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, dto.ErrorResponse{Error: "Invalid role ID"})
+		return
+	}
+
+	parentIDStr := c.Param("parent_id")
+	parentID, err := strconv.ParseUint(parentIDStr, 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, dto.ErrorResponse{Error: "Invalid parent role ID"})
+		return
+	}
+
+	if err := h.roleService.RemoveParentRole(c.Request.Context(), uint(roleID), uint(parentID)); err != nil {
+		c.JSON(http.StatusNotFound, dto.ErrorResponse{Error: err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, dto.MessageResponse{Message: "Parent role removed successfully"})
+}
@@ -0,0 +1,66 @@
+package handlers
+
+import (
+	"net/http"
+
+	"gin/internal/dto"
+	"gin/internal/services"
+
+	"github.com/gin-gonic/gin"
+)
+
+// UserAttributeHandler handles stored user attribute HTTP requests
+type UserAttributeHandler struct {
+	userAttributeService services.UserAttributeServiceInterface
+}
+
+// NewUserAttributeHandler creates a new user attribute handler
+func NewUserAttributeHandler(userAttributeService services.UserAttributeServiceInterface) *UserAttributeHandler {
+	return &UserAttributeHandler{
+		userAttributeService: userAttributeService,
+	}
+}
+
+// GetAttributes handles GET /users/:user_id/attributes
+func (h *UserAttributeHandler) GetAttributes(c *gin.Context) {
+	userID := c.Param("user_id")
+
+	attributes, err := h.userAttributeService.GetAttributes(userID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, dto.ErrorResponse{Error: err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, dto.UserAttributesResponse{UserID: userID, Attributes: attributes})
+}
+
+// SetAttributes handles PUT /users/:user_id/attributes
+func (h *UserAttributeHandler) SetAttributes(c *gin.Context) {
+	userID := c.Param("user_id")
+
+	var req dto.SetUserAttributesRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, dto.ErrorResponse{Error: err.Error()})
+		return
+	}
+
+	if err := h.userAttributeService.SetAttributes(userID, req.Attributes); err != nil {
+		c.JSON(http.StatusBadRequest, dto.ErrorResponse{Error: err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, dto.MessageResponse{Message: "User attributes updated successfully"})
+}
+
+// DeleteAttribute handles DELETE /users/:user_id/attributes/:key
+func (h *UserAttributeHandler) DeleteAttribute(c *gin.Context) {
+	userID := c.Param("user_id")
+	key := c.Param("key")
+
+	if err := h.userAttributeService.DeleteAttribute(userID, key); err != nil {
+		c.JSON(http.StatusNotFound, dto.ErrorResponse{Error: err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, dto.MessageResponse{Message: "User attribute deleted successfully"})
+}
@@ -6,6 +6,9 @@ import (
 	"time"
 
 	"gin/internal/dto"
+	"gin/internal/models"
+	"gin/internal/repositories"
+	"gin/internal/requestctx"
 	"gin/internal/services"
 
 	"github.com/gin-gonic/gin"
@@ -23,10 +26,67 @@ func NewUserBanHandler(userBanService services.UserBanServiceInterface) *UserBan
 	}
 }
 
+func toUserBanResponse(userBan models.UserBan) dto.UserBanResponse {
+	response := dto.UserBanResponse{
+		ID:           userBan.ID,
+		UserID:       userBan.UserID,
+		PermID:       userBan.PermID,
+		BanType:      userBan.Type(),
+		Kind:         userBan.Kind,
+		Notes:        userBan.Notes,
+		IssuedBy:     userBan.IssuedBy,
+		LiftedBy:     userBan.LiftedBy,
+		LiftReason:   userBan.LiftReason,
+		StrikePoints: userBan.StrikePoints,
+		Escalation:   userBan.EscalationRule,
+		Reason:       userBan.Reason,
+		ResourceType: userBan.ResourceType,
+		ResourceID:   userBan.ResourceID,
+		CreatedAt:    userBan.CreatedAt.Format(time.RFC3339),
+		UpdatedAt:    userBan.UpdatedAt.Format(time.RFC3339),
+	}
+
+	if userBan.ReasonCode != nil {
+		response.ReasonCode = *userBan.ReasonCode
+	}
+
+	if userBan.BanReason != nil {
+		response.PlayerText = userBan.BanReason.PlayerText
+	}
+
+	if userBan.ExpiresAt != nil {
+		response.ExpiresAt = userBan.ExpiresAt.Format(time.RFC3339)
+	}
+
+	if userBan.ExpiredAt != nil {
+		response.ExpiredAt = userBan.ExpiredAt.Format(time.RFC3339)
+	}
+
+	if userBan.LiftedAt != nil {
+		response.LiftedAt = userBan.LiftedAt.Format(time.RFC3339)
+	}
+
+	if userBan.Permission.PermID != 0 {
+		response.Permission = &dto.PermissionResponse{
+			PermID: userBan.Permission.PermID,
+			Name:   userBan.Permission.Name,
+		}
+	}
+
+	for _, exemption := range userBan.Exemptions {
+		response.Exemptions = append(response.Exemptions, dto.PermissionResponse{
+			PermID: exemption.PermID,
+			Name:   exemption.Name,
+		})
+	}
+
+	return response
+}
+
 // BanUser handles POST /users/:user_id/bans
 func (h *UserBanHandler) BanUser(c *gin.Context) {
 	userID := c.Param("user_id")
-	
+
 	var req dto.BanUserRequest
 
 	if err := c.ShouldBindJSON(&req); err != nil {
@@ -34,36 +94,83 @@ func (h *UserBanHandler) BanUser(c *gin.Context) {
 		return
 	}
 
-	userBan, err := h.userBanService.BanUser(userID, req.PermissionID, req.Reason)
+	opts := services.BanOptions{
+		Scope: models.ResourceScope{
+			ResourceType: req.ResourceType,
+			ResourceID:   req.ResourceID,
+		},
+		Global:       req.Global,
+		Exemptions:   req.ExemptPermissionIDs,
+		Kind:         req.Kind,
+		StrikePoints: req.StrikePoints,
+		ReasonCode:   req.ReasonCode,
+		Notes:        req.Notes,
+	}
+
+	if req.Duration != "" {
+		duration, err := services.ParseBanDuration(req.Duration)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, dto.ErrorResponse{Error: err.Error()})
+			return
+		}
+		opts.Duration = duration
+	}
+
+	result, err := h.userBanService.BanUser(c.Request.Context(), userID, req.PermissionID, req.Reason, opts)
 	if err != nil {
 		c.JSON(http.StatusBadRequest, dto.ErrorResponse{Error: err.Error()})
 		return
 	}
 
-	response := dto.UserBanResponse{
-		ID:         userBan.ID,
-		UserID:     userBan.UserID,
-		PermID:     userBan.PermID,
-		Reason:     userBan.Reason,
-		CreatedAt:  userBan.CreatedAt.Format(time.RFC3339), // Much cleaner! 😊
-		UpdatedAt:  userBan.UpdatedAt.Format(time.RFC3339),
+	response := dto.BanUserResponse{
+		UserBan:     toUserBanResponse(*result.Ban),
+		StrikeTotal: result.StrikeTotal,
+	}
+
+	if rule := result.Escalation; rule != nil {
+		response.Escalation = &dto.EscalationRuleResponse{
+			Name:      rule.Name,
+			Threshold: rule.Threshold,
+			Kind:      rule.Kind,
+			Permanent: rule.Permanent,
+			Global:    rule.Global,
+		}
+		if rule.Duration > 0 {
+			response.Escalation.Duration = rule.Duration.String()
+		}
 	}
 
-	c.JSON(http.StatusCreated, gin.H{"user_ban": response})
+	c.JSON(http.StatusCreated, response)
 }
 
-// UnbanUser handles DELETE /users/:user_id/bans/:permission_id
+// UnbanUser handles DELETE /users/:user_id/bans/:permission_id?resource_type=&resource_id=&kind=&lift_reason=
+// The permission ID "global" lifts the user's global ban. The ban is kept as history, lifted by the caller.
 func (h *UserBanHandler) UnbanUser(c *gin.Context) {
 	userID := c.Param("user_id")
 	permissionIDStr := c.Param("permission_id")
-	
-	permissionID, err := strconv.ParseUint(permissionIDStr, 10, 32)
-	if err != nil {
-		c.JSON(http.StatusBadRequest, dto.ErrorResponse{Error: "Invalid permission ID"})
-		return
+
+	opts := services.UnbanOptions{
+		Scope: models.ResourceScope{
+			ResourceType: c.Query("resource_type"),
+			ResourceID:   c.Query("resource_id"),
+		},
+		Kind:       c.Query("kind"),
+		LiftReason: c.Query("lift_reason"),
+	}
+
+	var err error
+	if permissionIDStr == models.BanTypeGlobal {
+		err = h.userBanService.UnbanUserGlobally(c.Request.Context(), userID, opts)
+	} else {
+		permissionID, parseErr := strconv.ParseUint(permissionIDStr, 10, 32)
+		if parseErr != nil {
+			c.JSON(http.StatusBadRequest, dto.ErrorResponse{Error: "Invalid permission ID"})
+			return
+		}
+		err = h.userBanService.UnbanUser(c.Request.Context(), userID, uint(permissionID), opts)
 	}
 
-	if err := h.userBanService.UnbanUser(userID, uint(permissionID)); err != nil {
+	if err != nil {
 		c.JSON(http.StatusNotFound, dto.ErrorResponse{Error: err.Error()})
 		return
 	}
@@ -89,35 +196,52 @@ func (h *UserBanHandler) GetUserBan(c *gin.Context) {
 	c.JSON(http.StatusOK, gin.H{"user_ban": userBan})
 }
 
-// GetUserBans handles GET /users/:user_id/bans
+// GetUserBans handles GET /users/:user_id/bans?include=history
+// Only active bans are listed unless the lifted and expired ones are asked for.
 func (h *UserBanHandler) GetUserBans(c *gin.Context) {
 	userID := c.Param("user_id")
+	includeHistory := c.Query("include") == "history"
 
-	userBans, err := h.userBanService.GetUserBans(userID)
+	// Users looking at their own bans are not told about shadow bans
+	hideShadowBans := requestctx.SelfOnly(c.Request.Context())
+	userBans, err := h.userBanService.GetUserBans(userID, includeHistory, hideShadowBans)
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
 		return
 	}
 
-	c.JSON(http.StatusOK, gin.H{"user_bans": userBans})
+	response := []dto.UserBanResponse{}
+	for _, userBan := range userBans {
+		response = append(response, toUserBanResponse(userBan))
+	}
+
+	c.JSON(http.StatusOK, gin.H{"user_bans": response})
 }
 
-// GetAllUserBans handles GET /bans
+// GetAllUserBans handles GET /bans?type=global|permission&reason_code=
 func (h *UserBanHandler) GetAllUserBans(c *gin.Context) {
-	userBans, err := h.userBanService.GetAllUserBans()
+	userBans, err := h.userBanService.GetAllUserBans(repositories.UserBanFilter{
+		Type:       c.Query("type"),
+		ReasonCode: c.Query("reason_code"),
+	})
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
 		return
 	}
 
-	c.JSON(http.StatusOK, gin.H{"user_bans": userBans})
+	response := []dto.UserBanResponse{}
+	for _, userBan := range userBans {
+		response = append(response, toUserBanResponse(userBan))
+	}
+
+	c.JSON(http.StatusOK, gin.H{"user_bans": response})
 }
 
 // CheckUserBan handles GET /users/:user_id/bans/check
 func (h *UserBanHandler) CheckUserBan(c *gin.Context) {
 	userID := c.Param("user_id")
 	permissionIDStr := c.Query("permission_id")
-	
+
 	if permissionIDStr == "" {
 		c.JSON(http.StatusBadRequest, dto.ErrorResponse{Error: "permission_id query parameter is required"})
 		return
@@ -129,7 +253,12 @@ func (h *UserBanHandler) CheckUserBan(c *gin.Context) {
 		return
 	}
 
-	isBanned, err := h.userBanService.IsUserBanned(userID, uint(permissionID))
+	scope := models.ResourceScope{
+		ResourceType: c.Query("resource_type"),
+		ResourceID:   c.Query("resource_id"),
+	}
+
+	ban, err := h.userBanService.CheckUserBan(userID, uint(permissionID), scope, requestctx.SelfOnly(c.Request.Context()))
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, dto.ErrorResponse{Error: err.Error()})
 		return
@@ -138,7 +267,15 @@ func (h *UserBanHandler) CheckUserBan(c *gin.Context) {
 	response := dto.CheckUserBanResponse{
 		UserID:       userID,
 		PermissionID: uint(permissionID),
-		IsBanned:     isBanned,
+		ResourceType: scope.ResourceType,
+		ResourceID:   scope.ResourceID,
+	}
+
+	if ban != nil {
+		banResponse := toUserBanResponse(*ban)
+		response.IsBanned = ban.Blocks()
+		response.Kind = ban.Kind
+		response.Ban = &banResponse
 	}
 
 	c.JSON(http.StatusOK, response)
@@ -162,10 +299,10 @@ func (h *UserBanHandler) UpdateBanReason(c *gin.Context) {
 		return
 	}
 
-	if err := h.userBanService.UpdateBanReason(uint(id), req.Reason); err != nil {
+	if err := h.userBanService.UpdateBanReason(c.Request.Context(), uint(id), req.Reason); err != nil {
 		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
 		return
 	}
 
 	c.JSON(http.StatusOK, gin.H{"message": "Ban reason updated successfully"})
-}
\ No newline at end of file
+}
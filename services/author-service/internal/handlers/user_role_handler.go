@@ -0,0 +1,115 @@
+package handlers
+
+import (
+	"net/http"
+	"strconv"
+	"time"
+
+	"gin/internal/dto"
+	"gin/internal/models"
+	"gin/internal/services"
+
+	"github.com/gin-gonic/gin"
+)
+
+// UserRoleHandler handles user role membership HTTP requests
+type UserRoleHandler struct {
+	userRoleService services.UserRoleServiceInterface
+}
+
+// NewUserRoleHandler creates a new user role handler
+func NewUserRoleHandler(userRoleService services.UserRoleServiceInterface) *UserRoleHandler {
+	return &UserRoleHandler{
+		userRoleService: userRoleService,
+	}
+}
+
+func toUserRoleResponse(userRole models.UserRole) dto.UserRoleResponse {
+	return dto.UserRoleResponse{
+		UserID: userRole.UserID,
+		Role: dto.RoleResponse{
+			RoleID: userRole.Role.RoleID,
+			Name:   userRole.Role.Name,
+		},
+		CreatedAt: userRole.CreatedAt.Format(time.RFC3339),
+	}
+}
+
+// AssignRole handles POST /users/:user_id/roles
+func (h *UserRoleHandler) AssignRole(c *gin.Context) {
+	userID := c.Param("user_id")
+
+	var req dto.AssignRoleRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, dto.ErrorResponse{Error: err.Error()})
+		return
+	}
+
+	userRole, err := h.userRoleService.AssignRole(c.Request.Context(), userID, req.RoleID)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, dto.ErrorResponse{Error: err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusCreated, gin.H{"user_role": toUserRoleResponse(*userRole)})
+}
+
+// GetUserRoles handles GET /users/:user_id/roles
+func (h *UserRoleHandler) GetUserRoles(c *gin.Context) {
+	userID := c.Param("user_id")
+
+	userRoles, err := h.userRoleService.GetUserRoles(userID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, dto.ErrorResponse{Error: err.Error()})
+		return
+	}
+
+	response := []dto.UserRoleResponse{}
+	for _, userRole := range userRoles {
+		response = append(response, toUserRoleResponse(userRole))
+	}
+
+	c.JSON(http.StatusOK, gin.H{"user_roles": response})
+}
+
+// RemoveRole handles DELETE /users/:user_id/roles/:role_id
+func (h *UserRoleHandler) RemoveRole(c *gin.Context) {
+	userID := c.Param("user_id")
+
+	roleIDStr := c.Param("role_id")
+	roleID, err := strconv.ParseUint(roleIDStr, 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, dto.ErrorResponse{Error: "Invalid role ID"})
+		return
+	}
+
+	if err := h.userRoleService.RemoveRole(c.Request.Context(), userID, uint(roleID)); err != nil {
+		c.JSON(http.StatusNotFound, dto.ErrorResponse{Error: err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, dto.MessageResponse{Message: "Role removed from user successfully"})
+}
+
+// GetRoleUsers handles GET /roles/:id/users
+func (h *UserRoleHandler) GetRoleUsers(c *gin.Context) {
+	idStr := c.Param("id")
+	roleID, err := strconv.ParseUint(idStr, 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, dto.ErrorResponse{Error: "Invalid role ID"})
+		return
+	}
+
+	userRoles, err := h.userRoleService.GetRoleUsers(uint(roleID))
+	if err != nil {
+		c.JSON(http.StatusNotFound, dto.ErrorResponse{Error: err.Error()})
+		return
+	}
+
+	response := []dto.UserRoleResponse{}
+	for _, userRole := range userRoles {
+		response = append(response, toUserRoleResponse(userRole))
+	}
+
+	c.JSON(http.StatusOK, gin.H{"user_roles": response})
+}
@@ -0,0 +1,65 @@
+package middleware
+
+import (
+	"net/http"
+	"strconv"
+	"strings"
+
+	"gin/internal/auth"
+	"gin/internal/dto"
+	"gin/internal/requestctx"
+	"gin/internal/services"
+
+	"github.com/gin-gonic/gin"
+)
+
+// APIKeyHeader carries the API key of a service caller
+const APIKeyHeader = "X-API-Key"
+
+// APIKeySubjectPrefix starts the subject of callers authenticated by an API key
+const APIKeySubjectPrefix = "api_key:"
+
+// Authenticate requires a valid bearer JWT or API key. The token's subject, or api_key:<id> for
+// an API key, becomes the actor of the request and is stored in the request context together
+// with the token's claims or the key's scopes.
+func Authenticate(verifier *auth.Verifier, apiKeys services.APIKeyServiceInterface) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if secret := c.GetHeader(APIKeyHeader); secret != "" {
+			key, err := apiKeys.Authenticate(secret)
+			if err != nil {
+				unauthorized(c, "Invalid API key")
+				return
+			}
+
+			subject := APIKeySubjectPrefix + strconv.FormatUint(uint64(key.ID), 10)
+			ctx := requestctx.WithSubject(c.Request.Context(), subject, map[string]interface{}{"api_key": key.Name})
+			ctx = requestctx.WithAPIKeyScopes(ctx, key.ScopeList())
+			ctx = requestctx.WithActor(ctx, subject)
+			c.Request = c.Request.WithContext(ctx)
+			c.Next()
+			return
+		}
+
+		token, found := strings.CutPrefix(c.GetHeader("Authorization"), "Bearer ")
+		if !found || token == "" {
+			unauthorized(c, "Missing bearer token or API key")
+			return
+		}
+
+		principal, err := verifier.Verify(strings.TrimSpace(token))
+		if err != nil {
+			unauthorized(c, "Invalid bearer token")
+			return
+		}
+
+		ctx := requestctx.WithSubject(c.Request.Context(), principal.Subject, principal.Claims)
+		ctx = requestctx.WithActor(ctx, principal.Subject)
+		c.Request = c.Request.WithContext(ctx)
+		c.Next()
+	}
+}
+
+func unauthorized(c *gin.Context, message string) {
+	c.Header("WWW-Authenticate", `Bearer realm="api"`)
+	c.AbortWithStatusJSON(http.StatusUnauthorized, dto.ErrorResponse{Error: message})
+}
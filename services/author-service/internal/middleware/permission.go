@@ -0,0 +1,117 @@
+package middleware
+
+import (
+	"fmt"
+	"net/http"
+
+	"gin/internal/dto"
+	"gin/internal/models"
+	"gin/internal/requestctx"
+	"gin/internal/services"
+
+	"github.com/gin-gonic/gin"
+)
+
+// Authorizer gates routes on the permissions the authenticated caller holds through their roles,
+// or on the scopes of the API key they authenticated with. It must run after Authenticate.
+type Authorizer struct {
+	authorizationService services.AuthorizationServiceInterface
+}
+
+// NewAuthorizer creates an authorizer that decides with the service's own authorization rules
+func NewAuthorizer(authorizationService services.AuthorizationServiceInterface) *Authorizer {
+	return &Authorizer{
+		authorizationService: authorizationService,
+	}
+}
+
+// Require rejects callers who do not hold the permission with 403
+func (a *Authorizer) Require(permission string) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if a.allowed(c, permission) {
+			c.Next()
+		}
+	}
+}
+
+// RequireSelf rejects callers who are not the user named by the path parameter or who do not
+// hold the permission
+func (a *Authorizer) RequireSelf(param, permission string) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if requestctx.Subject(c.Request.Context()) != c.Param(param) {
+			c.AbortWithStatusJSON(http.StatusForbidden, dto.ErrorResponse{Error: "Callers can only act for themselves here"})
+			return
+		}
+		if a.allowed(c, permission) {
+			c.Next()
+		}
+	}
+}
+
+// RequireSelfOr lets the user named by the path parameter through and otherwise requires the
+// permission. A user let through without the permission is marked with requestctx.WithSelfOnly.
+func (a *Authorizer) RequireSelfOr(param, permission string) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if subject := requestctx.Subject(c.Request.Context()); subject != "" && subject == c.Param(param) {
+			held, _, err := a.holds(c, subject, permission)
+			if err != nil {
+				c.AbortWithStatusJSON(http.StatusInternalServerError, dto.ErrorResponse{Error: err.Error()})
+				return
+			}
+			if !held {
+				c.Request = c.Request.WithContext(requestctx.WithSelfOnly(c.Request.Context()))
+			}
+			c.Next()
+			return
+		}
+		if a.allowed(c, permission) {
+			c.Next()
+		}
+	}
+}
+
+// allowed reports whether the caller holds the permission, aborting the request if not
+func (a *Authorizer) allowed(c *gin.Context, permission string) bool {
+	subject := requestctx.Subject(c.Request.Context())
+	if subject == "" {
+		unauthorized(c, "Missing bearer token or API key")
+		return false
+	}
+
+	held, reason, err := a.holds(c, subject, permission)
+	if err != nil {
+		c.AbortWithStatusJSON(http.StatusInternalServerError, dto.ErrorResponse{Error: err.Error()})
+		return false
+	}
+
+	if !held {
+		c.AbortWithStatusJSON(http.StatusForbidden, dto.ErrorResponse{Error: reason})
+		return false
+	}
+
+	return true
+}
+
+// holds reports whether the authenticated subject holds the permission, and if not, why
+func (a *Authorizer) holds(c *gin.Context, subject, permission string) (bool, string, error) {
+	if scopes, ok := requestctx.APIKeyScopes(c.Request.Context()); ok {
+		if !models.ScopesAllow(scopes, permission) {
+			return false, fmt.Sprintf("Permission '%s' is outside the API key's scopes", permission), nil
+		}
+		return true, "", nil
+	}
+
+	decision, err := a.authorizationService.Authorize(services.AuthorizationQuery{
+		UserID:         subject,
+		PermissionName: permission,
+	})
+	if err != nil {
+		return false, "", err
+	}
+
+	if !decision.Allowed {
+		return false, fmt.Sprintf("Permission '%s' required (%s)", permission, decision.Reason), nil
+	}
+
+	return true, "", nil
+}
@@ -0,0 +1,51 @@
+package models
+
+import (
+	"strings"
+	"time"
+)
+
+// APIKeyPrefix starts every API key, so that leaked keys are easy to recognise
+const APIKeyPrefix = "ak_"
+
+// APIKey authenticates a service rather than a person. Only the SHA-256 of the key is stored;
+// the key itself is shown once when it is created. Scopes are space-separated permission names,
+// possibly wildcards, limiting the operations the key may perform.
+type APIKey struct {
+	ID          uint       `gorm:"primaryKey;autoIncrement" json:"id"`
+	Name        string     `gorm:"size:100;not null" json:"name"`
+	Prefix      string     `gorm:"size:16;not null" json:"prefix"`
+	KeyHash     string     `gorm:"size:64;not null;uniqueIndex" json:"-"`
+	Scopes      string     `gorm:"not null" json:"scopes"`
+	CreatedBy   string     `gorm:"size:100" json:"created_by"`
+	RotatedFrom *uint      `json:"rotated_from,omitempty"`
+	ExpiresAt   *time.Time `json:"expires_at,omitempty"`
+	LastUsedAt  *time.Time `json:"last_used_at,omitempty"`
+	RevokedAt   *time.Time `gorm:"index" json:"revoked_at,omitempty"`
+	CreatedAt   time.Time  `json:"created_at"`
+}
+
+// ScopeList returns the permissions the key may use
+func (k APIKey) ScopeList() []string {
+	return strings.Fields(k.Scopes)
+}
+
+// Allows reports whether one of the key's scopes covers the permission
+func (k APIKey) Allows(permission string) bool {
+	return ScopesAllow(k.ScopeList(), permission)
+}
+
+// ScopesAllow reports whether one of the scopes covers the permission
+func ScopesAllow(scopes []string, permission string) bool {
+	for _, scope := range scopes {
+		if PermissionMatches(scope, permission) {
+			return true
+		}
+	}
+	return false
+}
+
+// IsActive reports whether the key is neither revoked nor expired at the given time
+func (k APIKey) IsActive(now time.Time) bool {
+	return k.RevokedAt == nil && (k.ExpiresAt == nil || k.ExpiresAt.After(now))
+}
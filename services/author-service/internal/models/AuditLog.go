@@ -0,0 +1,94 @@
+package models
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"strconv"
+	"time"
+)
+
+// Audited actions; each names the target type and what was done to it
+const (
+	AuditActionRoleCreate       = "role.create"
+	AuditActionRoleUpdate       = "role.update"
+	AuditActionRoleDelete       = "role.delete"
+	AuditActionRoleGrant        = "role.grant"
+	AuditActionRoleRevoke       = "role.revoke"
+	AuditActionRoleAddParent    = "role.add_parent"
+	AuditActionRoleRemoveParent = "role.remove_parent"
+	AuditActionPermissionCreate = "permission.create"
+	AuditActionPermissionUpdate = "permission.update"
+	AuditActionPermissionDelete = "permission.delete"
+	AuditActionBanCreate        = "ban.create"
+	AuditActionBanLift          = "ban.lift"
+	AuditActionBanUpdateReason  = "ban.update_reason"
+	AuditActionBanExpire        = "ban.expire"
+	AuditActionAppealSubmit     = "appeal.submit"
+	AuditActionAppealAccept     = "appeal.accept"
+	AuditActionAppealReject     = "appeal.reject"
+	AuditActionAPIKeyCreate     = "api_key.create"
+	AuditActionAPIKeyRevoke     = "api_key.revoke"
+)
+
+// Audited target types
+const (
+	AuditTargetRole       = "role"
+	AuditTargetPermission = "permission"
+	AuditTargetUserBan    = "user_ban"
+	AuditTargetBanAppeal  = "ban_appeal"
+	AuditTargetAPIKey     = "api_key"
+)
+
+// AuditLog is an append-only record of an administrative change: who made it, what it did to
+// which target, the target's state before and after as JSON, and the request it came from.
+// Each entry is chained to the previous one by hash, so editing or removing an entry breaks
+// every later link. Before and After are stored as json rather than jsonb to keep the exact text
+// that was hashed.
+type AuditLog struct {
+	ID         uint      `gorm:"primaryKey;autoIncrement" json:"id"`
+	Actor      string    `gorm:"not null;index" json:"actor"`
+	Action     string    `gorm:"size:50;not null;index" json:"action"`
+	TargetType string    `gorm:"size:50;not null;index:idx_audit_logs_target" json:"target_type"`
+	TargetID   string    `gorm:"size:100;not null;default:'';index:idx_audit_logs_target" json:"target_id"`
+	Before     *string   `gorm:"type:json" json:"before"`
+	After      *string   `gorm:"type:json" json:"after"`
+	RequestID  string    `gorm:"size:128;not null;default:'';index" json:"request_id"`
+	CreatedAt  time.Time `gorm:"index" json:"created_at"`
+	PrevHash   string    `gorm:"size:64;not null;default:''" json:"prev_hash"`
+	Hash       string    `gorm:"size:64;not null;default:'';index" json:"hash"`
+}
+
+// ComputeHash returns the hex SHA-256 of the entry's previous hash and contents. CreatedAt must
+// already be truncated to the microsecond precision the database keeps.
+func (a AuditLog) ComputeHash() string {
+	contents, _ := json.Marshal(struct {
+		Actor      string  `json:"actor"`
+		Action     string  `json:"action"`
+		TargetType string  `json:"target_type"`
+		TargetID   string  `json:"target_id"`
+		Before     *string `json:"before"`
+		After      *string `json:"after"`
+		RequestID  string  `json:"request_id"`
+		CreatedAt  string  `json:"created_at"`
+	}{a.Actor, a.Action, a.TargetType, a.TargetID, a.Before, a.After, a.RequestID, a.CreatedAt.UTC().Format(time.RFC3339Nano)})
+
+	sum := sha256.Sum256(append([]byte(a.PrevHash+"\n"), contents...))
+	return hex.EncodeToString(sum[:])
+}
+
+// AuditCheckpoint is a signed statement that the audit chain ended at EntryID with EntryHash.
+// Checkpoints reveal entries removed from the end of the chain, which hashing alone cannot.
+type AuditCheckpoint struct {
+	ID        uint      `gorm:"primaryKey;autoIncrement" json:"id"`
+	EntryID   uint      `gorm:"not null;index" json:"entry_id"`
+	EntryHash string    `gorm:"size:64;not null" json:"entry_hash"`
+	KeyID     string    `gorm:"size:16;not null" json:"key_id"`
+	Signature string    `gorm:"not null" json:"signature"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+// CheckpointMessage is the message signed for a checkpoint of the chain ending at entryID
+func CheckpointMessage(entryID uint, entryHash string) []byte {
+	return []byte("audit-checkpoint:" + strconv.FormatUint(uint64(entryID), 10) + ":" + entryHash)
+}
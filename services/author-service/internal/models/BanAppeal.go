@@ -0,0 +1,37 @@
+package models
+
+import "time"
+
+// Appeal statuses; an appeal starts pending and is reviewed once
+const (
+	AppealStatusPending  = "pending"
+	AppealStatusAccepted = "accepted"
+	AppealStatusRejected = "rejected"
+)
+
+// BanAppeal is a user's request to lift one of their bans. BanID is deliberately not a foreign key
+// so that the appeal outlives the ban it contests.
+type BanAppeal struct {
+	ID         uint       `gorm:"primaryKey;autoIncrement" json:"id"`
+	BanID      uint       `gorm:"not null;uniqueIndex" json:"ban_id"`
+	UserID     string     `gorm:"not null;index" json:"user_id"`
+	Message    string     `gorm:"type:text;not null" json:"message"`
+	Status     string     `gorm:"size:20;not null;default:'pending';index" json:"status"`
+	Response   string     `gorm:"type:text;not null;default:''" json:"response,omitempty"`
+	ReviewedBy string     `gorm:"not null;default:''" json:"reviewed_by,omitempty"`
+	ReviewedAt *time.Time `json:"reviewed_at,omitempty"`
+	CreatedAt  time.Time  `json:"created_at"`
+	UpdatedAt  time.Time  `json:"updated_at"`
+
+	Transitions []BanAppealTransition `gorm:"foreignKey:AppealID;constraint:OnDelete:CASCADE" json:"transitions,omitempty"`
+}
+
+// BanAppealTransition records a change of an appeal's status, when it happened and who made it
+type BanAppealTransition struct {
+	ID         uint      `gorm:"primaryKey;autoIncrement" json:"id"`
+	AppealID   uint      `gorm:"not null;index" json:"appeal_id"`
+	FromStatus string    `gorm:"size:20;not null;default:''" json:"from_status,omitempty"`
+	ToStatus   string    `gorm:"size:20;not null" json:"to_status"`
+	Actor      string    `gorm:"not null" json:"actor"`
+	CreatedAt  time.Time `json:"created_at"`
+}
@@ -0,0 +1,30 @@
+package models
+
+import (
+	"regexp"
+	"time"
+)
+
+var banReasonCodePattern = regexp.MustCompile(`^[a-z][a-z0-9_]*$`)
+
+// BanReason is an entry in the catalogue of reasons a user can be banned for. The defaults apply
+// to bans issued with the reason's code that do not set their own kind or duration.
+type BanReason struct {
+	Code                   string    `gorm:"primaryKey;size:50" json:"code"`
+	Label                  string    `gorm:"size:100;not null" json:"label"`
+	PlayerText             string    `gorm:"type:text;not null;default:''" json:"player_text"`
+	DefaultKind            string    `gorm:"size:20;not null;default:'suspension'" json:"default_kind"`
+	DefaultDurationSeconds int64     `gorm:"not null;default:0" json:"default_duration_seconds"`
+	CreatedAt              time.Time `json:"created_at"`
+	UpdatedAt              time.Time `json:"updated_at"`
+}
+
+// DefaultDuration returns the default ban duration; zero means permanent
+func (r BanReason) DefaultDuration() time.Duration {
+	return time.Duration(r.DefaultDurationSeconds) * time.Second
+}
+
+// IsValidBanReasonCode reports whether code is a lowercase identifier such as "afk_abuse"
+func IsValidBanReasonCode(code string) bool {
+	return len(code) <= 50 && banReasonCodePattern.MatchString(code)
+}
@@ -0,0 +1,93 @@
+package models
+
+import "time"
+
+// Domain event types published through the outbox
+const (
+	EventUserBanned            = "user.banned"
+	EventUserUnbanned          = "user.unbanned"
+	EventUserBanExpired        = "user.ban_expired"
+	EventUserBanUpdated        = "user.ban_updated"
+	EventBanAppealSubmitted    = "ban_appeal.submitted"
+	EventBanAppealAccepted     = "ban_appeal.accepted"
+	EventBanAppealRejected     = "ban_appeal.rejected"
+	EventUserRoleAssigned      = "user.role_assigned"
+	EventUserRoleRemoved       = "user.role_removed"
+	EventRoleCreated           = "role.created"
+	EventRoleUpdated           = "role.updated"
+	EventRoleDeleted           = "role.deleted"
+	EventRolePermissionAdded   = "role.permission_added"
+	EventRolePermissionRemoved = "role.permission_removed"
+	EventRoleParentAdded       = "role.parent_added"
+	EventRoleParentRemoved     = "role.parent_removed"
+	EventPermissionCreated     = "permission.created"
+	EventPermissionUpdated     = "permission.updated"
+	EventPermissionDeleted     = "permission.deleted"
+)
+
+// Aggregates outbox events are about
+const (
+	AggregateRole       = "role"
+	AggregatePermission = "permission"
+	AggregateUserBan    = "user_ban"
+	AggregateBanAppeal  = "ban_appeal"
+	AggregateUserRole   = "user_role"
+)
+
+// Delivery statuses
+const (
+	DeliveryStatusPending   = "pending"
+	DeliveryStatusDelivered = "delivered"
+	// DeliveryStatusDead is a delivery that ran out of attempts; it has a dead letter
+	DeliveryStatusDead = "dead"
+)
+
+// OutboxEvent is a domain event written in the same transaction as the change it reports, so
+// that it is published if and only if the change commits. The dispatcher gives it a delivery
+// for every configured sink and records FannedOutAt.
+type OutboxEvent struct {
+	ID            uint       `gorm:"primaryKey;autoIncrement" json:"id"`
+	Type          string     `gorm:"size:100;not null;index" json:"type"`
+	AggregateType string     `gorm:"size:50;not null;index:idx_outbox_events_aggregate" json:"aggregate_type"`
+	AggregateID   string     `gorm:"size:100;not null;default:'';index:idx_outbox_events_aggregate" json:"aggregate_id"`
+	Payload       string     `gorm:"type:jsonb;not null" json:"payload"`
+	Actor         string     `gorm:"not null;default:''" json:"actor"`
+	RequestID     string     `gorm:"size:128;not null;default:''" json:"request_id"`
+	CreatedAt     time.Time  `gorm:"index" json:"created_at"`
+	FannedOutAt   *time.Time `gorm:"index" json:"fanned_out_at,omitempty"`
+
+	Deliveries []OutboxDelivery `gorm:"foreignKey:EventID" json:"deliveries,omitempty"`
+}
+
+// OutboxDelivery tracks the delivery of an event to one sink. A pending delivery is attempted
+// once NextAttemptAt has passed; the dispatcher also pushes NextAttemptAt forward while it holds
+// the delivery, so that another instance does not attempt it at the same time.
+type OutboxDelivery struct {
+	ID            uint       `gorm:"primaryKey;autoIncrement" json:"id"`
+	EventID       uint       `gorm:"not null;uniqueIndex:idx_outbox_deliveries_event_sink" json:"event_id"`
+	Sink          string     `gorm:"size:100;not null;uniqueIndex:idx_outbox_deliveries_event_sink" json:"sink"`
+	Status        string     `gorm:"size:20;not null;index:idx_outbox_deliveries_due" json:"status"`
+	Attempts      int        `gorm:"not null;default:0" json:"attempts"`
+	NextAttemptAt time.Time  `gorm:"not null;index:idx_outbox_deliveries_due" json:"next_attempt_at"`
+	LastError     string     `gorm:"type:text;not null;default:''" json:"last_error,omitempty"`
+	DeliveredAt   *time.Time `json:"delivered_at,omitempty"`
+	CreatedAt     time.Time  `json:"created_at"`
+	UpdatedAt     time.Time  `json:"updated_at"`
+
+	Event OutboxEvent `gorm:"foreignKey:EventID" json:"-"`
+}
+
+// OutboxDeadLetter records a delivery that gave up after its last attempt. Retrying it puts the
+// delivery back in the queue and records RetriedAt.
+type OutboxDeadLetter struct {
+	ID         uint       `gorm:"primaryKey;autoIncrement" json:"id"`
+	DeliveryID uint       `gorm:"not null;index" json:"delivery_id"`
+	EventID    uint       `gorm:"not null;index" json:"event_id"`
+	Sink       string     `gorm:"size:100;not null" json:"sink"`
+	Attempts   int        `gorm:"not null" json:"attempts"`
+	LastError  string     `gorm:"type:text;not null;default:''" json:"last_error"`
+	CreatedAt  time.Time  `gorm:"index" json:"created_at"`
+	RetriedAt  *time.Time `json:"retried_at,omitempty"`
+
+	Event OutboxEvent `gorm:"foreignKey:EventID" json:"-"`
+}
@@ -1,7 +1,85 @@
 package models
 
+import (
+	"regexp"
+	"strings"
+)
+
+// PermissionWildcard grants every permission, or every action under a resource when used as "resource:*"
+const PermissionWildcard = "*"
+
+// permissionNamePattern accepts "resource:action", deeper "resource:sub:action" names and
+// wildcards in the last segment only
+var permissionNamePattern = regexp.MustCompile(`^(\*|[a-z][a-z0-9_]*(:[a-z][a-z0-9_]*)*:([a-z][a-z0-9_]*|\*))$`)
+
 type Permission struct {
-	PermID uint `gorm:"primaryKey;autoIncrement" json:"perm_id"`
+	PermID uint   `gorm:"primaryKey;autoIncrement" json:"perm_id"`
 	Name   string `gorm:"size:100;not null;unique" json:"name"`
-	Roles  []Role `gorm:"many2many:role_permission;" json:"roles,omitempty"`
-}
\ No newline at end of file
+	Roles  []Role `gorm:"many2many:role_permission;joinForeignKey:PermID;joinReferences:RoleID" json:"roles,omitempty"`
+}
+
+// IsValidPermissionName reports whether name follows the resource:action naming scheme
+func IsValidPermissionName(name string) bool {
+	return permissionNamePattern.MatchString(name)
+}
+
+// IsWildcard reports whether the permission grants a family of permissions rather than a single one
+func (p Permission) IsWildcard() bool {
+	return p.Name == PermissionWildcard || strings.HasSuffix(p.Name, ":"+PermissionWildcard)
+}
+
+// PermissionMatches reports whether a grant of pattern covers the permission name.
+// It mirrors the SQL matching used when resolving grants and bans.
+func PermissionMatches(pattern, name string) bool {
+	if pattern == name || pattern == PermissionWildcard {
+		return true
+	}
+	if strings.HasSuffix(pattern, ":"+PermissionWildcard) {
+		return strings.HasPrefix(name, strings.TrimSuffix(pattern, PermissionWildcard))
+	}
+	return false
+}
+
+// Permissions that gate the service's own admin API
+const (
+	PermissionRoleView           = "role:view"
+	PermissionRoleCreate         = "role:create"
+	PermissionRoleUpdate         = "role:update"
+	PermissionRoleDelete         = "role:delete"
+	PermissionRoleAssign         = "role:assign"
+	PermissionRoleRemove         = "role:remove"
+	PermissionPermissionView     = "permission:view"
+	PermissionPermissionCreate   = "permission:create"
+	PermissionPermissionUpdate   = "permission:update"
+	PermissionPermissionDelete   = "permission:delete"
+	PermissionPermissionAssign   = "permission:assign"
+	PermissionPermissionRemove   = "permission:remove"
+	PermissionModerationBan      = "moderation:ban"
+	PermissionModerationUnban    = "moderation:unban"
+	PermissionModerationViewBans = "moderation:view_bans"
+	PermissionModerationReasons  = "moderation:manage_reasons"
+	PermissionModerationAppeals  = "moderation:review_appeals"
+	PermissionAppealSubmit       = "appeal:submit"
+	PermissionAuthorizationCheck = "authorization:check"
+	PermissionAttributeView      = "attribute:view"
+	PermissionAttributeWrite     = "attribute:write"
+	PermissionAuditView          = "audit:view"
+	PermissionAPIKeyManage       = "api_key:manage"
+	PermissionTokenIssue         = "token:issue"
+	PermissionOutboxView         = "outbox:view"
+	PermissionOutboxManage       = "outbox:manage"
+)
+
+// AdminAPIPermissions lists every permission the admin API checks
+var AdminAPIPermissions = []string{
+	PermissionRoleView, PermissionRoleCreate, PermissionRoleUpdate, PermissionRoleDelete,
+	PermissionRoleAssign, PermissionRoleRemove,
+	PermissionPermissionView, PermissionPermissionCreate, PermissionPermissionUpdate,
+	PermissionPermissionDelete, PermissionPermissionAssign, PermissionPermissionRemove,
+	PermissionModerationBan, PermissionModerationUnban, PermissionModerationViewBans,
+	PermissionModerationReasons, PermissionModerationAppeals,
+	PermissionAppealSubmit, PermissionAuthorizationCheck,
+	PermissionAttributeView, PermissionAttributeWrite, PermissionAuditView,
+	PermissionAPIKeyManage, PermissionTokenIssue,
+	PermissionOutboxView, PermissionOutboxManage,
+}
@@ -0,0 +1,34 @@
+package models
+
+import "fmt"
+
+// ResourceScope narrows a grant or ban to a resource type (e.g. "room") or a single resource of
+// that type. The zero value is the global scope.
+type ResourceScope struct {
+	ResourceType string `json:"resource_type,omitempty"`
+	ResourceID   string `json:"resource_id,omitempty"`
+}
+
+// IsGlobal reports whether the scope applies to every resource
+func (s ResourceScope) IsGlobal() bool {
+	return s.ResourceType == ""
+}
+
+// Validate rejects a resource ID without a resource type
+func (s ResourceScope) Validate() error {
+	if s.ResourceType == "" && s.ResourceID != "" {
+		return fmt.Errorf("resource type is required when a resource ID is given")
+	}
+	return nil
+}
+
+// Covers reports whether a grant or ban with this scope applies to a check against target
+func (s ResourceScope) Covers(target ResourceScope) bool {
+	if s.IsGlobal() {
+		return true
+	}
+	if s.ResourceType != target.ResourceType {
+		return false
+	}
+	return s.ResourceID == "" || s.ResourceID == target.ResourceID
+}
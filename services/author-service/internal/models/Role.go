@@ -1,7 +1,8 @@
 package models
 
 type Role struct {
-  RoleID      uint           `gorm:"primaryKey;autoIncrement" json:"role_id"`
-  Name        string         `gorm:"size:100;not null;unique" json:"name"`
-	Permissions []Permission   `gorm:"many2many:role_permission;" json:"permissions,omitempty"`
-}
\ No newline at end of file
+	RoleID      uint             `gorm:"primaryKey;autoIncrement" json:"role_id"`
+	Name        string           `gorm:"size:100;not null;unique" json:"name"`
+	Permissions []Permission     `gorm:"many2many:role_permission;joinForeignKey:RoleID;joinReferences:PermID" json:"permissions,omitempty"`
+	Grants      []RolePermission `gorm:"foreignKey:RoleID;references:RoleID" json:"grants,omitempty"`
+}
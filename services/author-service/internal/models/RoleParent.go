@@ -0,0 +1,9 @@
+package models
+
+type RoleParent struct {
+	RoleID   uint `gorm:"primaryKey" json:"role_id"`
+	ParentID uint `gorm:"primaryKey;index" json:"parent_id"`
+
+	Role   Role `gorm:"foreignKey:RoleID;references:RoleID;constraint:OnDelete:CASCADE" json:"-"`
+	Parent Role `gorm:"foreignKey:ParentID;references:RoleID;constraint:OnDelete:CASCADE" json:"parent"`
+}
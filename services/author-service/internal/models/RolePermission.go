@@ -0,0 +1,34 @@
+package models
+
+// Grant effects; a deny grant overrides every allow grant of the permission it covers
+const (
+	GrantEffectAllow = "allow"
+	GrantEffectDeny  = "deny"
+)
+
+// RolePermission is a grant of a permission to a role, optionally limited to a resource scope
+// and to the checks for which its CEL Condition holds. Effect decides whether it allows or denies.
+type RolePermission struct {
+	RoleID       uint   `gorm:"primaryKey" json:"role_id"`
+	PermID       uint   `gorm:"primaryKey" json:"perm_id"`
+	ResourceType string `gorm:"primaryKey;size:50;not null;default:''" json:"resource_type,omitempty"`
+	ResourceID   string `gorm:"primaryKey;size:100;not null;default:''" json:"resource_id,omitempty"`
+	Condition    string `gorm:"type:text;not null;default:''" json:"condition,omitempty"`
+	Effect       string `gorm:"size:10;not null;default:'allow'" json:"effect"`
+
+	Permission Permission `gorm:"foreignKey:PermID;references:PermID" json:"permission"`
+}
+
+func (RolePermission) TableName() string {
+	return "role_permission"
+}
+
+// Scope returns the resource scope of the grant
+func (rp RolePermission) Scope() ResourceScope {
+	return ResourceScope{ResourceType: rp.ResourceType, ResourceID: rp.ResourceID}
+}
+
+// IsValidGrantEffect reports whether effect is allow or deny
+func IsValidGrantEffect(effect string) bool {
+	return effect == GrantEffectAllow || effect == GrantEffectDeny
+}
@@ -0,0 +1,12 @@
+package models
+
+import "time"
+
+// UserAttribute is a stored fact about a user, such as account_age_days or region, that grant
+// conditions can refer to. Value holds the JSON encoding of the attribute.
+type UserAttribute struct {
+	UserID    string    `gorm:"primaryKey;size:100" json:"user_id"`
+	Key       string    `gorm:"primaryKey;size:100" json:"key"`
+	Value     string    `gorm:"type:text;not null" json:"value"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
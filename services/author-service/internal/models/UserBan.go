@@ -2,13 +2,103 @@ package models
 
 import "time"
 
+// Ban types, told apart by whether the ban names a permission
+const (
+	BanTypePermission = "permission"
+	BanTypeGlobal     = "global"
+)
+
+// Ban kinds, from the mildest to the most severe
+const (
+	// BanKindWarning is recorded against the user but has no effect
+	BanKindWarning = "warning"
+	// BanKindRestriction keeps the permission but degrades it, e.g. a low-priority matchmaking queue
+	BanKindRestriction = "restriction"
+	// BanKindShadowBan keeps the permission for the user while its effects are hidden from others;
+	// the user must not be told about it
+	BanKindShadowBan = "shadow_ban"
+	// BanKindMute withholds the permission, typically a chat permission
+	BanKindMute = "mute"
+	// BanKindSuspension withholds the permission
+	BanKindSuspension = "suspension"
+)
+
+var banKindSeverity = map[string]int{
+	BanKindWarning:     1,
+	BanKindRestriction: 2,
+	BanKindShadowBan:   3,
+	BanKindMute:        4,
+	BanKindSuspension:  5,
+}
+
+// IsValidBanKind reports whether kind is one of the ban kinds
+func IsValidBanKind(kind string) bool {
+	_, ok := banKindSeverity[kind]
+	return ok
+}
+
+// BanKindSeverity orders ban kinds; a higher value is more severe
+func BanKindSeverity(kind string) int {
+	return banKindSeverity[kind]
+}
+
+// UserBan withholds a permission from a user, or every permission when PermID is nil, except
+// for the permissions listed in Exemptions. A ban with ExpiresAt set is temporary; once it has
+// run out the ban sweeper records ExpiredAt. Lifting a ban early records LiftedAt instead, so
+// ended bans stay on as history. ReasonCode refers to the reason catalogue and Notes add the
+// moderator's free text. StrikePoints count towards strike escalation, and EscalationRule names
+// the rule that made the ban harsher, if any.
 type UserBan struct {
-	ID        uint      `gorm:"primaryKey;autoIncrement" json:"id"`
-	UserID    string    `gorm:"not null;index" json:"user_id"`
-	PermID    uint      `gorm:"not null;index" json:"perm_id"`
-	Reason    string    `gorm:"not null" json:"reason"`
-	CreatedAt time.Time `json:"created_at"`
-	UpdatedAt time.Time `json:"updated_at"`
-
-	Permission Permission `gorm:"foreignKey:PermID;references:PermID"`
-}
\ No newline at end of file
+	ID             uint       `gorm:"primaryKey;autoIncrement" json:"id"`
+	UserID         string     `gorm:"not null;index" json:"user_id"`
+	PermID         *uint      `gorm:"index" json:"perm_id"`
+	Reason         string     `gorm:"not null" json:"reason"`
+	ReasonCode     *string    `gorm:"size:50;index" json:"reason_code,omitempty"`
+	Notes          string     `gorm:"type:text;not null;default:''" json:"notes,omitempty"`
+	Kind           string     `gorm:"size:20;not null;default:'suspension';index" json:"kind"`
+	StrikePoints   int        `gorm:"not null;default:0" json:"strike_points"`
+	EscalationRule string     `gorm:"size:100;not null;default:''" json:"escalation_rule,omitempty"`
+	ResourceType   string     `gorm:"size:50;not null;default:''" json:"resource_type,omitempty"`
+	ResourceID     string     `gorm:"size:100;not null;default:''" json:"resource_id,omitempty"`
+	ExpiresAt      *time.Time `gorm:"index" json:"expires_at,omitempty"`
+	ExpiredAt      *time.Time `json:"expired_at,omitempty"`
+	IssuedBy       string     `gorm:"not null;default:''" json:"issued_by,omitempty"`
+	LiftedAt       *time.Time `gorm:"index" json:"lifted_at,omitempty"`
+	LiftedBy       string     `gorm:"not null;default:''" json:"lifted_by,omitempty"`
+	LiftReason     string     `gorm:"type:text;not null;default:''" json:"lift_reason,omitempty"`
+	CreatedAt      time.Time  `json:"created_at"`
+	UpdatedAt      time.Time  `json:"updated_at"`
+
+	Permission Permission   `gorm:"foreignKey:PermID;references:PermID"`
+	BanReason  *BanReason   `gorm:"foreignKey:ReasonCode;references:Code" json:"ban_reason,omitempty"`
+	Exemptions []Permission `gorm:"many2many:user_ban_exemptions;joinForeignKey:BanID;joinReferences:PermID" json:"exemptions,omitempty"`
+}
+
+// Blocks reports whether the ban withholds its permissions; other kinds are for the
+// game-service to act on
+func (b UserBan) Blocks() bool {
+	return b.Kind == BanKindMute || b.Kind == BanKindSuspension
+}
+
+// IsGlobal reports whether the ban covers every permission
+func (b UserBan) IsGlobal() bool {
+	return b.PermID == nil
+}
+
+// Type returns BanTypeGlobal or BanTypePermission
+func (b UserBan) Type() string {
+	if b.IsGlobal() {
+		return BanTypeGlobal
+	}
+	return BanTypePermission
+}
+
+// Scope returns the resource scope of the ban
+func (b UserBan) Scope() ResourceScope {
+	return ResourceScope{ResourceType: b.ResourceType, ResourceID: b.ResourceID}
+}
+
+// IsActive reports whether the ban is still in force at the given time
+func (b UserBan) IsActive(now time.Time) bool {
+	return b.LiftedAt == nil && b.ExpiredAt == nil && (b.ExpiresAt == nil || b.ExpiresAt.After(now))
+}
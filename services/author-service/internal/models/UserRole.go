@@ -0,0 +1,12 @@
+package models
+
+import "time"
+
+type UserRole struct {
+	ID        uint      `gorm:"primaryKey;autoIncrement" json:"id"`
+	UserID    string    `gorm:"size:100;not null;uniqueIndex:idx_user_roles_user_role" json:"user_id"`
+	RoleID    uint      `gorm:"not null;uniqueIndex:idx_user_roles_user_role;index" json:"role_id"`
+	CreatedAt time.Time `json:"created_at"`
+
+	Role Role `gorm:"foreignKey:RoleID;references:RoleID;constraint:OnDelete:CASCADE" json:"role"`
+}
@@ -0,0 +1,52 @@
+package outbox
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"gin/internal/models"
+
+	"github.com/redis/go-redis/v9"
+)
+
+// DefaultStreamMaxLen is roughly how many events a stream keeps when no limit is configured
+const DefaultStreamMaxLen = 100000
+
+// RedisStreamSink appends each event to a Redis stream, with the event ID and type as fields
+// next to the JSON envelope so that consumers can filter without decoding it
+type RedisStreamSink struct {
+	client *redis.Client
+	stream string
+	maxLen int64
+}
+
+// NewRedisStreamSink creates a stream sink named "redis_stream:<stream>"; the stream is trimmed
+// to about maxLen entries
+func NewRedisStreamSink(client *redis.Client, stream string, maxLen int64) *RedisStreamSink {
+	if maxLen <= 0 {
+		maxLen = DefaultStreamMaxLen
+	}
+	return &RedisStreamSink{client: client, stream: stream, maxLen: maxLen}
+}
+
+func (r *RedisStreamSink) Name() string {
+	return "redis_stream:" + r.stream
+}
+
+func (r *RedisStreamSink) Deliver(ctx context.Context, event models.OutboxEvent) error {
+	body, err := json.Marshal(NewEnvelope(event))
+	if err != nil {
+		return fmt.Errorf("failed to encode event: %w", err)
+	}
+
+	return r.client.XAdd(ctx, &redis.XAddArgs{
+		Stream: r.stream,
+		MaxLen: r.maxLen,
+		Approx: true,
+		Values: map[string]interface{}{
+			"id":    event.ID,
+			"type":  event.Type,
+			"event": body,
+		},
+	}).Err()
+}
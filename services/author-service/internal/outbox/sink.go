@@ -0,0 +1,43 @@
+// Package outbox holds the sinks outbox events are delivered to. Delivery is at least once:
+// consumers should ignore events whose ID they have already handled.
+package outbox
+
+import (
+	"context"
+	"encoding/json"
+	"gin/internal/models"
+	"time"
+)
+
+// Sink delivers outbox events to one destination
+type Sink interface {
+	// Name identifies the sink in delivery records, so it must not change across restarts
+	Name() string
+	Deliver(ctx context.Context, event models.OutboxEvent) error
+}
+
+// Envelope is the document every sink delivers
+type Envelope struct {
+	ID            uint            `json:"id"`
+	Type          string          `json:"type"`
+	AggregateType string          `json:"aggregate_type"`
+	AggregateID   string          `json:"aggregate_id,omitempty"`
+	Actor         string          `json:"actor,omitempty"`
+	RequestID     string          `json:"request_id,omitempty"`
+	OccurredAt    time.Time       `json:"occurred_at"`
+	Data          json.RawMessage `json:"data"`
+}
+
+// NewEnvelope wraps an event for delivery
+func NewEnvelope(event models.OutboxEvent) Envelope {
+	return Envelope{
+		ID:            event.ID,
+		Type:          event.Type,
+		AggregateType: event.AggregateType,
+		AggregateID:   event.AggregateID,
+		Actor:         event.Actor,
+		RequestID:     event.RequestID,
+		OccurredAt:    event.CreatedAt,
+		Data:          json.RawMessage(event.Payload),
+	}
+}
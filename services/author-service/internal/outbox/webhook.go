@@ -0,0 +1,96 @@
+package outbox
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"gin/internal/models"
+	"io"
+	"net/http"
+	"strconv"
+	"time"
+)
+
+// DefaultWebhookTimeout bounds a single webhook request
+const DefaultWebhookTimeout = 10 * time.Second
+
+// Webhook headers. The signature is "sha256=" followed by the hex HMAC-SHA256, keyed with the
+// shared secret, of the timestamp, a dot and the request body; receivers should recompute it
+// and reject old timestamps.
+const (
+	HeaderEventID   = "X-Outbox-Event-ID"
+	HeaderEventType = "X-Outbox-Event-Type"
+	HeaderTimestamp = "X-Outbox-Timestamp"
+	HeaderSignature = "X-Outbox-Signature"
+)
+
+// WebhookSink posts each event as JSON to a URL, signed with a shared secret
+type WebhookSink struct {
+	name   string
+	url    string
+	secret []byte
+	client *http.Client
+}
+
+// NewWebhookSink creates a webhook sink named "webhook:<name>"
+func NewWebhookSink(name, url string, secret []byte, timeout time.Duration) *WebhookSink {
+	if timeout <= 0 {
+		timeout = DefaultWebhookTimeout
+	}
+	return &WebhookSink{
+		name:   "webhook:" + name,
+		url:    url,
+		secret: secret,
+		client: &http.Client{Timeout: timeout},
+	}
+}
+
+func (w *WebhookSink) Name() string {
+	return w.name
+}
+
+// Deliver posts the event and succeeds on any 2xx response
+func (w *WebhookSink) Deliver(ctx context.Context, event models.OutboxEvent) error {
+	body, err := json.Marshal(NewEnvelope(event))
+	if err != nil {
+		return fmt.Errorf("failed to encode event: %w", err)
+	}
+
+	request, err := http.NewRequestWithContext(ctx, http.MethodPost, w.url, bytes.NewReader(body))
+	if err != nil {
+		return fmt.Errorf("failed to create request: %w", err)
+	}
+
+	timestamp := strconv.FormatInt(time.Now().Unix(), 10)
+	request.Header.Set("Content-Type", "application/json")
+	request.Header.Set(HeaderEventID, strconv.FormatUint(uint64(event.ID), 10))
+	request.Header.Set(HeaderEventType, event.Type)
+	request.Header.Set(HeaderTimestamp, timestamp)
+	request.Header.Set(HeaderSignature, "sha256="+Sign(w.secret, timestamp, body))
+
+	response, err := w.client.Do(request)
+	if err != nil {
+		return err
+	}
+	defer response.Body.Close()
+	io.Copy(io.Discard, io.LimitReader(response.Body, 64<<10))
+
+	if response.StatusCode < 200 || response.StatusCode >= 300 {
+		return fmt.Errorf("webhook responded %s", response.Status)
+	}
+	return nil
+}
+
+// Sign returns the hex HMAC-SHA256 a webhook request with the given timestamp and body is
+// signed with
+func Sign(secret []byte, timestamp string, body []byte) string {
+	mac := hmac.New(sha256.New, secret)
+	mac.Write([]byte(timestamp))
+	mac.Write([]byte("."))
+	mac.Write(body)
+	return hex.EncodeToString(mac.Sum(nil))
+}
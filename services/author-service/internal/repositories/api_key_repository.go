@@ -0,0 +1,75 @@
+package repositories
+
+import (
+	"gin/internal/models"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+type APIKeyRepositoryInterface interface {
+	Create(key *models.APIKey) error
+	GetByID(id uint) (*models.APIKey, error)
+	GetByHash(hash string) (*models.APIKey, error)
+	GetAll() ([]models.APIKey, error)
+	Update(key *models.APIKey) error
+	Revoke(id uint, now time.Time) error
+	TouchLastUsed(id uint, now time.Time) error
+}
+
+type APIKeyRepository struct {
+	db *gorm.DB
+}
+
+func NewAPIKeyRepository(db *gorm.DB) APIKeyRepositoryInterface {
+	return &APIKeyRepository{db: db}
+}
+
+func (a *APIKeyRepository) Create(key *models.APIKey) error {
+	return a.db.Create(key).Error
+}
+
+func (a *APIKeyRepository) GetByID(id uint) (*models.APIKey, error) {
+	var key models.APIKey
+	if err := a.db.First(&key, id).Error; err != nil {
+		return nil, err
+	}
+	return &key, nil
+}
+
+func (a *APIKeyRepository) GetByHash(hash string) (*models.APIKey, error) {
+	var key models.APIKey
+	if err := a.db.Where("key_hash = ?", hash).First(&key).Error; err != nil {
+		return nil, err
+	}
+	return &key, nil
+}
+
+// GetAll returns every key, including revoked ones, newest first
+func (a *APIKeyRepository) GetAll() ([]models.APIKey, error) {
+	var keys []models.APIKey
+	err := a.db.Order("id DESC").Find(&keys).Error
+	return keys, err
+}
+
+func (a *APIKeyRepository) Update(key *models.APIKey) error {
+	return a.db.Save(key).Error
+}
+
+// Revoke revokes a key that is not revoked yet; it returns gorm.ErrRecordNotFound otherwise
+func (a *APIKeyRepository) Revoke(id uint, now time.Time) error {
+	result := a.db.Model(&models.APIKey{}).
+		Where("id = ? AND revoked_at IS NULL", id).
+		Update("revoked_at", now)
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return gorm.ErrRecordNotFound
+	}
+	return nil
+}
+
+func (a *APIKeyRepository) TouchLastUsed(id uint, now time.Time) error {
+	return a.db.Model(&models.APIKey{}).Where("id = ?", id).Update("last_used_at", now).Error
+}
@@ -0,0 +1,133 @@
+package repositories
+
+import (
+	"gin/internal/models"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// AuditLogFilter narrows an audit log listing; zero fields match every entry
+type AuditLogFilter struct {
+	Actor      string
+	Action     string
+	TargetType string
+	TargetID   string
+	RequestID  string
+	Since      time.Time
+	Until      time.Time
+	Limit      int
+	Offset     int
+}
+
+// auditChainLockKey identifies the advisory lock that serialises appends to the audit chain
+const auditChainLockKey = 0x61756469 // "audi"
+
+// AuditLogRepositoryInterface appends to and reads the audit log; entries are never changed
+type AuditLogRepositoryInterface interface {
+	Create(entry *models.AuditLog) error
+	Find(filter AuditLogFilter) ([]models.AuditLog, int64, error)
+	GetLast() (*models.AuditLog, error)
+	GetAfter(afterID uint, limit int) ([]models.AuditLog, error)
+	CreateCheckpoint(checkpoint *models.AuditCheckpoint) error
+	GetLatestCheckpoint() (*models.AuditCheckpoint, error)
+	GetCheckpoints() ([]models.AuditCheckpoint, error)
+}
+
+type AuditLogRepository struct {
+	db *gorm.DB
+}
+
+func NewAuditLogRepository(db *gorm.DB) AuditLogRepositoryInterface {
+	return &AuditLogRepository{db: db}
+}
+
+// Create appends an entry to the chain, linking it to the latest entry. The chain is locked until
+// the surrounding transaction ends, so entries are chained in the order their IDs are assigned.
+func (a *AuditLogRepository) Create(entry *models.AuditLog) error {
+	return a.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Exec("SELECT pg_advisory_xact_lock(?)", auditChainLockKey).Error; err != nil {
+			return err
+		}
+
+		var last models.AuditLog
+		err := tx.Select("hash").Order("id DESC").Limit(1).Find(&last).Error
+		if err != nil {
+			return err
+		}
+
+		entry.CreatedAt = entry.CreatedAt.Truncate(time.Microsecond)
+		entry.PrevHash = last.Hash
+		entry.Hash = entry.ComputeHash()
+		return tx.Create(entry).Error
+	})
+}
+
+// Find returns a page of matching entries, newest first, and the number of matching entries
+func (a *AuditLogRepository) Find(filter AuditLogFilter) ([]models.AuditLog, int64, error) {
+	query := a.db.Model(&models.AuditLog{})
+	if filter.Actor != "" {
+		query = query.Where("actor = ?", filter.Actor)
+	}
+	if filter.Action != "" {
+		query = query.Where("action = ?", filter.Action)
+	}
+	if filter.TargetType != "" {
+		query = query.Where("target_type = ?", filter.TargetType)
+	}
+	if filter.TargetID != "" {
+		query = query.Where("target_id = ?", filter.TargetID)
+	}
+	if filter.RequestID != "" {
+		query = query.Where("request_id = ?", filter.RequestID)
+	}
+	if !filter.Since.IsZero() {
+		query = query.Where("created_at >= ?", filter.Since)
+	}
+	if !filter.Until.IsZero() {
+		query = query.Where("created_at < ?", filter.Until)
+	}
+
+	var total int64
+	if err := query.Count(&total).Error; err != nil {
+		return nil, 0, err
+	}
+
+	var entries []models.AuditLog
+	err := query.Order("id DESC").Limit(filter.Limit).Offset(filter.Offset).Find(&entries).Error
+	return entries, total, err
+}
+
+// GetLast returns the latest entry of the chain
+func (a *AuditLogRepository) GetLast() (*models.AuditLog, error) {
+	var entry models.AuditLog
+	if err := a.db.Order("id DESC").First(&entry).Error; err != nil {
+		return nil, err
+	}
+	return &entry, nil
+}
+
+// GetAfter returns up to limit entries following afterID, in chain order
+func (a *AuditLogRepository) GetAfter(afterID uint, limit int) ([]models.AuditLog, error) {
+	var entries []models.AuditLog
+	err := a.db.Where("id > ?", afterID).Order("id").Limit(limit).Find(&entries).Error
+	return entries, err
+}
+
+func (a *AuditLogRepository) CreateCheckpoint(checkpoint *models.AuditCheckpoint) error {
+	return a.db.Create(checkpoint).Error
+}
+
+func (a *AuditLogRepository) GetLatestCheckpoint() (*models.AuditCheckpoint, error) {
+	var checkpoint models.AuditCheckpoint
+	if err := a.db.Order("id DESC").First(&checkpoint).Error; err != nil {
+		return nil, err
+	}
+	return &checkpoint, nil
+}
+
+func (a *AuditLogRepository) GetCheckpoints() ([]models.AuditCheckpoint, error) {
+	var checkpoints []models.AuditCheckpoint
+	err := a.db.Order("id").Find(&checkpoints).Error
+	return checkpoints, err
+}
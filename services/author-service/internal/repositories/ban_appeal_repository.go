@@ -0,0 +1,96 @@
+package repositories
+
+import (
+	"errors"
+	"gin/internal/models"
+
+	"gorm.io/gorm"
+)
+
+// ErrAppealAlreadyReviewed is returned when an appeal left the expected status before a transition
+var ErrAppealAlreadyReviewed = errors.New("appeal has already been reviewed")
+
+type BanAppealRepositoryInterface interface {
+	Create(appeal *models.BanAppeal) error
+	GetByID(id uint) (*models.BanAppeal, error)
+	GetByBanID(banID uint) (*models.BanAppeal, error)
+	GetByUserID(userID string) ([]models.BanAppeal, error)
+	GetByStatus(status string) ([]models.BanAppeal, error)
+	Transition(appeal *models.BanAppeal, fromStatus string, transition *models.BanAppealTransition) error
+}
+
+type BanAppealRepository struct {
+	db *gorm.DB
+}
+
+func NewBanAppealRepository(db *gorm.DB) BanAppealRepositoryInterface {
+	return &BanAppealRepository{db: db}
+}
+
+// Create stores a new appeal together with its initial transitions
+func (r *BanAppealRepository) Create(appeal *models.BanAppeal) error {
+	return r.db.Create(appeal).Error
+}
+
+func (r *BanAppealRepository) GetByID(id uint) (*models.BanAppeal, error) {
+	var appeal models.BanAppeal
+	err := r.db.Preload("Transitions", func(db *gorm.DB) *gorm.DB {
+		return db.Order("id")
+	}).First(&appeal, id).Error
+	if err != nil {
+		return nil, err
+	}
+	return &appeal, nil
+}
+
+func (r *BanAppealRepository) GetByBanID(banID uint) (*models.BanAppeal, error) {
+	var appeal models.BanAppeal
+	err := r.db.Where("ban_id = ?", banID).First(&appeal).Error
+	if err != nil {
+		return nil, err
+	}
+	return &appeal, nil
+}
+
+func (r *BanAppealRepository) GetByUserID(userID string) ([]models.BanAppeal, error) {
+	var appeals []models.BanAppeal
+	err := r.db.Where("user_id = ?", userID).Order("id").Find(&appeals).Error
+	return appeals, err
+}
+
+// GetByStatus returns the appeals with the given status, oldest first; an empty status returns all
+func (r *BanAppealRepository) GetByStatus(status string) ([]models.BanAppeal, error) {
+	var appeals []models.BanAppeal
+	query := r.db.Order("id")
+	if status != "" {
+		query = query.Where("status = ?", status)
+	}
+	err := query.Find(&appeals).Error
+	return appeals, err
+}
+
+// Transition saves the appeal's new state and records the transition, provided the appeal is
+// still in fromStatus; otherwise another review got there first and ErrAppealAlreadyReviewed is
+// returned.
+func (r *BanAppealRepository) Transition(appeal *models.BanAppeal, fromStatus string, transition *models.BanAppealTransition) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		result := tx.Model(&models.BanAppeal{}).
+			Where("id = ? AND status = ?", appeal.ID, fromStatus).
+			Updates(map[string]interface{}{
+				"status":      appeal.Status,
+				"response":    appeal.Response,
+				"reviewed_by": appeal.ReviewedBy,
+				"reviewed_at": appeal.ReviewedAt,
+				"updated_at":  appeal.UpdatedAt,
+			})
+		if result.Error != nil {
+			return result.Error
+		}
+		if result.RowsAffected == 0 {
+			return ErrAppealAlreadyReviewed
+		}
+
+		transition.AppealID = appeal.ID
+		return tx.Create(transition).Error
+	})
+}
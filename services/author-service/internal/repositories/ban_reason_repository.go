@@ -0,0 +1,58 @@
+package repositories
+
+import (
+	"gin/internal/models"
+
+	"gorm.io/gorm"
+)
+
+type BanReasonRepositoryInterface interface {
+	Create(reason *models.BanReason) error
+	GetByCode(code string) (*models.BanReason, error)
+	GetAll() ([]models.BanReason, error)
+	Update(reason *models.BanReason) error
+	Delete(code string) error
+	CountBans(code string) (int64, error)
+}
+
+type BanReasonRepository struct {
+	db *gorm.DB
+}
+
+func NewBanReasonRepository(db *gorm.DB) BanReasonRepositoryInterface {
+	return &BanReasonRepository{db: db}
+}
+
+func (r *BanReasonRepository) Create(reason *models.BanReason) error {
+	return r.db.Create(reason).Error
+}
+
+func (r *BanReasonRepository) GetByCode(code string) (*models.BanReason, error) {
+	var reason models.BanReason
+	err := r.db.Where("code = ?", code).First(&reason).Error
+	if err != nil {
+		return nil, err
+	}
+	return &reason, nil
+}
+
+func (r *BanReasonRepository) GetAll() ([]models.BanReason, error) {
+	var reasons []models.BanReason
+	err := r.db.Order("code").Find(&reasons).Error
+	return reasons, err
+}
+
+func (r *BanReasonRepository) Update(reason *models.BanReason) error {
+	return r.db.Save(reason).Error
+}
+
+func (r *BanReasonRepository) Delete(code string) error {
+	return r.db.Where("code = ?", code).Delete(&models.BanReason{}).Error
+}
+
+// CountBans returns how many bans, active or not, were issued with the reason code
+func (r *BanReasonRepository) CountBans(code string) (int64, error) {
+	var count int64
+	err := r.db.Model(&models.UserBan{}).Where("reason_code = ?", code).Count(&count).Error
+	return count, err
+}
@@ -0,0 +1,255 @@
+package repositories
+
+import (
+	"gin/internal/models"
+	"time"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// OutboxEventFilter narrows an outbox listing; zero fields match every event
+type OutboxEventFilter struct {
+	Type          string
+	AggregateType string
+	AggregateID   string
+	// DeliveryStatus matches events with at least one delivery in that status
+	DeliveryStatus string
+	Limit          int
+	Offset         int
+}
+
+// OutboxRepositoryInterface stores outbox events and tracks their delivery to sinks
+type OutboxRepositoryInterface interface {
+	CreateEvent(event *models.OutboxEvent) error
+	FanOut(sinks []string, limit int, now time.Time) (int, error)
+	ClaimDeliveries(limit int, now time.Time, lease time.Duration) ([]models.OutboxDelivery, error)
+	MarkDelivered(id uint, now time.Time) error
+	MarkFailed(id uint, attempts int, nextAttemptAt time.Time, lastError string) error
+	MarkDead(id uint, attempts int, lastError string, now time.Time) error
+	FindEvents(filter OutboxEventFilter) ([]models.OutboxEvent, int64, error)
+	GetEvent(id uint) (*models.OutboxEvent, error)
+	FindDeadLetters(includeRetried bool, limit, offset int) ([]models.OutboxDeadLetter, int64, error)
+	RetryDeadLetter(id uint, now time.Time) error
+}
+
+type OutboxRepository struct {
+	db *gorm.DB
+}
+
+func NewOutboxRepository(db *gorm.DB) OutboxRepositoryInterface {
+	return &OutboxRepository{db: db}
+}
+
+func (o *OutboxRepository) CreateEvent(event *models.OutboxEvent) error {
+	return o.db.Create(event).Error
+}
+
+// FanOut gives up to limit events that have not been fanned out a pending delivery for every
+// sink, oldest first, and returns how many events it fanned out. Events locked by another
+// instance are skipped. Without sinks nothing is fanned out, so that events wait for the first
+// sink rather than being marked fanned out with no delivery; a sink added later receives the
+// events fanned out from then on.
+func (o *OutboxRepository) FanOut(sinks []string, limit int, now time.Time) (int, error) {
+	if len(sinks) == 0 {
+		return 0, nil
+	}
+
+	var fannedOut int
+	err := o.db.Transaction(func(tx *gorm.DB) error {
+		var events []models.OutboxEvent
+		err := tx.Clauses(clause.Locking{Strength: "UPDATE", Options: "SKIP LOCKED"}).
+			Where("fanned_out_at IS NULL").
+			Order("id").Limit(limit).
+			Find(&events).Error
+		if err != nil || len(events) == 0 {
+			return err
+		}
+
+		eventIDs := make([]uint, 0, len(events))
+		deliveries := make([]models.OutboxDelivery, 0, len(events)*len(sinks))
+		for _, event := range events {
+			eventIDs = append(eventIDs, event.ID)
+			for _, sink := range sinks {
+				deliveries = append(deliveries, models.OutboxDelivery{
+					EventID:       event.ID,
+					Sink:          sink,
+					Status:        models.DeliveryStatusPending,
+					NextAttemptAt: now,
+				})
+			}
+		}
+
+		err = tx.Clauses(clause.OnConflict{DoNothing: true}).Create(&deliveries).Error
+		if err != nil {
+			return err
+		}
+
+		fannedOut = len(events)
+		return tx.Model(&models.OutboxEvent{}).Where("id IN ?", eventIDs).Update("fanned_out_at", now).Error
+	})
+	return fannedOut, err
+}
+
+// ClaimDeliveries returns up to limit pending deliveries that are due, oldest event first, with
+// their events. Each is leased until now+lease by moving its next attempt, so that other
+// instances leave it alone while it is attempted, and it is attempted again if the attempt
+// never reports back.
+func (o *OutboxRepository) ClaimDeliveries(limit int, now time.Time, lease time.Duration) ([]models.OutboxDelivery, error) {
+	var deliveries []models.OutboxDelivery
+	err := o.db.Transaction(func(tx *gorm.DB) error {
+		err := tx.Clauses(clause.Locking{Strength: "UPDATE", Options: "SKIP LOCKED"}).
+			Where("status = ? AND next_attempt_at <= ?", models.DeliveryStatusPending, now).
+			Order("event_id, id").Limit(limit).
+			Find(&deliveries).Error
+		if err != nil || len(deliveries) == 0 {
+			return err
+		}
+
+		ids := make([]uint, 0, len(deliveries))
+		for _, delivery := range deliveries {
+			ids = append(ids, delivery.ID)
+		}
+
+		err = tx.Model(&models.OutboxDelivery{}).Where("id IN ?", ids).
+			Updates(map[string]interface{}{"next_attempt_at": now.Add(lease), "updated_at": now}).Error
+		if err != nil {
+			return err
+		}
+
+		return tx.Preload("Event").Where("id IN ?", ids).Order("event_id, id").Find(&deliveries).Error
+	})
+	return deliveries, err
+}
+
+func (o *OutboxRepository) MarkDelivered(id uint, now time.Time) error {
+	return o.db.Model(&models.OutboxDelivery{}).Where("id = ?", id).
+		Updates(map[string]interface{}{
+			"status":       models.DeliveryStatusDelivered,
+			"attempts":     gorm.Expr("attempts + 1"),
+			"last_error":   "",
+			"delivered_at": now,
+			"updated_at":   now,
+		}).Error
+}
+
+func (o *OutboxRepository) MarkFailed(id uint, attempts int, nextAttemptAt time.Time, lastError string) error {
+	return o.db.Model(&models.OutboxDelivery{}).Where("id = ?", id).
+		Updates(map[string]interface{}{
+			"attempts":        attempts,
+			"next_attempt_at": nextAttemptAt,
+			"last_error":      lastError,
+			"updated_at":      time.Now(),
+		}).Error
+}
+
+// MarkDead gives up on a delivery and records it in the dead letter table
+func (o *OutboxRepository) MarkDead(id uint, attempts int, lastError string, now time.Time) error {
+	return o.db.Transaction(func(tx *gorm.DB) error {
+		var delivery models.OutboxDelivery
+		if err := tx.First(&delivery, id).Error; err != nil {
+			return err
+		}
+
+		err := tx.Model(&delivery).Updates(map[string]interface{}{
+			"status":     models.DeliveryStatusDead,
+			"attempts":   attempts,
+			"last_error": lastError,
+			"updated_at": now,
+		}).Error
+		if err != nil {
+			return err
+		}
+
+		return tx.Create(&models.OutboxDeadLetter{
+			DeliveryID: delivery.ID,
+			EventID:    delivery.EventID,
+			Sink:       delivery.Sink,
+			Attempts:   attempts,
+			LastError:  lastError,
+			CreatedAt:  now,
+		}).Error
+	})
+}
+
+// FindEvents returns a page of matching events with their deliveries, newest first, and the
+// number of matching events
+func (o *OutboxRepository) FindEvents(filter OutboxEventFilter) ([]models.OutboxEvent, int64, error) {
+	query := o.db.Model(&models.OutboxEvent{})
+	if filter.Type != "" {
+		query = query.Where("type = ?", filter.Type)
+	}
+	if filter.AggregateType != "" {
+		query = query.Where("aggregate_type = ?", filter.AggregateType)
+	}
+	if filter.AggregateID != "" {
+		query = query.Where("aggregate_id = ?", filter.AggregateID)
+	}
+	if filter.DeliveryStatus != "" {
+		query = query.Where("EXISTS (SELECT 1 FROM outbox_deliveries d WHERE d.event_id = outbox_events.id AND d.status = ?)", filter.DeliveryStatus)
+	}
+
+	var total int64
+	if err := query.Count(&total).Error; err != nil {
+		return nil, 0, err
+	}
+
+	var events []models.OutboxEvent
+	err := query.Preload("Deliveries", func(db *gorm.DB) *gorm.DB { return db.Order("sink") }).
+		Order("id DESC").Limit(filter.Limit).Offset(filter.Offset).
+		Find(&events).Error
+	return events, total, err
+}
+
+func (o *OutboxRepository) GetEvent(id uint) (*models.OutboxEvent, error) {
+	var event models.OutboxEvent
+	err := o.db.Preload("Deliveries", func(db *gorm.DB) *gorm.DB { return db.Order("sink") }).
+		First(&event, id).Error
+	if err != nil {
+		return nil, err
+	}
+	return &event, nil
+}
+
+// FindDeadLetters returns a page of dead letters with their events, newest first, and the
+// number of dead letters. Dead letters that were retried are left out unless includeRetried
+// is set.
+func (o *OutboxRepository) FindDeadLetters(includeRetried bool, limit, offset int) ([]models.OutboxDeadLetter, int64, error) {
+	query := o.db.Model(&models.OutboxDeadLetter{})
+	if !includeRetried {
+		query = query.Where("retried_at IS NULL")
+	}
+
+	var total int64
+	if err := query.Count(&total).Error; err != nil {
+		return nil, 0, err
+	}
+
+	var deadLetters []models.OutboxDeadLetter
+	err := query.Preload("Event").Order("id DESC").Limit(limit).Offset(offset).Find(&deadLetters).Error
+	return deadLetters, total, err
+}
+
+// RetryDeadLetter puts the delivery of a dead letter back in the queue with a fresh set of
+// attempts
+func (o *OutboxRepository) RetryDeadLetter(id uint, now time.Time) error {
+	return o.db.Transaction(func(tx *gorm.DB) error {
+		var deadLetter models.OutboxDeadLetter
+		if err := tx.Where("retried_at IS NULL").First(&deadLetter, id).Error; err != nil {
+			return err
+		}
+
+		err := tx.Model(&models.OutboxDelivery{}).Where("id = ?", deadLetter.DeliveryID).
+			Updates(map[string]interface{}{
+				"status":          models.DeliveryStatusPending,
+				"attempts":        0,
+				"next_attempt_at": now,
+				"updated_at":      now,
+			}).Error
+		if err != nil {
+			return err
+		}
+
+		return tx.Model(&deadLetter).Update("retried_at", now).Error
+	})
+}
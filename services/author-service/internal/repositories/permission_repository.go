@@ -1,16 +1,29 @@
 package repositories
 
 import (
+	"fmt"
 	"gin/internal/models"
 
 	"gorm.io/gorm"
 )
 
+// permissionMatchSQL is the SQL counterpart of models.PermissionMatches: it is true when the
+// permission row aliased granted covers the permission row aliased target, either exactly,
+// through "*" or through a "resource:*" prefix
+func permissionMatchSQL(granted, target string) string {
+	return fmt.Sprintf(
+		"(%[1]s.perm_id = %[2]s.perm_id OR %[1]s.name = '*' OR "+
+			"(%[1]s.name LIKE '%%:*' AND starts_with(%[2]s.name, left(%[1]s.name, -1))))",
+		granted, target)
+}
+
 type PermissionRepositoryInterface interface {
 	Create(permission *models.Permission) error
 	GetByID(id uint) (*models.Permission, error)
 	GetByName(name string) (*models.Permission, error)
 	GetAll() ([]models.Permission, error)
+	GetByIDs(ids []uint) ([]models.Permission, error)
+	GetByNames(names []string) ([]models.Permission, error)
 	Update(permission *models.Permission) error
 	Delete(id uint) error
 	GetWithRoles(id uint) (*models.Permission, error)
@@ -52,6 +65,24 @@ func (p *PermissionRepository) GetAll() ([]models.Permission, error) {
 	return permissions, err
 }
 
+func (p *PermissionRepository) GetByIDs(ids []uint) ([]models.Permission, error) {
+	var permissions []models.Permission
+	if len(ids) == 0 {
+		return permissions, nil
+	}
+	err := p.db.Where("perm_id IN ?", ids).Find(&permissions).Error
+	return permissions, err
+}
+
+func (p *PermissionRepository) GetByNames(names []string) ([]models.Permission, error) {
+	var permissions []models.Permission
+	if len(names) == 0 {
+		return permissions, nil
+	}
+	err := p.db.Where("name IN ?", names).Find(&permissions).Error
+	return permissions, err
+}
+
 // Update updates a permission
 func (p *PermissionRepository) Update(permission *models.Permission) error {
 	return p.db.Save(permission).Error
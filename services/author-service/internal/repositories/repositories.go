@@ -2,18 +2,48 @@ package repositories
 
 import "gorm.io/gorm"
 
+// Transactor runs a function against repositories bound to a single database transaction
+type Transactor interface {
+	Transaction(fn func(tx *Repositories) error) error
+}
+
 // Repositories holds all repository instances
 type Repositories struct {
-	Role       RoleRepositoryInterface
-	Permission PermissionRepositoryInterface
-	UserBan    UserBanRepositoryInterface
+	db *gorm.DB
+
+	Role          RoleRepositoryInterface
+	Permission    PermissionRepositoryInterface
+	UserBan       UserBanRepositoryInterface
+	UserRole      UserRoleRepositoryInterface
+	UserAttribute UserAttributeRepositoryInterface
+	BanReason     BanReasonRepositoryInterface
+	BanAppeal     BanAppealRepositoryInterface
+	AuditLog      AuditLogRepositoryInterface
+	APIKey        APIKeyRepositoryInterface
+	Outbox        OutboxRepositoryInterface
 }
 
 // NewRepositories creates and returns all repository instances
 func NewRepositories(db *gorm.DB) *Repositories {
 	return &Repositories{
-		Role:       NewRoleRepository(db),
-		Permission: NewPermissionRepository(db),
-		UserBan:    NewUserBanRepository(db),
+		db: db,
+
+		Role:          NewRoleRepository(db),
+		Permission:    NewPermissionRepository(db),
+		UserBan:       NewUserBanRepository(db),
+		UserRole:      NewUserRoleRepository(db),
+		UserAttribute: NewUserAttributeRepository(db),
+		BanReason:     NewBanReasonRepository(db),
+		BanAppeal:     NewBanAppealRepository(db),
+		AuditLog:      NewAuditLogRepository(db),
+		APIKey:        NewAPIKeyRepository(db),
+		Outbox:        NewOutboxRepository(db),
 	}
-}
\ No newline at end of file
+}
+
+// Transaction runs fn with repositories that share one transaction, committing if fn returns nil
+func (r *Repositories) Transaction(fn func(tx *Repositories) error) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		return fn(NewRepositories(tx))
+	})
+}
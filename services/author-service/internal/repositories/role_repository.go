@@ -1,11 +1,34 @@
 package repositories
 
 import (
+	"errors"
 	"gin/internal/models"
 
 	"gorm.io/gorm"
 )
 
+// ErrRoleHierarchyCycle is returned when a parent assignment would make a role inherit from itself
+var ErrRoleHierarchyCycle = errors.New("role hierarchy cycle detected")
+
+// ErrRoleHierarchyTooDeep is returned when a parent assignment would make a chain of inheriting
+// roles longer than MaxRoleHierarchyDepth
+var ErrRoleHierarchyTooDeep = errors.New("role hierarchy too deep")
+
+// MaxRoleHierarchyDepth bounds how far the hierarchy is walked when resolving inherited permissions
+const MaxRoleHierarchyDepth = 32
+
+// InheritedPermission is a permission a role receives from one of its ancestors
+type InheritedPermission struct {
+	PermID         uint
+	PermName       string
+	ResourceType   string
+	ResourceID     string
+	Condition      string
+	Effect         string
+	SourceRoleID   uint
+	SourceRoleName string
+}
+
 type RoleRepositoryInterface interface {
 	Create(role *models.Role) error
 	GetByID(id uint) (*models.Role, error)
@@ -14,8 +37,14 @@ type RoleRepositoryInterface interface {
 	Update(role *models.Role) error
 	Delete(id uint) error
 	GetWithPermissions(id uint) (*models.Role, error)
-	AddPermission(roleID, permissionID uint) error
-	RemovePermission(roleID, permissionID uint) error
+	GetGrant(roleID, permissionID uint, scope models.ResourceScope) (*models.RolePermission, error)
+	AddPermission(grant *models.RolePermission) error
+	RemovePermission(roleID, permissionID uint, scope models.ResourceScope) error
+	GetParents(roleID uint) ([]models.Role, error)
+	AddParent(roleID, parentID uint) error
+	RemoveParent(roleID, parentID uint) error
+	GetInheritedPermissions(roleID uint) ([]InheritedPermission, error)
+	GetInheritingUserIDs(roleID uint) ([]string, error)
 }
 
 type RoleRepository struct {
@@ -64,39 +93,177 @@ func (r *RoleRepository) Delete(id uint) error {
 
 func (r *RoleRepository) GetWithPermissions(id uint) (*models.Role, error) {
 	var role models.Role
-	err := r.db.Preload("Permissions").First(&role, id).Error
+	err := r.db.Preload("Permissions").Preload("Grants.Permission").First(&role, id).Error
 	if err != nil {
 		return nil, err
 	}
 	return &role, nil
 }
 
-func (r *RoleRepository) AddPermission(roleID, permissionID uint) error {
-	var role models.Role
-	var permission models.Permission
-	
-	if err := r.db.First(&role, roleID).Error; err != nil {
-		return err
+func (r *RoleRepository) GetGrant(roleID, permissionID uint, scope models.ResourceScope) (*models.RolePermission, error) {
+	var grant models.RolePermission
+	err := r.db.Where("role_id = ? AND perm_id = ? AND resource_type = ? AND resource_id = ?",
+		roleID, permissionID, scope.ResourceType, scope.ResourceID).
+		Preload("Permission").
+		First(&grant).Error
+	if err != nil {
+		return nil, err
 	}
-	
-	if err := r.db.First(&permission, permissionID).Error; err != nil {
-		return err
+	return &grant, nil
+}
+
+func (r *RoleRepository) AddPermission(grant *models.RolePermission) error {
+	return r.db.Create(grant).Error
+}
+
+func (r *RoleRepository) RemovePermission(roleID, permissionID uint, scope models.ResourceScope) error {
+	result := r.db.Where("role_id = ? AND perm_id = ? AND resource_type = ? AND resource_id = ?",
+		roleID, permissionID, scope.ResourceType, scope.ResourceID).
+		Delete(&models.RolePermission{})
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return gorm.ErrRecordNotFound
 	}
-	
-	return r.db.Model(&role).Association("Permissions").Append(&permission)
+	return nil
 }
 
-func (r *RoleRepository) RemovePermission(roleID, permissionID uint) error {
-	var role models.Role
-	var permission models.Permission
-	
-	if err := r.db.First(&role, roleID).Error; err != nil {
-		return err
+func (r *RoleRepository) GetParents(roleID uint) ([]models.Role, error) {
+	var parents []models.Role
+	err := r.db.Joins("JOIN role_parents ON role_parents.parent_id = roles.role_id").
+		Where("role_parents.role_id = ?", roleID).
+		Order("roles.role_id").
+		Find(&parents).Error
+	return parents, err
+}
+
+// AddParent makes roleID inherit from parentID. The hierarchy is locked while the new edge is
+// checked so that concurrent writes cannot close a cycle or make it deeper than
+// MaxRoleHierarchyDepth, past which resolution would not see the grants.
+func (r *RoleRepository) AddParent(roleID, parentID uint) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Exec("LOCK TABLE role_parents IN SHARE ROW EXCLUSIVE MODE").Error; err != nil {
+			return err
+		}
+
+		if roleID == parentID {
+			return ErrRoleHierarchyCycle
+		}
+
+		// roleID must not already be an ancestor of parentID. The walk is not bounded by depth:
+		// UNION stops it once no new ancestor turns up.
+		var cycles int64
+		err := tx.Raw(`
+			WITH RECURSIVE ancestors(role_id) AS (
+				SELECT parent_id FROM role_parents WHERE role_id = ?
+				UNION
+				SELECT rp.parent_id
+				FROM role_parents rp
+				JOIN ancestors a ON rp.role_id = a.role_id
+			)
+			SELECT COUNT(*) FROM ancestors WHERE role_id = ?`, parentID, roleID).
+			Scan(&cycles).Error
+		if err != nil {
+			return err
+		}
+		if cycles > 0 {
+			return ErrRoleHierarchyCycle
+		}
+
+		// The longest chain through the new edge runs from the deepest role inheriting from
+		// roleID to the furthest ancestor of parentID
+		var depth int
+		err = tx.Raw(`
+			WITH RECURSIVE ancestors(role_id, depth) AS (
+				SELECT CAST(? AS bigint), 0
+				UNION
+				SELECT rp.parent_id, a.depth + 1
+				FROM role_parents rp
+				JOIN ancestors a ON rp.role_id = a.role_id
+				WHERE a.depth < ?
+			), descendants(role_id, depth) AS (
+				SELECT CAST(? AS bigint), 0
+				UNION
+				SELECT rp.role_id, d.depth + 1
+				FROM role_parents rp
+				JOIN descendants d ON rp.parent_id = d.role_id
+				WHERE d.depth < ?
+			)
+			SELECT (SELECT MAX(depth) FROM ancestors) + (SELECT MAX(depth) FROM descendants) + 1`,
+			parentID, MaxRoleHierarchyDepth, roleID, MaxRoleHierarchyDepth).
+			Scan(&depth).Error
+		if err != nil {
+			return err
+		}
+		if depth > MaxRoleHierarchyDepth {
+			return ErrRoleHierarchyTooDeep
+		}
+
+		return tx.Create(&models.RoleParent{RoleID: roleID, ParentID: parentID}).Error
+	})
+}
+
+func (r *RoleRepository) RemoveParent(roleID, parentID uint) error {
+	result := r.db.Where("role_id = ? AND parent_id = ?", roleID, parentID).Delete(&models.RoleParent{})
+	if result.Error != nil {
+		return result.Error
 	}
-	
-	if err := r.db.First(&permission, permissionID).Error; err != nil {
-		return err
+	if result.RowsAffected == 0 {
+		return gorm.ErrRecordNotFound
 	}
-	
-	return r.db.Model(&role).Association("Permissions").Delete(&permission)
-}
\ No newline at end of file
+	return nil
+}
+
+// GetInheritedPermissions resolves the grants a role receives from its ancestors, attributing
+// each to the nearest ancestor that carries it. Grants the role carries itself are excluded;
+// allow and deny grants of the same permission and scope are reported separately.
+func (r *RoleRepository) GetInheritedPermissions(roleID uint) ([]InheritedPermission, error) {
+	var permissions []InheritedPermission
+	err := r.db.Raw(`
+		WITH RECURSIVE ancestors(role_id, depth) AS (
+			SELECT parent_id, 1 FROM role_parents WHERE role_id = ?
+			UNION
+			SELECT rp.parent_id, a.depth + 1
+			FROM role_parents rp
+			JOIN ancestors a ON rp.role_id = a.role_id
+			WHERE a.depth < ?
+		)
+		SELECT DISTINCT ON (p.perm_id, rperm.resource_type, rperm.resource_id, rperm.effect)
+			p.perm_id, p.name AS perm_name, rperm.resource_type, rperm.resource_id, rperm.condition, rperm.effect,
+			sr.role_id AS source_role_id, sr.name AS source_role_name
+		FROM ancestors a
+		JOIN role_permission rperm ON rperm.role_id = a.role_id
+		JOIN permissions p ON p.perm_id = rperm.perm_id
+		JOIN roles sr ON sr.role_id = a.role_id
+		WHERE NOT EXISTS (
+			SELECT 1 FROM role_permission own
+			WHERE own.role_id = ? AND own.perm_id = rperm.perm_id
+				AND own.resource_type = rperm.resource_type AND own.resource_id = rperm.resource_id
+				AND own.effect = rperm.effect
+		)
+		ORDER BY p.perm_id, rperm.resource_type, rperm.resource_id, rperm.effect, a.depth, sr.role_id`, roleID, MaxRoleHierarchyDepth, roleID).
+		Scan(&permissions).Error
+	return permissions, err
+}
+
+// GetInheritingUserIDs returns the users who hold the role or any role that inherits from it,
+// i.e. every user whose permissions change with the role
+func (r *RoleRepository) GetInheritingUserIDs(roleID uint) ([]string, error) {
+	var userIDs []string
+	err := r.db.Raw(`
+		WITH RECURSIVE descendants(role_id, depth) AS (
+			SELECT role_id, 0 FROM roles WHERE role_id = ?
+			UNION
+			SELECT rp.role_id, d.depth + 1
+			FROM role_parents rp
+			JOIN descendants d ON rp.parent_id = d.role_id
+			WHERE d.depth < ?
+		)
+		SELECT DISTINCT ur.user_id
+		FROM user_roles ur
+		JOIN descendants d ON d.role_id = ur.role_id
+		ORDER BY ur.user_id`, roleID, MaxRoleHierarchyDepth).
+		Scan(&userIDs).Error
+	return userIDs, err
+}
@@ -0,0 +1,59 @@
+package repositories
+
+import (
+	"gin/internal/models"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+type UserAttributeRepositoryInterface interface {
+	GetByUserID(userID string) ([]models.UserAttribute, error)
+	GetByUserIDs(userIDs []string) ([]models.UserAttribute, error)
+	Upsert(attributes []models.UserAttribute) error
+	Delete(userID, key string) error
+}
+
+type UserAttributeRepository struct {
+	db *gorm.DB
+}
+
+func NewUserAttributeRepository(db *gorm.DB) UserAttributeRepositoryInterface {
+	return &UserAttributeRepository{db: db}
+}
+
+func (u *UserAttributeRepository) GetByUserID(userID string) ([]models.UserAttribute, error) {
+	var attributes []models.UserAttribute
+	err := u.db.Where("user_id = ?", userID).Order("key").Find(&attributes).Error
+	return attributes, err
+}
+
+func (u *UserAttributeRepository) GetByUserIDs(userIDs []string) ([]models.UserAttribute, error) {
+	var attributes []models.UserAttribute
+	if len(userIDs) == 0 {
+		return attributes, nil
+	}
+	err := u.db.Where("user_id IN ?", userIDs).Find(&attributes).Error
+	return attributes, err
+}
+
+func (u *UserAttributeRepository) Upsert(attributes []models.UserAttribute) error {
+	if len(attributes) == 0 {
+		return nil
+	}
+	return u.db.Clauses(clause.OnConflict{
+		Columns:   []clause.Column{{Name: "user_id"}, {Name: "key"}},
+		DoUpdates: clause.AssignmentColumns([]string{"value", "updated_at"}),
+	}).Create(&attributes).Error
+}
+
+func (u *UserAttributeRepository) Delete(userID, key string) error {
+	result := u.db.Where("user_id = ? AND key = ?", userID, key).Delete(&models.UserAttribute{})
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return gorm.ErrRecordNotFound
+	}
+	return nil
+}
@@ -5,21 +5,49 @@ import (
 	"time"
 
 	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
 )
 
+// MatchedBan is a ban that covers a permission, either directly or through a wildcard
+type MatchedBan struct {
+	PermID uint
+	Ban    models.UserBan
+}
+
+// userBanLockKey identifies the advisory locks that serialise the bans issued to a user
+const userBanLockKey = 0x62616e73 // "bans"
+
+// activeBanCondition matches bans that have been neither lifted nor finalised and have not run out
+// by the given time
+const activeBanCondition = "lifted_at IS NULL AND expired_at IS NULL AND (expires_at IS NULL OR expires_at > ?)"
+
+// UserBanFilter narrows a ban listing; empty fields match every ban
+type UserBanFilter struct {
+	// Type is models.BanTypeGlobal or models.BanTypePermission
+	Type       string
+	ReasonCode string
+}
+
 type UserBanRepositoryInterface interface {
 	Create(userBan *models.UserBan) error
 	GetByID(id uint) (*models.UserBan, error)
 	GetByUserID(userID string) ([]models.UserBan, error)
-	GetByUserIDAndPermission(userID string, permID uint) (*models.UserBan, error)
+	GetByUserIDAndPermission(userID string, permID uint, scope models.ResourceScope, kind string) (*models.UserBan, error)
+	GetGlobalBan(userID string, scope models.ResourceScope, kind string) (*models.UserBan, error)
+	GetMatchingBans(userIDs []string, permIDs []uint) ([]MatchedBan, error)
 	GetAll() ([]models.UserBan, error)
+	Find(filter UserBanFilter) ([]models.UserBan, error)
 	Update(userBan *models.UserBan) error
 	Delete(id uint) error
+	Lift(id uint, liftedBy, reason string, now time.Time) error
 	GetWithPermission(id uint) (*models.UserBan, error)
 	GetActiveUserBans(userID string) ([]models.UserBan, error)
 	IsUserBanned(userID string, permID uint) (bool, error)
 	BanUser(userID string, permID uint, reason string) error
 	UnbanUser(userID string, permID uint) error
+	ExpireBans(now time.Time) ([]models.UserBan, error)
+	GetStrikeHistory(userID string, since time.Time) ([]models.UserBan, error)
+	LockUser(userID string) error
 }
 
 type UserBanRepository struct {
@@ -43,38 +71,142 @@ func (u *UserBanRepository) GetByID(id uint) (*models.UserBan, error) {
 	return &userBan, nil
 }
 
+// GetByUserID returns every ban of a user, including lifted and expired ones, newest first
 func (u *UserBanRepository) GetByUserID(userID string) ([]models.UserBan, error) {
 	var userBans []models.UserBan
-	err := u.db.Where("user_id = ?", userID).Find(&userBans).Error
+	err := u.db.Where("user_id = ?", userID).
+		Preload("Permission").Preload("Exemptions").Preload("BanReason").
+		Order("created_at DESC, id DESC").
+		Find(&userBans).Error
 	return userBans, err
 }
 
-func (u *UserBanRepository) GetByUserIDAndPermission(userID string, permID uint) (*models.UserBan, error) {
+// GetByUserIDAndPermission returns the active ban of a user on a permission in the given scope.
+// An empty kind matches a ban of any kind.
+func (u *UserBanRepository) GetByUserIDAndPermission(userID string, permID uint, scope models.ResourceScope, kind string) (*models.UserBan, error) {
 	var userBan models.UserBan
-	err := u.db.Where("user_id = ? AND perm_id = ?", userID, permID).First(&userBan).Error
+	query := u.db.Where("user_id = ? AND perm_id = ? AND resource_type = ? AND resource_id = ?",
+		userID, permID, scope.ResourceType, scope.ResourceID).
+		Where(activeBanCondition, time.Now())
+	if kind != "" {
+		query = query.Where("kind = ?", kind)
+	}
+	err := query.Order("id DESC").First(&userBan).Error
+	if err != nil {
+		return nil, err
+	}
+	return &userBan, nil
+}
+
+// GetGlobalBan returns the active global ban of a user in the given scope.
+// An empty kind matches a ban of any kind.
+func (u *UserBanRepository) GetGlobalBan(userID string, scope models.ResourceScope, kind string) (*models.UserBan, error) {
+	var userBan models.UserBan
+	query := u.db.Where("user_id = ? AND perm_id IS NULL AND resource_type = ? AND resource_id = ?",
+		userID, scope.ResourceType, scope.ResourceID).
+		Where(activeBanCondition, time.Now())
+	if kind != "" {
+		query = query.Where("kind = ?", kind)
+	}
+	err := query.Preload("Exemptions").Order("id DESC").First(&userBan).Error
 	if err != nil {
 		return nil, err
 	}
 	return &userBan, nil
 }
 
+// GetMatchingBans returns the active bans of the given users that cover the given permissions,
+// including bans on wildcard permissions and global bans that do not exempt them, in any
+// resource scope. Callers narrow the result with
+// models.ResourceScope.Covers. A nil permIDs matches against every permission.
+func (u *UserBanRepository) GetMatchingBans(userIDs []string, permIDs []uint) ([]MatchedBan, error) {
+	var matches []MatchedBan
+	if len(userIDs) == 0 || (permIDs != nil && len(permIDs) == 0) {
+		return matches, nil
+	}
+
+	var rows []struct {
+		BanID  uint
+		PermID uint
+	}
+
+	query := u.db.Table("user_bans ub").
+		Select("ub.id AS ban_id, p.perm_id").
+		Joins("LEFT JOIN permissions bp ON bp.perm_id = ub.perm_id").
+		Joins("JOIN permissions p ON ub.perm_id IS NULL OR "+permissionMatchSQL("bp", "p")).
+		Where("ub.user_id IN ?", userIDs).
+		Where(`NOT EXISTS (
+			SELECT 1 FROM user_ban_exemptions ex
+			JOIN permissions ep ON ep.perm_id = ex.perm_id
+			WHERE ex.ban_id = ub.id AND `+permissionMatchSQL("ep", "p")+`)`).
+		Where("ub.lifted_at IS NULL AND ub.expired_at IS NULL AND (ub.expires_at IS NULL OR ub.expires_at > ?)", time.Now())
+
+	if permIDs != nil {
+		query = query.Where("p.perm_id IN ?", permIDs)
+	}
+
+	if err := query.Order("p.perm_id, ub.perm_id IS NULL DESC, ub.resource_type = '' DESC, ub.id").Scan(&rows).Error; err != nil {
+		return nil, err
+	}
+	if len(rows) == 0 {
+		return matches, nil
+	}
+
+	banIDs := make([]uint, 0, len(rows))
+	for _, row := range rows {
+		banIDs = append(banIDs, row.BanID)
+	}
+
+	var userBans []models.UserBan
+	if err := u.db.Preload("Permission").Preload("Exemptions").Preload("BanReason").Where("id IN ?", banIDs).Find(&userBans).Error; err != nil {
+		return nil, err
+	}
+
+	banByID := make(map[uint]models.UserBan, len(userBans))
+	for _, userBan := range userBans {
+		banByID[userBan.ID] = userBan
+	}
+
+	for _, row := range rows {
+		matches = append(matches, MatchedBan{PermID: row.PermID, Ban: banByID[row.BanID]})
+	}
+	return matches, nil
+}
+
 func (u *UserBanRepository) GetAll() ([]models.UserBan, error) {
 	var userBans []models.UserBan
 	err := u.db.Find(&userBans).Error
 	return userBans, err
 }
 
+func (u *UserBanRepository) Find(filter UserBanFilter) ([]models.UserBan, error) {
+	var userBans []models.UserBan
+	query := u.db.Preload("Exemptions").Preload("BanReason")
+	switch filter.Type {
+	case models.BanTypeGlobal:
+		query = query.Where("perm_id IS NULL")
+	case models.BanTypePermission:
+		query = query.Where("perm_id IS NOT NULL")
+	}
+	if filter.ReasonCode != "" {
+		query = query.Where("reason_code = ?", filter.ReasonCode)
+	}
+	err := query.Order("id").Find(&userBans).Error
+	return userBans, err
+}
+
 func (u *UserBanRepository) Update(userBan *models.UserBan) error {
 	return u.db.Save(userBan).Error
 }
 
+// Delete removes a ban together with its exemptions
 func (u *UserBanRepository) Delete(id uint) error {
-	return u.db.Delete(&models.UserBan{}, id).Error
+	return u.db.Select("Exemptions").Delete(&models.UserBan{ID: id}).Error
 }
 
 func (u *UserBanRepository) GetWithPermission(id uint) (*models.UserBan, error) {
 	var userBan models.UserBan
-	err := u.db.Preload("Permission").First(&userBan, id).Error
+	err := u.db.Preload("Permission").Preload("Exemptions").Preload("BanReason").First(&userBan, id).Error
 	if err != nil {
 		return nil, err
 	}
@@ -83,20 +215,23 @@ func (u *UserBanRepository) GetWithPermission(id uint) (*models.UserBan, error)
 
 func (u *UserBanRepository) GetActiveUserBans(userID string) ([]models.UserBan, error) {
 	var userBans []models.UserBan
-	err := u.db.Where("user_id = ?", userID).Preload("Permission").Find(&userBans).Error
+	err := u.db.Where("user_id = ?", userID).Where(activeBanCondition, time.Now()).
+		Preload("Permission").Preload("Exemptions").Preload("BanReason").Find(&userBans).Error
 	return userBans, err
 }
 
 func (u *UserBanRepository) IsUserBanned(userID string, permID uint) (bool, error) {
 	var count int64
-	err := u.db.Model(&models.UserBan{}).Where("user_id = ? AND perm_id = ?", userID, permID).Count(&count).Error
+	err := u.db.Model(&models.UserBan{}).Where("user_id = ? AND perm_id = ?", userID, permID).
+		Where("kind IN ?", []string{models.BanKindMute, models.BanKindSuspension}).
+		Where(activeBanCondition, time.Now()).Count(&count).Error
 	return count > 0, err
 }
 
 func (u *UserBanRepository) BanUser(userID string, permID uint, reason string) error {
 	userBan := &models.UserBan{
 		UserID:    userID,
-		PermID:    permID,
+		PermID:    &permID,
 		Reason:    reason,
 		CreatedAt: time.Now(),
 		UpdatedAt: time.Now(),
@@ -105,21 +240,72 @@ func (u *UserBanRepository) BanUser(userID string, permID uint, reason string) e
 }
 
 func (u *UserBanRepository) UnbanUser(userID string, permID uint) error {
-	return u.db.Where("user_id = ? AND perm_id = ?", userID, permID).Delete(&models.UserBan{}).Error
+	now := time.Now()
+	return u.db.Model(&models.UserBan{}).
+		Where("user_id = ? AND perm_id = ?", userID, permID).
+		Where(activeBanCondition, now).
+		Updates(map[string]interface{}{"lifted_at": now, "updated_at": now}).Error
+}
+
+// Lift ends an active ban early and keeps it as history. gorm.ErrRecordNotFound is returned when
+// the ban does not exist or is no longer active.
+func (u *UserBanRepository) Lift(id uint, liftedBy, reason string, now time.Time) error {
+	result := u.db.Model(&models.UserBan{}).
+		Where("id = ?", id).
+		Where(activeBanCondition, now).
+		Updates(map[string]interface{}{
+			"lifted_at":   now,
+			"lifted_by":   liftedBy,
+			"lift_reason": reason,
+			"updated_at":  now,
+		})
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return gorm.ErrRecordNotFound
+	}
+	return nil
+}
+
+// ExpireBans finalises every temporary ban that has run out by now and returns the bans it touched
+func (u *UserBanRepository) ExpireBans(now time.Time) ([]models.UserBan, error) {
+	var expired []models.UserBan
+	err := u.db.Model(&expired).
+		Clauses(clause.Returning{}).
+		Where("lifted_at IS NULL AND expired_at IS NULL AND expires_at <= ?", now).
+		Updates(map[string]interface{}{"expired_at": now, "updated_at": now}).Error
+	return expired, err
 }
 
 func (u *UserBanRepository) GetRecentBans(limit int) ([]models.UserBan, error) {
 	var userBans []models.UserBan
 	thirtyDaysAgo := time.Now().AddDate(0, 0, -30)
-	
+
 	query := u.db.Where("created_at > ?", thirtyDaysAgo).
 		Preload("Permission").
 		Order("created_at DESC")
-	
+
 	if limit > 0 {
 		query = query.Limit(limit)
 	}
-	
+
 	err := query.Find(&userBans).Error
 	return userBans, err
-}
\ No newline at end of file
+}
+
+// GetStrikeHistory returns the bans of a user carrying strike points created since the given time,
+// whether active or expired. Lifted bans are left out, so a ban overturned on appeal stops counting.
+func (u *UserBanRepository) GetStrikeHistory(userID string, since time.Time) ([]models.UserBan, error) {
+	var userBans []models.UserBan
+	err := u.db.Where("user_id = ? AND strike_points > 0 AND created_at >= ? AND lifted_at IS NULL", userID, since).
+		Order("created_at").
+		Find(&userBans).Error
+	return userBans, err
+}
+
+// LockUser serialises ban issuance for a user until the surrounding transaction ends, so that
+// concurrent bans see each other's strike points and duplicates. It must run in a transaction.
+func (u *UserBanRepository) LockUser(userID string) error {
+	return u.db.Exec("SELECT pg_advisory_xact_lock(?, hashtext(?))", userBanLockKey, userID).Error
+}
@@ -0,0 +1,111 @@
+package repositories
+
+import (
+	"gin/internal/models"
+
+	"gorm.io/gorm"
+)
+
+// PermissionGrant is a permission a user holds through one of their roles. SourceRoleID is the
+// role the permission is attached to, which differs from RoleID when it is inherited.
+type PermissionGrant struct {
+	UserID         string
+	PermID         uint
+	PermName       string
+	RoleID         uint
+	RoleName       string
+	SourceRoleID   uint
+	SourceRoleName string
+	// GrantedAs is the name of the granted permission, a wildcard when it covers PermName
+	GrantedAs    string
+	ResourceType string
+	ResourceID   string
+	Condition    string
+	Effect       string
+}
+
+type UserRoleRepositoryInterface interface {
+	Create(userRole *models.UserRole) error
+	GetByUserID(userID string) ([]models.UserRole, error)
+	GetByUserIDAndRole(userID string, roleID uint) (*models.UserRole, error)
+	GetByRoleID(roleID uint) ([]models.UserRole, error)
+	Delete(id uint) error
+	GetPermissionGrants(userIDs []string, permIDs []uint) ([]PermissionGrant, error)
+}
+
+type UserRoleRepository struct {
+	db *gorm.DB
+}
+
+func NewUserRoleRepository(db *gorm.DB) UserRoleRepositoryInterface {
+	return &UserRoleRepository{db: db}
+}
+
+func (u *UserRoleRepository) Create(userRole *models.UserRole) error {
+	return u.db.Create(userRole).Error
+}
+
+func (u *UserRoleRepository) GetByUserID(userID string) ([]models.UserRole, error) {
+	var userRoles []models.UserRole
+	err := u.db.Where("user_id = ?", userID).Preload("Role").Order("role_id").Find(&userRoles).Error
+	return userRoles, err
+}
+
+func (u *UserRoleRepository) GetByUserIDAndRole(userID string, roleID uint) (*models.UserRole, error) {
+	var userRole models.UserRole
+	err := u.db.Where("user_id = ? AND role_id = ?", userID, roleID).First(&userRole).Error
+	if err != nil {
+		return nil, err
+	}
+	return &userRole, nil
+}
+
+func (u *UserRoleRepository) GetByRoleID(roleID uint) ([]models.UserRole, error) {
+	var userRoles []models.UserRole
+	err := u.db.Where("role_id = ?", roleID).Preload("Role").Order("user_id").Find(&userRoles).Error
+	return userRoles, err
+}
+
+func (u *UserRoleRepository) Delete(id uint) error {
+	return u.db.Delete(&models.UserRole{}, id).Error
+}
+
+// GetPermissionGrants returns, in a single query, every grant of the given permissions
+// to the given users through their roles and the ancestors of those roles, including grants
+// of wildcard permissions that cover them. A nil permIDs returns grants of every permission.
+// Direct grants are ordered first.
+func (u *UserRoleRepository) GetPermissionGrants(userIDs []string, permIDs []uint) ([]PermissionGrant, error) {
+	var grants []PermissionGrant
+	if len(userIDs) == 0 || (permIDs != nil && len(permIDs) == 0) {
+		return grants, nil
+	}
+
+	permFilter := "TRUE"
+	args := []interface{}{userIDs, MaxRoleHierarchyDepth}
+	if permIDs != nil {
+		permFilter = "p.perm_id IN ?"
+		args = append(args, permIDs)
+	}
+
+	err := u.db.Raw(`
+		WITH RECURSIVE role_tree(user_id, role_id, source_role_id, depth) AS (
+			SELECT user_id, role_id, role_id, 0 FROM user_roles WHERE user_id IN ?
+			UNION
+			SELECT rt.user_id, rt.role_id, rp.parent_id, rt.depth + 1
+			FROM role_tree rt
+			JOIN role_parents rp ON rp.role_id = rt.source_role_id
+			WHERE rt.depth < ?
+		)
+		SELECT rt.user_id, p.perm_id, p.name AS perm_name,
+			r.role_id, r.name AS role_name, sr.role_id AS source_role_id, sr.name AS source_role_name,
+			gp.name AS granted_as, rperm.resource_type, rperm.resource_id, rperm.condition, rperm.effect
+		FROM role_tree rt
+		JOIN role_permission rperm ON rperm.role_id = rt.source_role_id
+		JOIN permissions gp ON gp.perm_id = rperm.perm_id
+		JOIN permissions p ON `+permissionMatchSQL("gp", "p")+`
+		JOIN roles r ON r.role_id = rt.role_id
+		JOIN roles sr ON sr.role_id = rt.source_role_id
+		WHERE `+permFilter+`
+		ORDER BY p.perm_id, rperm.condition = '' DESC, rperm.resource_type = '' DESC, rt.depth, gp.perm_id <> p.perm_id, r.role_id, sr.role_id`, args...).Scan(&grants).Error
+	return grants, err
+}
@@ -0,0 +1,88 @@
+// Package requestctx carries who made a request and which request it was through a context,
+// so that services can attribute the changes they make.
+package requestctx
+
+import "context"
+
+// Actors used when a change is not made on behalf of a caller
+const (
+	ActorAnonymous = "anonymous"
+	ActorSystem    = "system"
+)
+
+type contextKey int
+
+const (
+	actorKey contextKey = iota
+	requestIDKey
+	subjectKey
+	claimsKey
+	apiKeyScopesKey
+	selfOnlyKey
+)
+
+// WithActor returns a context that attributes changes to actor
+func WithActor(ctx context.Context, actor string) context.Context {
+	return context.WithValue(ctx, actorKey, actor)
+}
+
+// Actor returns the actor of the context, or ActorAnonymous when none is set
+func Actor(ctx context.Context) string {
+	if actor, ok := ctx.Value(actorKey).(string); ok && actor != "" {
+		return actor
+	}
+	return ActorAnonymous
+}
+
+// WithRequestID returns a context that carries the ID of the request being served
+func WithRequestID(ctx context.Context, requestID string) context.Context {
+	return context.WithValue(ctx, requestIDKey, requestID)
+}
+
+// RequestID returns the request ID of the context, or an empty string
+func RequestID(ctx context.Context) string {
+	requestID, _ := ctx.Value(requestIDKey).(string)
+	return requestID
+}
+
+// WithSubject returns a context carrying the authenticated subject and the claims of its token
+func WithSubject(ctx context.Context, subject string, claims map[string]interface{}) context.Context {
+	ctx = context.WithValue(ctx, subjectKey, subject)
+	return context.WithValue(ctx, claimsKey, claims)
+}
+
+// Subject returns the authenticated subject of the context, or an empty string
+func Subject(ctx context.Context) string {
+	subject, _ := ctx.Value(subjectKey).(string)
+	return subject
+}
+
+// Claims returns the token claims of the authenticated subject, or nil
+func Claims(ctx context.Context) map[string]interface{} {
+	claims, _ := ctx.Value(claimsKey).(map[string]interface{})
+	return claims
+}
+
+// WithAPIKeyScopes marks the context as authenticated by an API key limited to scopes
+func WithAPIKeyScopes(ctx context.Context, scopes []string) context.Context {
+	return context.WithValue(ctx, apiKeyScopesKey, scopes)
+}
+
+// APIKeyScopes returns the scopes of the API key that authenticated the context, and false when
+// the caller did not use an API key
+func APIKeyScopes(ctx context.Context) ([]string, bool) {
+	scopes, ok := ctx.Value(apiKeyScopesKey).([]string)
+	return scopes, ok
+}
+
+// WithSelfOnly marks the context as a user acting on their own data without the permission that
+// covers other users' data, so that what only moderators may see is withheld from them
+func WithSelfOnly(ctx context.Context) context.Context {
+	return context.WithValue(ctx, selfOnlyKey, true)
+}
+
+// SelfOnly reports whether the context was marked by WithSelfOnly
+func SelfOnly(ctx context.Context) bool {
+	selfOnly, _ := ctx.Value(selfOnlyKey).(bool)
+	return selfOnly
+}
@@ -0,0 +1,291 @@
+package services
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"fmt"
+	"gin/internal/models"
+	"gin/internal/repositories"
+	"gin/internal/requestctx"
+	"strings"
+	"time"
+)
+
+// apiKeyTouchInterval limits how often a key's last-used time is written
+const apiKeyTouchInterval = time.Minute
+
+// MaxAPIKeyRotationGrace bounds how long a rotated key keeps working alongside its replacement
+const MaxAPIKeyRotationGrace = 7 * 24 * time.Hour
+
+// IssuedAPIKey is a newly created key together with the secret, which is never shown again
+type IssuedAPIKey struct {
+	Key    *models.APIKey
+	Secret string
+}
+
+// APIKeyServiceInterface manages the keys services use to call the API
+type APIKeyServiceInterface interface {
+	CreateKey(ctx context.Context, name string, scopes []string, expiresIn time.Duration) (*IssuedAPIKey, error)
+	GetKeys() ([]models.APIKey, error)
+	GetKey(id uint) (*models.APIKey, error)
+	RevokeKey(ctx context.Context, id uint) error
+	RotateKey(ctx context.Context, id uint, grace time.Duration) (*IssuedAPIKey, error)
+	Authenticate(secret string) (*models.APIKey, error)
+}
+
+type APIKeyService struct {
+	apiKeyRepo           repositories.APIKeyRepositoryInterface
+	authorizationService AuthorizationServiceInterface
+	transactor           repositories.Transactor
+}
+
+func NewAPIKeyService(
+	apiKeyRepo repositories.APIKeyRepositoryInterface,
+	authorizationService AuthorizationServiceInterface,
+	transactor repositories.Transactor,
+) APIKeyServiceInterface {
+	return &APIKeyService{
+		apiKeyRepo:           apiKeyRepo,
+		authorizationService: authorizationService,
+		transactor:           transactor,
+	}
+}
+
+// CreateKey issues a key limited to the given permissions, e.g. "authorization:check" for a
+// check-only caller or "moderation:*" for ban management. A zero expiresIn never expires.
+// The caller must hold every scope it hands on, so a key never grants more than its creator.
+func (s *APIKeyService) CreateKey(ctx context.Context, name string, scopes []string, expiresIn time.Duration) (*IssuedAPIKey, error) {
+	if name == "" {
+		return nil, fmt.Errorf("API key name cannot be empty")
+	}
+
+	if len(scopes) == 0 {
+		return nil, fmt.Errorf("API key needs at least one scope")
+	}
+
+	for _, scope := range scopes {
+		if !models.IsValidPermissionName(scope) {
+			return nil, fmt.Errorf("scope '%s' must be a permission name such as 'authorization:check' or 'moderation:*'", scope)
+		}
+	}
+
+	if expiresIn < 0 {
+		return nil, fmt.Errorf("API key lifetime must be positive")
+	}
+
+	if err := s.checkCallerHolds(ctx, scopes); err != nil {
+		return nil, err
+	}
+
+	issued, err := newAPIKey(name, strings.Join(scopes, " "), requestctx.Actor(ctx))
+	if err != nil {
+		return nil, err
+	}
+
+	if expiresIn > 0 {
+		expiresAt := issued.Key.CreatedAt.Add(expiresIn)
+		issued.Key.ExpiresAt = &expiresAt
+	}
+
+	err = s.transactor.Transaction(func(tx *repositories.Repositories) error {
+		if err := tx.APIKey.Create(issued.Key); err != nil {
+			return fmt.Errorf("failed to create API key: %w", err)
+		}
+		return recordChange(ctx, tx, models.AuditActionAPIKeyCreate, models.AuditTargetAPIKey, auditID(issued.Key.ID), nil, issued.Key)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return issued, nil
+}
+
+func (s *APIKeyService) GetKeys() ([]models.APIKey, error) {
+	return s.apiKeyRepo.GetAll()
+}
+
+func (s *APIKeyService) GetKey(id uint) (*models.APIKey, error) {
+	if id == 0 {
+		return nil, fmt.Errorf("invalid API key ID")
+	}
+
+	key, err := s.apiKeyRepo.GetByID(id)
+	if err != nil {
+		return nil, fmt.Errorf("API key not found: %w", err)
+	}
+
+	return key, nil
+}
+
+// RevokeKey stops a key from authenticating; the key is kept for the record
+func (s *APIKeyService) RevokeKey(ctx context.Context, id uint) error {
+	key, err := s.GetKey(id)
+	if err != nil {
+		return err
+	}
+
+	now := time.Now()
+	return s.transactor.Transaction(func(tx *repositories.Repositories) error {
+		if err := tx.APIKey.Revoke(id, now); err != nil {
+			return fmt.Errorf("API key is already revoked: %w", err)
+		}
+		after := *key
+		after.RevokedAt = &now
+		return recordChange(ctx, tx, models.AuditActionAPIKeyRevoke, models.AuditTargetAPIKey, auditID(id), key, after)
+	})
+}
+
+// RotateKey issues a replacement with the same name, scopes and lifetime. The old key keeps
+// working for the grace period, so callers can switch over, and is revoked at once without one.
+// As with CreateKey, the caller must hold every scope of the key.
+func (s *APIKeyService) RotateKey(ctx context.Context, id uint, grace time.Duration) (*IssuedAPIKey, error) {
+	if grace < 0 || grace > MaxAPIKeyRotationGrace {
+		return nil, fmt.Errorf("rotation grace period must be between 0 and %s", FormatBanDuration(MaxAPIKeyRotationGrace))
+	}
+
+	old, err := s.GetKey(id)
+	if err != nil {
+		return nil, err
+	}
+
+	now := time.Now()
+	if !old.IsActive(now) {
+		return nil, fmt.Errorf("only active API keys can be rotated")
+	}
+
+	if err := s.checkCallerHolds(ctx, old.ScopeList()); err != nil {
+		return nil, err
+	}
+
+	issued, err := newAPIKey(old.Name, old.Scopes, requestctx.Actor(ctx))
+	if err != nil {
+		return nil, err
+	}
+	issued.Key.RotatedFrom = &old.ID
+	if old.ExpiresAt != nil {
+		expiresAt := issued.Key.CreatedAt.Add(old.ExpiresAt.Sub(old.CreatedAt))
+		issued.Key.ExpiresAt = &expiresAt
+	}
+
+	err = s.transactor.Transaction(func(tx *repositories.Repositories) error {
+		before := *old
+		if grace > 0 {
+			graceEnds := now.Add(grace)
+			if old.ExpiresAt == nil || old.ExpiresAt.After(graceEnds) {
+				old.ExpiresAt = &graceEnds
+			}
+			if err := tx.APIKey.Update(old); err != nil {
+				return fmt.Errorf("failed to rotate API key: %w", err)
+			}
+		} else {
+			if err := tx.APIKey.Revoke(old.ID, now); err != nil {
+				return fmt.Errorf("failed to rotate API key: %w", err)
+			}
+			old.RevokedAt = &now
+		}
+
+		if err := tx.APIKey.Create(issued.Key); err != nil {
+			return fmt.Errorf("failed to create API key: %w", err)
+		}
+
+		if err := recordChange(ctx, tx, models.AuditActionAPIKeyRevoke, models.AuditTargetAPIKey, auditID(old.ID), before, old); err != nil {
+			return err
+		}
+		return recordChange(ctx, tx, models.AuditActionAPIKeyCreate, models.AuditTargetAPIKey, auditID(issued.Key.ID), nil, issued.Key)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return issued, nil
+}
+
+// Authenticate returns the active key matching secret and records that it was used
+func (s *APIKeyService) Authenticate(secret string) (*models.APIKey, error) {
+	if !strings.HasPrefix(secret, models.APIKeyPrefix) {
+		return nil, fmt.Errorf("malformed API key")
+	}
+
+	key, err := s.apiKeyRepo.GetByHash(hashAPIKey(secret))
+	if err != nil {
+		return nil, fmt.Errorf("unknown API key")
+	}
+
+	now := time.Now()
+	if !key.IsActive(now) {
+		return nil, fmt.Errorf("API key is revoked or expired")
+	}
+
+	if key.LastUsedAt == nil || now.Sub(*key.LastUsedAt) >= apiKeyTouchInterval {
+		if err := s.apiKeyRepo.TouchLastUsed(key.ID, now); err != nil {
+			return nil, fmt.Errorf("failed to record API key use: %w", err)
+		}
+		key.LastUsedAt = &now
+	}
+
+	return key, nil
+}
+
+// checkCallerHolds rejects scopes the caller could not use itself: those outside the scopes of
+// the API key it authenticated with, or outside the permissions a user holds through their roles
+func (s *APIKeyService) checkCallerHolds(ctx context.Context, scopes []string) error {
+	held, ok := requestctx.APIKeyScopes(ctx)
+	if !ok {
+		subject := requestctx.Subject(ctx)
+		if subject == "" {
+			return fmt.Errorf("API keys can only be issued to an authenticated caller")
+		}
+
+		effective, err := s.authorizationService.GetEffectivePermissions(subject, models.ResourceScope{})
+		if err != nil {
+			return fmt.Errorf("failed to get caller permissions: %w", err)
+		}
+		for _, permission := range effective {
+			held = append(held, permission.Permission.Name)
+		}
+	}
+
+	for _, scope := range scopes {
+		if !models.ScopesAllow(held, scope) {
+			return fmt.Errorf("scope '%s' exceeds the caller's own permissions", scope)
+		}
+	}
+
+	return nil
+}
+
+// newAPIKey generates a key of the form ak_<prefix>_<secret>; the prefix identifies the key in
+// listings without revealing it
+func newAPIKey(name, scopes, createdBy string) (*IssuedAPIKey, error) {
+	prefix := make([]byte, 4)
+	secret := make([]byte, 32)
+	if _, err := rand.Read(prefix); err != nil {
+		return nil, fmt.Errorf("failed to generate API key: %w", err)
+	}
+	if _, err := rand.Read(secret); err != nil {
+		return nil, fmt.Errorf("failed to generate API key: %w", err)
+	}
+
+	visiblePrefix := models.APIKeyPrefix + hex.EncodeToString(prefix)
+	plaintext := visiblePrefix + "_" + base64.RawURLEncoding.EncodeToString(secret)
+
+	return &IssuedAPIKey{
+		Key: &models.APIKey{
+			Name:      name,
+			Prefix:    visiblePrefix,
+			KeyHash:   hashAPIKey(plaintext),
+			Scopes:    scopes,
+			CreatedBy: createdBy,
+			CreatedAt: time.Now(),
+		},
+		Secret: plaintext,
+	}, nil
+}
+
+func hashAPIKey(secret string) string {
+	sum := sha256.Sum256([]byte(secret))
+	return hex.EncodeToString(sum[:])
+}
@@ -0,0 +1,131 @@
+package services
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"gin/internal/models"
+	"gin/internal/repositories"
+	"gin/internal/requestctx"
+)
+
+type fakeAPIKeyRepository struct {
+	repositories.APIKeyRepositoryInterface
+	keys []*models.APIKey
+}
+
+func (r *fakeAPIKeyRepository) Create(key *models.APIKey) error {
+	key.ID = uint(len(r.keys) + 1)
+	r.keys = append(r.keys, key)
+	return nil
+}
+
+func (r *fakeAPIKeyRepository) GetByID(id uint) (*models.APIKey, error) {
+	key := *r.keys[id-1]
+	return &key, nil
+}
+
+func (r *fakeAPIKeyRepository) Revoke(id uint, now time.Time) error {
+	r.keys[id-1].RevokedAt = &now
+	return nil
+}
+
+type fakeTransactor struct {
+	tx *repositories.Repositories
+}
+
+func (t *fakeTransactor) Transaction(fn func(tx *repositories.Repositories) error) error {
+	return fn(t.tx)
+}
+
+// fakeAuthorizationService grants every user the same permissions
+type fakeAuthorizationService struct {
+	AuthorizationServiceInterface
+	permissions []string
+}
+
+func (s *fakeAuthorizationService) GetEffectivePermissions(userID string, scope models.ResourceScope) ([]EffectivePermission, error) {
+	var effective []EffectivePermission
+	for _, name := range s.permissions {
+		effective = append(effective, EffectivePermission{Permission: models.Permission{Name: name}})
+	}
+	return effective, nil
+}
+
+func newTestAPIKeyService(userPermissions []string) (APIKeyServiceInterface, *fakeAPIKeyRepository) {
+	keys := &fakeAPIKeyRepository{}
+	transactor := &fakeTransactor{tx: &repositories.Repositories{APIKey: keys, AuditLog: &fakeAuditLogRepository{}}}
+	return NewAPIKeyService(keys, &fakeAuthorizationService{permissions: userPermissions}, transactor), keys
+}
+
+func TestCreateKeyLimitsScopesToTheCaller(t *testing.T) {
+	userCtx := requestctx.WithSubject(context.Background(), "alice", nil)
+	keyCtx := requestctx.WithAPIKeyScopes(requestctx.WithSubject(context.Background(), "api_key:1", nil),
+		[]string{"api_key:manage", "moderation:*"})
+
+	tests := []struct {
+		name    string
+		ctx     context.Context
+		scopes  []string
+		wantErr bool
+	}{
+		{name: "user holding the scopes", ctx: userCtx, scopes: []string{"authorization:check", "moderation:ban"}},
+		{name: "user holding a covering wildcard", ctx: userCtx, scopes: []string{"room:*", "room:create"}},
+		{name: "user asking for everything", ctx: userCtx, scopes: []string{"*"}, wantErr: true},
+		{name: "user asking for a wider wildcard", ctx: userCtx, scopes: []string{"moderation:*"}, wantErr: true},
+		{name: "user asking for a permission not held", ctx: userCtx, scopes: []string{"authorization:check", "role:assign"}, wantErr: true},
+		{name: "key within its own scopes", ctx: keyCtx, scopes: []string{"moderation:ban", "moderation:*"}},
+		{name: "key asking for everything", ctx: keyCtx, scopes: []string{"*"}, wantErr: true},
+		{name: "key asking for what its user holds", ctx: keyCtx, scopes: []string{"authorization:check"}, wantErr: true},
+		{name: "unauthenticated caller", ctx: context.Background(), scopes: []string{"authorization:check"}, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			service, keys := newTestAPIKeyService([]string{"authorization:check", "moderation:ban", "room:*", "room:create"})
+
+			issued, err := service.CreateKey(tt.ctx, "ci", tt.scopes, 0)
+			if tt.wantErr {
+				if err == nil {
+					t.Fatalf("CreateKey(%v) error = nil, want an error", tt.scopes)
+				}
+				if len(keys.keys) != 0 {
+					t.Errorf("CreateKey(%v) stored %d keys after an error", tt.scopes, len(keys.keys))
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("CreateKey(%v) error = %v", tt.scopes, err)
+			}
+			if got := issued.Key.ScopeList(); len(got) != len(tt.scopes) {
+				t.Errorf("key scopes = %v, want %v", got, tt.scopes)
+			}
+		})
+	}
+}
+
+func TestRotateKeyRequiresTheKeysScopes(t *testing.T) {
+	admin := requestctx.WithSubject(context.Background(), "admin", nil)
+	service, keys := newTestAPIKeyService([]string{"*"})
+	issued, err := service.CreateKey(admin, "ci", []string{"*"}, 0)
+	if err != nil {
+		t.Fatalf("CreateKey() error = %v", err)
+	}
+
+	manager := requestctx.WithAPIKeyScopes(requestctx.WithSubject(context.Background(), "api_key:9", nil),
+		[]string{"api_key:manage"})
+	if _, err := service.RotateKey(manager, issued.Key.ID, 0); err == nil {
+		t.Fatal("RotateKey() by a narrower key error = nil, want an error")
+	}
+	if keys.keys[0].RevokedAt != nil || len(keys.keys) != 1 {
+		t.Error("RotateKey() changed keys after refusing the rotation")
+	}
+
+	if _, err := service.RotateKey(admin, issued.Key.ID, 0); err != nil {
+		t.Fatalf("RotateKey() by the holder error = %v", err)
+	}
+	if keys.keys[0].RevokedAt == nil || len(keys.keys) != 2 {
+		t.Error("RotateKey() did not revoke the old key and create a replacement")
+	}
+}
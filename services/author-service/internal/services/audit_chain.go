@@ -0,0 +1,231 @@
+package services
+
+import (
+	"context"
+	"crypto/ed25519"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"gin/internal/models"
+	"log"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// DefaultAuditCheckpointInterval is how often the audit chain is checkpointed when no interval is configured
+const DefaultAuditCheckpointInterval = time.Hour
+
+// auditVerifyBatchSize is the number of entries read at once while verifying the chain
+const auditVerifyBatchSize = 1000
+
+// AuditChainBreak is the first place the audit chain fails verification
+type AuditChainBreak struct {
+	EntryID uint
+	Reason  string
+}
+
+// AuditChainReport summarises a verification of the audit chain
+type AuditChainReport struct {
+	Entries int
+	// Unchained counts the leading entries recorded before entries were chained
+	Unchained   int
+	Checkpoints int
+	Break       *AuditChainBreak
+}
+
+// ParseAuditSigningKey decodes a base64 Ed25519 private key or 32-byte seed
+func ParseAuditSigningKey(value string) (ed25519.PrivateKey, error) {
+	raw, err := base64.StdEncoding.DecodeString(value)
+	if err != nil {
+		return nil, fmt.Errorf("invalid audit signing key: %w", err)
+	}
+
+	switch len(raw) {
+	case ed25519.SeedSize:
+		return ed25519.NewKeyFromSeed(raw), nil
+	case ed25519.PrivateKeySize:
+		return ed25519.PrivateKey(raw), nil
+	default:
+		return nil, fmt.Errorf("audit signing key must be a %d-byte seed or %d-byte private key", ed25519.SeedSize, ed25519.PrivateKeySize)
+	}
+}
+
+// ParseAuditVerifyKey decodes a base64 Ed25519 public key
+func ParseAuditVerifyKey(value string) (ed25519.PublicKey, error) {
+	raw, err := base64.StdEncoding.DecodeString(value)
+	if err != nil {
+		return nil, fmt.Errorf("invalid audit verify key: %w", err)
+	}
+
+	if len(raw) != ed25519.PublicKeySize {
+		return nil, fmt.Errorf("audit verify key must be %d bytes", ed25519.PublicKeySize)
+	}
+	return ed25519.PublicKey(raw), nil
+}
+
+// auditKeyID identifies the key a checkpoint was signed with
+func auditKeyID(publicKey ed25519.PublicKey) string {
+	sum := sha256.Sum256(publicKey)
+	return hex.EncodeToString(sum[:8])
+}
+
+// WriteCheckpoint signs the current end of the audit chain. It returns nil when no key is
+// configured or nothing was recorded since the latest checkpoint.
+func (s *AuditLogService) WriteCheckpoint() (*models.AuditCheckpoint, error) {
+	if s.signingKey == nil {
+		return nil, nil
+	}
+
+	last, err := s.auditLogRepo.GetLast()
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get latest audit entry: %w", err)
+	}
+
+	if last.Hash == "" {
+		return nil, nil
+	}
+
+	latest, err := s.auditLogRepo.GetLatestCheckpoint()
+	if err == nil && latest.EntryID == last.ID {
+		return nil, nil
+	}
+	if err != nil && !errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, fmt.Errorf("failed to get latest audit checkpoint: %w", err)
+	}
+
+	signature := ed25519.Sign(s.signingKey, models.CheckpointMessage(last.ID, last.Hash))
+	checkpoint := &models.AuditCheckpoint{
+		EntryID:   last.ID,
+		EntryHash: last.Hash,
+		KeyID:     auditKeyID(s.signingKey.Public().(ed25519.PublicKey)),
+		Signature: base64.StdEncoding.EncodeToString(signature),
+		CreatedAt: time.Now(),
+	}
+
+	if err := s.auditLogRepo.CreateCheckpoint(checkpoint); err != nil {
+		return nil, fmt.Errorf("failed to write audit checkpoint: %w", err)
+	}
+
+	return checkpoint, nil
+}
+
+// VerifyChain walks the audit chain from the first entry and reports the first broken link:
+// an entry whose contents no longer match its hash, an entry not linked to the one before it,
+// or a checkpoint whose entry is missing, differs, or whose signature does not verify.
+// Signatures are only checked when a public key is given.
+func (s *AuditLogService) VerifyChain(publicKey ed25519.PublicKey) (*AuditChainReport, error) {
+	checkpoints, err := s.auditLogRepo.GetCheckpoints()
+	if err != nil {
+		return nil, fmt.Errorf("failed to get audit checkpoints: %w", err)
+	}
+
+	report := &AuditChainReport{Checkpoints: len(checkpoints)}
+
+	var keyID string
+	if publicKey != nil {
+		keyID = auditKeyID(publicKey)
+	}
+
+	checkpointed := make(map[uint][]models.AuditCheckpoint)
+	for _, checkpoint := range checkpoints {
+		checkpointed[checkpoint.EntryID] = append(checkpointed[checkpoint.EntryID], checkpoint)
+	}
+
+	brokenAt := func(entryID uint, format string, args ...interface{}) {
+		if report.Break == nil || entryID < report.Break.EntryID {
+			report.Break = &AuditChainBreak{EntryID: entryID, Reason: fmt.Sprintf(format, args...)}
+		}
+	}
+
+	for _, checkpoint := range checkpoints {
+		if publicKey == nil {
+			continue
+		}
+		if checkpoint.KeyID != keyID {
+			brokenAt(checkpoint.EntryID, "checkpoint %d was signed with key %s, not %s", checkpoint.ID, checkpoint.KeyID, keyID)
+			continue
+		}
+		signature, err := base64.StdEncoding.DecodeString(checkpoint.Signature)
+		if err != nil || !ed25519.Verify(publicKey, models.CheckpointMessage(checkpoint.EntryID, checkpoint.EntryHash), signature) {
+			brokenAt(checkpoint.EntryID, "checkpoint %d has an invalid signature", checkpoint.ID)
+		}
+	}
+
+	var afterID uint
+	prevHash := ""
+	chained := false
+	for report.Break == nil || afterID < report.Break.EntryID {
+		entries, err := s.auditLogRepo.GetAfter(afterID, auditVerifyBatchSize)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read audit log: %w", err)
+		}
+		if len(entries) == 0 {
+			break
+		}
+
+		for _, entry := range entries {
+			afterID = entry.ID
+			report.Entries++
+
+			switch {
+			case entry.Hash == "" && !chained:
+				report.Unchained++
+				continue
+			case entry.PrevHash != prevHash:
+				brokenAt(entry.ID, "entry %d is not linked to the entry before it", entry.ID)
+			case entry.ComputeHash() != entry.Hash:
+				brokenAt(entry.ID, "entry %d does not match its hash", entry.ID)
+			}
+			chained = true
+			prevHash = entry.Hash
+
+			for _, checkpoint := range checkpointed[entry.ID] {
+				if checkpoint.EntryHash != entry.Hash {
+					brokenAt(entry.ID, "entry %d differs from checkpoint %d", entry.ID, checkpoint.ID)
+				}
+			}
+			delete(checkpointed, entry.ID)
+		}
+	}
+
+	for entryID, remaining := range checkpointed {
+		if report.Break != nil && entryID > report.Break.EntryID {
+			continue
+		}
+		brokenAt(entryID, "entry %d named by checkpoint %d is missing", entryID, remaining[0].ID)
+	}
+
+	return report, nil
+}
+
+// RunAuditCheckpointer checkpoints the audit chain every interval until ctx is cancelled
+func RunAuditCheckpointer(ctx context.Context, auditLogService AuditLogServiceInterface, interval time.Duration) {
+	if interval <= 0 {
+		interval = DefaultAuditCheckpointInterval
+	}
+
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			checkpoint, err := auditLogService.WriteCheckpoint()
+			if err != nil {
+				log.Printf("Audit checkpointer: %v", err)
+				continue
+			}
+			if checkpoint != nil {
+				log.Printf("Audit checkpointer: signed chain up to entry %d", checkpoint.EntryID)
+			}
+		}
+	}
+}
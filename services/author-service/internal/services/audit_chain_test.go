@@ -0,0 +1,243 @@
+package services
+
+import (
+	"crypto/ed25519"
+	"strings"
+	"testing"
+	"time"
+
+	"gin/internal/models"
+	"gin/internal/repositories"
+
+	"gorm.io/gorm"
+)
+
+// fakeAuditLogRepository keeps the audit chain in memory, chaining entries as the repository does
+type fakeAuditLogRepository struct {
+	repositories.AuditLogRepositoryInterface
+	entries     []models.AuditLog
+	checkpoints []models.AuditCheckpoint
+}
+
+func (r *fakeAuditLogRepository) Create(entry *models.AuditLog) error {
+	entry.ID = 1
+	if len(r.entries) > 0 {
+		entry.ID = r.entries[len(r.entries)-1].ID + 1
+		entry.PrevHash = r.entries[len(r.entries)-1].Hash
+	}
+	entry.CreatedAt = entry.CreatedAt.Truncate(time.Microsecond)
+	entry.Hash = entry.ComputeHash()
+	r.entries = append(r.entries, *entry)
+	return nil
+}
+
+func (r *fakeAuditLogRepository) GetLast() (*models.AuditLog, error) {
+	if len(r.entries) == 0 {
+		return nil, gorm.ErrRecordNotFound
+	}
+	last := r.entries[len(r.entries)-1]
+	return &last, nil
+}
+
+func (r *fakeAuditLogRepository) GetAfter(afterID uint, limit int) ([]models.AuditLog, error) {
+	var entries []models.AuditLog
+	for _, entry := range r.entries {
+		if entry.ID > afterID && len(entries) < limit {
+			entries = append(entries, entry)
+		}
+	}
+	return entries, nil
+}
+
+func (r *fakeAuditLogRepository) CreateCheckpoint(checkpoint *models.AuditCheckpoint) error {
+	checkpoint.ID = uint(len(r.checkpoints) + 1)
+	r.checkpoints = append(r.checkpoints, *checkpoint)
+	return nil
+}
+
+func (r *fakeAuditLogRepository) GetLatestCheckpoint() (*models.AuditCheckpoint, error) {
+	if len(r.checkpoints) == 0 {
+		return nil, gorm.ErrRecordNotFound
+	}
+	latest := r.checkpoints[len(r.checkpoints)-1]
+	return &latest, nil
+}
+
+func (r *fakeAuditLogRepository) GetCheckpoints() ([]models.AuditCheckpoint, error) {
+	return r.checkpoints, nil
+}
+
+// entry returns the entry with the given ID for tampering
+func (r *fakeAuditLogRepository) entry(id uint) *models.AuditLog {
+	for i := range r.entries {
+		if r.entries[i].ID == id {
+			return &r.entries[i]
+		}
+	}
+	return nil
+}
+
+func (r *fakeAuditLogRepository) remove(ids ...uint) {
+	kept := r.entries[:0]
+	for _, entry := range r.entries {
+		removed := false
+		for _, id := range ids {
+			removed = removed || entry.ID == id
+		}
+		if !removed {
+			kept = append(kept, entry)
+		}
+	}
+	r.entries = kept
+}
+
+func TestVerifyChain(t *testing.T) {
+	seed := make([]byte, ed25519.SeedSize)
+	signingKey := ed25519.NewKeyFromSeed(seed)
+	publicKey := signingKey.Public().(ed25519.PublicKey)
+	otherKey := ed25519.NewKeyFromSeed(append(make([]byte, ed25519.SeedSize-1), 1))
+
+	tests := []struct {
+		name        string
+		tamper      func(r *fakeAuditLogRepository)
+		publicKey   ed25519.PublicKey
+		wantBreakAt uint // 0 when the chain verifies
+		wantReason  string
+		wantEntries int
+	}{
+		{
+			name:        "intact",
+			tamper:      func(r *fakeAuditLogRepository) {},
+			publicKey:   publicKey,
+			wantEntries: 7,
+		},
+		{
+			name:        "entry contents edited",
+			tamper:      func(r *fakeAuditLogRepository) { r.entry(5).Actor = "mallory" },
+			publicKey:   publicKey,
+			wantBreakAt: 5,
+			wantReason:  "does not match its hash",
+		},
+		{
+			name: "entry edited and rehashed",
+			tamper: func(r *fakeAuditLogRepository) {
+				entry := r.entry(4)
+				entry.Actor = "mallory"
+				entry.Hash = entry.ComputeHash()
+			},
+			publicKey:   publicKey,
+			wantBreakAt: 4,
+			wantReason:  "differs from checkpoint",
+		},
+		{
+			name: "entry after the last checkpoint edited and rehashed",
+			tamper: func(r *fakeAuditLogRepository) {
+				entry := r.entry(6)
+				entry.Actor = "mallory"
+				entry.Hash = entry.ComputeHash()
+			},
+			publicKey:   publicKey,
+			wantBreakAt: 7,
+			wantReason:  "not linked",
+		},
+		{
+			name:        "entry removed from the middle",
+			tamper:      func(r *fakeAuditLogRepository) { r.remove(5) },
+			publicKey:   publicKey,
+			wantBreakAt: 6,
+			wantReason:  "not linked",
+		},
+		{
+			name:        "entries removed from the end up to a checkpoint",
+			tamper:      func(r *fakeAuditLogRepository) { r.remove(6, 7) },
+			publicKey:   publicKey,
+			wantBreakAt: 7,
+			wantReason:  "missing",
+		},
+		{
+			name:        "checkpoint forged",
+			tamper:      func(r *fakeAuditLogRepository) { r.checkpoints[0].EntryHash = r.entry(4).PrevHash },
+			publicKey:   publicKey,
+			wantBreakAt: 4,
+			wantReason:  "invalid signature",
+		},
+		{
+			name:        "checkpoint forged, not checked without a key",
+			tamper:      func(r *fakeAuditLogRepository) { r.checkpoints[0].Signature = "AAAA" },
+			wantEntries: 7,
+		},
+		{
+			name:        "checkpoints signed with another key",
+			tamper:      func(r *fakeAuditLogRepository) {},
+			publicKey:   otherKey.Public().(ed25519.PublicKey),
+			wantBreakAt: 4,
+			wantReason:  "signed with key",
+		},
+		{
+			name: "unchained entry after chained ones",
+			tamper: func(r *fakeAuditLogRepository) {
+				entry := r.entry(6)
+				entry.PrevHash = ""
+				entry.Hash = ""
+			},
+			publicKey:   publicKey,
+			wantBreakAt: 6,
+			wantReason:  "not linked",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			repo := &fakeAuditLogRepository{}
+			service := NewAuditLogService(repo, signingKey)
+
+			// Two entries from before the chain, then five chained entries checkpointed
+			// after the second and the fifth
+			createdAt := time.Date(2026, 3, 14, 12, 0, 0, 123456789, time.UTC)
+			for i := 1; i <= 7; i++ {
+				entry := &models.AuditLog{
+					Actor:      "alice",
+					Action:     models.AuditActionRoleCreate,
+					TargetType: models.AuditTargetRole,
+					TargetID:   auditID(uint(i)),
+					CreatedAt:  createdAt.Add(time.Duration(i) * time.Second),
+				}
+				if err := repo.Create(entry); err != nil {
+					t.Fatal(err)
+				}
+				if i <= 2 {
+					repo.entries[i-1].PrevHash = ""
+					repo.entries[i-1].Hash = ""
+				}
+				if i == 4 || i == 7 {
+					if _, err := service.WriteCheckpoint(); err != nil {
+						t.Fatal(err)
+					}
+				}
+			}
+			tt.tamper(repo)
+
+			report, err := service.VerifyChain(tt.publicKey)
+			if err != nil {
+				t.Fatalf("VerifyChain() error = %v", err)
+			}
+
+			if tt.wantBreakAt == 0 {
+				if report.Break != nil {
+					t.Fatalf("VerifyChain() break = %+v, want none", report.Break)
+				}
+				if report.Entries != tt.wantEntries || report.Unchained != 2 || report.Checkpoints != 2 {
+					t.Errorf("VerifyChain() report = %+v, want %d entries, 2 unchained, 2 checkpoints", report, tt.wantEntries)
+				}
+				return
+			}
+
+			if report.Break == nil {
+				t.Fatalf("VerifyChain() break = nil, want one at entry %d", tt.wantBreakAt)
+			}
+			if report.Break.EntryID != tt.wantBreakAt || !strings.Contains(report.Break.Reason, tt.wantReason) {
+				t.Errorf("VerifyChain() break = %+v, want entry %d: %s", report.Break, tt.wantBreakAt, tt.wantReason)
+			}
+		})
+	}
+}
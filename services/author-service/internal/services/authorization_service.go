@@ -0,0 +1,514 @@
+package services
+
+import (
+	"fmt"
+	"gin/internal/conditions"
+	"gin/internal/models"
+	"gin/internal/repositories"
+	"time"
+)
+
+// Decision reasons reported by the authorization service
+const (
+	DecisionGranted         = "granted"
+	DecisionBanned          = "banned"
+	DecisionDeniedByRole    = "denied_by_role"
+	DecisionNoGrant         = "no_grant"
+	DecisionConditionNotMet = "condition_not_met"
+)
+
+// MaxBatchAuthorizationChecks caps the number of checks answered by a single batch request
+const MaxBatchAuthorizationChecks = 500
+
+// AuthorizationQuery asks whether a user holds a permission, identified either by name or by ID
+type AuthorizationQuery struct {
+	UserID         string
+	PermissionName string
+	PermissionID   uint
+	// Scope is the resource the action targets; global grants and bans always apply
+	Scope models.ResourceScope
+	// Attributes are evaluated by grant conditions alongside the attributes stored for the user
+	Attributes map[string]interface{}
+}
+
+// AuthorizationDecision is the outcome of an authorization check and the factor that decided it
+type AuthorizationDecision struct {
+	UserID     string
+	Permission models.Permission
+	Scope      models.ResourceScope
+	Allowed    bool
+	Reason     string
+	Role       *models.Role
+	// GrantedAs is the granted permission that covers Permission, a wildcard such as "room:*" or "*"
+	GrantedAs string
+	// InheritedFrom is the ancestor of Role that carries the grant, if Role does not carry it directly
+	InheritedFrom *models.Role
+	// Condition is the condition of the deciding grant, if it has one
+	Condition string
+	Ban       *models.UserBan
+}
+
+// PermissionSource is a role through which a user holds a permission
+type PermissionSource struct {
+	Role          models.Role
+	InheritedFrom *models.Role
+	GrantedAs     string
+	Scope         models.ResourceScope
+	Condition     string
+}
+
+// EffectivePermission is a permission a user actually holds and the roles it comes from
+type EffectivePermission struct {
+	Permission models.Permission
+	GrantedBy  []PermissionSource
+}
+
+// AuthorizationResult holds either the decision for a query or the error that prevented one
+type AuthorizationResult struct {
+	Decision *AuthorizationDecision
+	Err      error
+}
+
+// AuthorizationServiceInterface answers whether users may perform actions
+type AuthorizationServiceInterface interface {
+	Authorize(query AuthorizationQuery) (*AuthorizationDecision, error)
+	AuthorizeBatch(queries []AuthorizationQuery) ([]AuthorizationResult, error)
+	GetEffectivePermissions(userID string, scope models.ResourceScope) ([]EffectivePermission, error)
+}
+
+// AuthorizationService combines role grants and user bans into a single decision
+type AuthorizationService struct {
+	userBanRepo       repositories.UserBanRepositoryInterface
+	userRoleRepo      repositories.UserRoleRepositoryInterface
+	permissionRepo    repositories.PermissionRepositoryInterface
+	userAttributeRepo repositories.UserAttributeRepositoryInterface
+	evaluator         *conditions.Evaluator
+}
+
+// NewAuthorizationService creates a new authorization service
+func NewAuthorizationService(
+	userBanRepo repositories.UserBanRepositoryInterface,
+	userRoleRepo repositories.UserRoleRepositoryInterface,
+	permissionRepo repositories.PermissionRepositoryInterface,
+	userAttributeRepo repositories.UserAttributeRepositoryInterface,
+	evaluator *conditions.Evaluator,
+) AuthorizationServiceInterface {
+	return &AuthorizationService{
+		userBanRepo:       userBanRepo,
+		userRoleRepo:      userRoleRepo,
+		permissionRepo:    permissionRepo,
+		userAttributeRepo: userAttributeRepo,
+		evaluator:         evaluator,
+	}
+}
+
+// Authorize decides whether a user holds a permission.
+// Bans take precedence over deny grants, which take precedence over allow grants;
+// a user without a granting role is denied.
+// Grants and bans of wildcard permissions cover every permission they match, and
+// conditional grants only count when their condition holds for the query's attributes.
+func (s *AuthorizationService) Authorize(query AuthorizationQuery) (*AuthorizationDecision, error) {
+	results, err := s.AuthorizeBatch([]AuthorizationQuery{query})
+	if err != nil {
+		return nil, err
+	}
+
+	if results[0].Err != nil {
+		return nil, results[0].Err
+	}
+	return results[0].Decision, nil
+}
+
+// AuthorizeBatch answers several queries with a fixed number of set-based queries.
+// Invalid queries get a per-item error instead of failing the whole batch.
+func (s *AuthorizationService) AuthorizeBatch(queries []AuthorizationQuery) ([]AuthorizationResult, error) {
+	if len(queries) == 0 {
+		return nil, fmt.Errorf("at least one authorization check is required")
+	}
+
+	if len(queries) > MaxBatchAuthorizationChecks {
+		return nil, fmt.Errorf("at most %d authorization checks are allowed per batch", MaxBatchAuthorizationChecks)
+	}
+
+	permissionsByID, permissionsByName, err := s.loadPermissions(queries)
+	if err != nil {
+		return nil, err
+	}
+
+	now := time.Now()
+	results := make([]AuthorizationResult, len(queries))
+	attributesByResult := make([]map[string]interface{}, len(queries))
+	var userIDs []string
+	var permIDs []uint
+	seenUsers := map[string]bool{}
+	seenPermissions := map[uint]bool{}
+
+	for i, query := range queries {
+		if query.UserID == "" {
+			results[i].Err = fmt.Errorf("user ID cannot be empty")
+			continue
+		}
+
+		if err := query.Scope.Validate(); err != nil {
+			results[i].Err = err
+			continue
+		}
+
+		var permission *models.Permission
+		switch {
+		case query.PermissionID != 0:
+			permission = permissionsByID[query.PermissionID]
+		case query.PermissionName != "":
+			permission = permissionsByName[query.PermissionName]
+		default:
+			results[i].Err = fmt.Errorf("permission name or permission ID is required")
+			continue
+		}
+
+		if permission == nil {
+			results[i].Err = fmt.Errorf("permission not found")
+			continue
+		}
+
+		results[i].Decision = &AuthorizationDecision{
+			UserID:     query.UserID,
+			Permission: *permission,
+			Scope:      query.Scope,
+		}
+		attributesByResult[i] = query.Attributes
+
+		if !seenUsers[query.UserID] {
+			seenUsers[query.UserID] = true
+			userIDs = append(userIDs, query.UserID)
+		}
+		if !seenPermissions[permission.PermID] {
+			seenPermissions[permission.PermID] = true
+			permIDs = append(permIDs, permission.PermID)
+		}
+	}
+
+	bans, err := s.userBanRepo.GetMatchingBans(userIDs, permIDs)
+	if err != nil {
+		return nil, fmt.Errorf("failed to check user bans: %w", err)
+	}
+
+	bansByKey := map[string][]*models.UserBan{}
+	for i := range bans {
+		key := grantKey(bans[i].Ban.UserID, bans[i].PermID)
+		bansByKey[key] = append(bansByKey[key], &bans[i].Ban)
+	}
+
+	grants, err := s.userRoleRepo.GetPermissionGrants(userIDs, permIDs)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get permission grants: %w", err)
+	}
+
+	grantsByKey := map[string][]*repositories.PermissionGrant{}
+	denialsByKey := map[string][]*repositories.PermissionGrant{}
+	var conditionalUserIDs []string
+	seenConditionalUsers := map[string]bool{}
+	for i := range grants {
+		key := grantKey(grants[i].UserID, grants[i].PermID)
+		if grants[i].Effect == models.GrantEffectDeny {
+			denialsByKey[key] = append(denialsByKey[key], &grants[i])
+		} else {
+			grantsByKey[key] = append(grantsByKey[key], &grants[i])
+		}
+
+		if grants[i].Condition != "" && !seenConditionalUsers[grants[i].UserID] {
+			seenConditionalUsers[grants[i].UserID] = true
+			conditionalUserIDs = append(conditionalUserIDs, grants[i].UserID)
+		}
+	}
+
+	storedAttributes, err := s.loadUserAttributes(conditionalUserIDs)
+	if err != nil {
+		return nil, err
+	}
+
+	for i, result := range results {
+		decision := result.Decision
+		if decision == nil {
+			continue
+		}
+
+		key := grantKey(decision.UserID, decision.Permission.PermID)
+		if ban := firstCoveringBan(bansByKey[key], decision.Scope); ban != nil {
+			decision.Reason = DecisionBanned
+			decision.Ban = ban
+			continue
+		}
+
+		input := conditions.Input{
+			User:    storedAttributes[decision.UserID],
+			Request: attributesByResult[i],
+			Now:     now,
+		}
+
+		if denial := s.firstApplicableDenial(denialsByKey[key], decision.Scope, input); denial != nil {
+			decision.Reason = DecisionDeniedByRole
+			setDecidingGrant(decision, denial)
+			continue
+		}
+
+		grant, conditionNotMet := s.firstApplicableGrant(grantsByKey[key], decision.Scope, input)
+		if grant != nil {
+			decision.Allowed = true
+			decision.Reason = DecisionGranted
+			setDecidingGrant(decision, grant)
+			continue
+		}
+
+		if conditionNotMet {
+			decision.Reason = DecisionConditionNotMet
+			continue
+		}
+
+		decision.Reason = DecisionNoGrant
+	}
+
+	return results, nil
+}
+
+// GetEffectivePermissions returns the union of the permissions granted by the user's roles,
+// minus every permission the user is banned from or denied by a role, as they apply within
+// the given scope. A wildcard is left out when it covers a withheld permission, so that the
+// names returned never match more than the user holds.
+// Conditional grants are evaluated against the attributes stored for the user.
+func (s *AuthorizationService) GetEffectivePermissions(userID string, scope models.ResourceScope) ([]EffectivePermission, error) {
+	if userID == "" {
+		return nil, fmt.Errorf("user ID cannot be empty")
+	}
+
+	if err := scope.Validate(); err != nil {
+		return nil, err
+	}
+
+	bans, err := s.userBanRepo.GetMatchingBans([]string{userID}, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get user bans: %w", err)
+	}
+
+	banned := map[uint]bool{}
+	for _, ban := range bans {
+		if ban.Ban.Blocks() && ban.Ban.Scope().Covers(scope) {
+			banned[ban.PermID] = true
+		}
+	}
+
+	grants, err := s.userRoleRepo.GetPermissionGrants([]string{userID}, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get permission grants: %w", err)
+	}
+
+	storedAttributes, err := s.loadUserAttributes([]string{userID})
+	if err != nil {
+		return nil, err
+	}
+	input := conditions.Input{User: storedAttributes[userID], Now: time.Now()}
+
+	denied := map[uint]bool{}
+	for i := range grants {
+		if grants[i].Effect != models.GrantEffectDeny {
+			continue
+		}
+		if s.firstApplicableDenial([]*repositories.PermissionGrant{&grants[i]}, scope, input) != nil {
+			denied[grants[i].PermID] = true
+		}
+	}
+
+	// Every permission a granted wildcard covers has a row of its own, so the rows name every
+	// withheld permission a wildcard could cover
+	var withheld []string
+	seenWithheld := map[uint]bool{}
+	for _, grant := range grants {
+		if (banned[grant.PermID] || denied[grant.PermID]) && !seenWithheld[grant.PermID] {
+			seenWithheld[grant.PermID] = true
+			withheld = append(withheld, grant.PermName)
+		}
+	}
+
+	effective := []EffectivePermission{}
+	indexByPermID := map[uint]int{}
+	seenSources := map[string]bool{}
+	for _, grant := range grants {
+		if banned[grant.PermID] || denied[grant.PermID] || grant.Effect == models.GrantEffectDeny ||
+			!grantScope(grant).Covers(scope) || coversAny(grant.PermName, withheld) {
+			continue
+		}
+
+		if grant.Condition != "" {
+			if met, err := s.evaluator.Evaluate(grant.Condition, input); err != nil || !met {
+				continue
+			}
+		}
+
+		sourceKey := fmt.Sprintf("%d/%d/%d/%s/%s/%s", grant.PermID, grant.RoleID, grant.SourceRoleID,
+			grant.GrantedAs, grant.ResourceType, grant.ResourceID)
+		if seenSources[sourceKey] {
+			continue
+		}
+		seenSources[sourceKey] = true
+
+		if i, exists := indexByPermID[grant.PermID]; exists {
+			effective[i].GrantedBy = append(effective[i].GrantedBy, grantSource(grant))
+			continue
+		}
+
+		indexByPermID[grant.PermID] = len(effective)
+		effective = append(effective, EffectivePermission{
+			Permission: models.Permission{PermID: grant.PermID, Name: grant.PermName},
+			GrantedBy:  []PermissionSource{grantSource(grant)},
+		})
+	}
+
+	return effective, nil
+}
+
+// coversAny reports whether the permission is a wildcard covering any of the names
+func coversAny(permission string, names []string) bool {
+	if !(models.Permission{Name: permission}).IsWildcard() {
+		return false
+	}
+	for _, name := range names {
+		if models.PermissionMatches(permission, name) {
+			return true
+		}
+	}
+	return false
+}
+
+func (s *AuthorizationService) loadPermissions(queries []AuthorizationQuery) (map[uint]*models.Permission, map[string]*models.Permission, error) {
+	var ids []uint
+	var names []string
+	for _, query := range queries {
+		if query.PermissionID != 0 {
+			ids = append(ids, query.PermissionID)
+		} else if query.PermissionName != "" {
+			names = append(names, query.PermissionName)
+		}
+	}
+
+	byID := map[uint]*models.Permission{}
+	byName := map[string]*models.Permission{}
+
+	permissions, err := s.permissionRepo.GetByIDs(ids)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to get permissions: %w", err)
+	}
+	for i := range permissions {
+		byID[permissions[i].PermID] = &permissions[i]
+	}
+
+	permissions, err = s.permissionRepo.GetByNames(names)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to get permissions: %w", err)
+	}
+	for i := range permissions {
+		byName[permissions[i].Name] = &permissions[i]
+	}
+
+	return byID, byName, nil
+}
+
+func grantSource(grant repositories.PermissionGrant) PermissionSource {
+	source := PermissionSource{
+		Role:      models.Role{RoleID: grant.RoleID, Name: grant.RoleName},
+		GrantedAs: grant.GrantedAs,
+		Scope:     grantScope(grant),
+		Condition: grant.Condition,
+	}
+	if grant.SourceRoleID != grant.RoleID {
+		source.InheritedFrom = &models.Role{RoleID: grant.SourceRoleID, Name: grant.SourceRoleName}
+	}
+	return source
+}
+
+func grantScope(grant repositories.PermissionGrant) models.ResourceScope {
+	return models.ResourceScope{ResourceType: grant.ResourceType, ResourceID: grant.ResourceID}
+}
+
+// firstCoveringBan returns the first ban covering the scope that withholds the permission;
+// warnings, restrictions and shadow bans leave the decision to the grants
+func firstCoveringBan(bans []*models.UserBan, scope models.ResourceScope) *models.UserBan {
+	for _, ban := range bans {
+		if ban.Blocks() && ban.Scope().Covers(scope) {
+			return ban
+		}
+	}
+	return nil
+}
+
+func setDecidingGrant(decision *AuthorizationDecision, grant *repositories.PermissionGrant) {
+	source := grantSource(*grant)
+	decision.Role = &source.Role
+	decision.GrantedAs = grant.GrantedAs
+	decision.InheritedFrom = source.InheritedFrom
+	decision.Condition = grant.Condition
+}
+
+// firstApplicableDenial returns the first deny grant covering the scope whose condition, if any,
+// holds. A deny condition that fails to evaluate is treated as met so that denials fail closed.
+func (s *AuthorizationService) firstApplicableDenial(denials []*repositories.PermissionGrant, scope models.ResourceScope, input conditions.Input) *repositories.PermissionGrant {
+	for _, candidate := range denials {
+		if !grantScope(*candidate).Covers(scope) {
+			continue
+		}
+
+		if candidate.Condition == "" {
+			return candidate
+		}
+
+		if met, err := s.evaluator.Evaluate(candidate.Condition, input); err != nil || met {
+			return candidate
+		}
+	}
+	return nil
+}
+
+// firstApplicableGrant returns the first grant covering the scope whose condition, if any, holds.
+// conditionNotMet reports that covering grants existed but none of their conditions held.
+func (s *AuthorizationService) firstApplicableGrant(grants []*repositories.PermissionGrant, scope models.ResourceScope, input conditions.Input) (grant *repositories.PermissionGrant, conditionNotMet bool) {
+	for _, candidate := range grants {
+		if !grantScope(*candidate).Covers(scope) {
+			continue
+		}
+
+		if candidate.Condition == "" {
+			return candidate, false
+		}
+
+		// A condition that fails to evaluate, e.g. on a missing attribute, is not met
+		if met, err := s.evaluator.Evaluate(candidate.Condition, input); err == nil && met {
+			return candidate, false
+		}
+		conditionNotMet = true
+	}
+	return nil, conditionNotMet
+}
+
+func (s *AuthorizationService) loadUserAttributes(userIDs []string) (map[string]map[string]interface{}, error) {
+	attributesByUser := map[string]map[string]interface{}{}
+	if len(userIDs) == 0 {
+		return attributesByUser, nil
+	}
+
+	attributes, err := s.userAttributeRepo.GetByUserIDs(userIDs)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get user attributes: %w", err)
+	}
+
+	rowsByUser := map[string][]models.UserAttribute{}
+	for _, attribute := range attributes {
+		rowsByUser[attribute.UserID] = append(rowsByUser[attribute.UserID], attribute)
+	}
+	for userID, rows := range rowsByUser {
+		attributesByUser[userID] = decodeAttributes(rows)
+	}
+
+	return attributesByUser, nil
+}
+
+func grantKey(userID string, permID uint) string {
+	return fmt.Sprintf("%s\x00%d", userID, permID)
+}
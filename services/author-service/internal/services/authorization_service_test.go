@@ -0,0 +1,522 @@
+package services
+
+import (
+	"testing"
+
+	"gin/internal/conditions"
+	"gin/internal/models"
+	"gin/internal/repositories"
+)
+
+var (
+	roomCreate    = models.Permission{PermID: 1, Name: "room:create"}
+	roomJoin      = models.Permission{PermID: 2, Name: "room:join"}
+	moderationBan = models.Permission{PermID: 3, Name: "moderation:ban"}
+)
+
+// The fakes return the rows the repositories' queries would, given as the test's own rows: a
+// grant or ban of a wildcard has a row for every permission it covers
+
+type fakePermissionRepository struct {
+	repositories.PermissionRepositoryInterface
+	permissions []models.Permission
+}
+
+func (r *fakePermissionRepository) GetByIDs(ids []uint) ([]models.Permission, error) {
+	var permissions []models.Permission
+	for _, permission := range r.permissions {
+		for _, id := range ids {
+			if permission.PermID == id {
+				permissions = append(permissions, permission)
+			}
+		}
+	}
+	return permissions, nil
+}
+
+func (r *fakePermissionRepository) GetByNames(names []string) ([]models.Permission, error) {
+	var permissions []models.Permission
+	for _, permission := range r.permissions {
+		for _, name := range names {
+			if permission.Name == name {
+				permissions = append(permissions, permission)
+			}
+		}
+	}
+	return permissions, nil
+}
+
+type fakeUserRoleRepository struct {
+	repositories.UserRoleRepositoryInterface
+	grants []repositories.PermissionGrant
+}
+
+func (r *fakeUserRoleRepository) GetPermissionGrants(userIDs []string, permIDs []uint) ([]repositories.PermissionGrant, error) {
+	var grants []repositories.PermissionGrant
+	for _, grant := range r.grants {
+		if selected(userIDs, permIDs, grant.UserID, grant.PermID) {
+			grants = append(grants, grant)
+		}
+	}
+	return grants, nil
+}
+
+type fakeUserBanRepository struct {
+	repositories.UserBanRepositoryInterface
+	bans []repositories.MatchedBan
+}
+
+func (r *fakeUserBanRepository) GetMatchingBans(userIDs []string, permIDs []uint) ([]repositories.MatchedBan, error) {
+	var bans []repositories.MatchedBan
+	for _, ban := range r.bans {
+		if selected(userIDs, permIDs, ban.Ban.UserID, ban.PermID) {
+			bans = append(bans, ban)
+		}
+	}
+	return bans, nil
+}
+
+func (r *fakeUserBanRepository) GetActiveUserBans(userID string) ([]models.UserBan, error) {
+	var bans []models.UserBan
+	seen := map[uint]bool{}
+	for _, ban := range r.bans {
+		if ban.Ban.UserID == userID && !seen[ban.Ban.ID] {
+			seen[ban.Ban.ID] = true
+			bans = append(bans, ban.Ban)
+		}
+	}
+	return bans, nil
+}
+
+type fakeUserAttributeRepository struct {
+	repositories.UserAttributeRepositoryInterface
+	attributes []models.UserAttribute
+}
+
+func (r *fakeUserAttributeRepository) GetByUserIDs(userIDs []string) ([]models.UserAttribute, error) {
+	var attributes []models.UserAttribute
+	for _, attribute := range r.attributes {
+		if selected(userIDs, nil, attribute.UserID, 0) {
+			attributes = append(attributes, attribute)
+		}
+	}
+	return attributes, nil
+}
+
+// selected reports whether a row of userID and permID is among those asked for; nil permIDs
+// asks for every permission
+func selected(userIDs []string, permIDs []uint, userID string, permID uint) bool {
+	for _, id := range userIDs {
+		if id != userID {
+			continue
+		}
+		if permIDs == nil {
+			return true
+		}
+		for _, id := range permIDs {
+			if id == permID {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+func newTestAuthorizationService(t *testing.T, grants []repositories.PermissionGrant, bans []repositories.MatchedBan, attributes []models.UserAttribute) AuthorizationServiceInterface {
+	evaluator, err := conditions.NewEvaluator()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return NewAuthorizationService(
+		&fakeUserBanRepository{bans: bans},
+		&fakeUserRoleRepository{grants: grants},
+		&fakePermissionRepository{permissions: []models.Permission{roomCreate, roomJoin, moderationBan}},
+		&fakeUserAttributeRepository{attributes: attributes},
+		evaluator,
+	)
+}
+
+func TestAuthorizeBatch(t *testing.T) {
+	room7 := models.ResourceScope{ResourceType: "room", ResourceID: "7"}
+
+	tests := []struct {
+		name              string
+		grants            []repositories.PermissionGrant
+		bans              []repositories.MatchedBan
+		attributes        []models.UserAttribute
+		query             AuthorizationQuery
+		wantAllowed       bool
+		wantReason        string
+		wantRole          string
+		wantGrantedAs     string
+		wantInheritedFrom string
+	}{
+		{
+			name:       "no role",
+			query:      AuthorizationQuery{UserID: "alice", PermissionName: "room:create"},
+			wantReason: DecisionNoGrant,
+		},
+		{
+			name: "granted",
+			grants: []repositories.PermissionGrant{
+				{UserID: "alice", PermID: roomCreate.PermID, RoleName: "player", GrantedAs: "room:create"},
+			},
+			query:         AuthorizationQuery{UserID: "alice", PermissionName: "room:create"},
+			wantAllowed:   true,
+			wantReason:    DecisionGranted,
+			wantRole:      "player",
+			wantGrantedAs: "room:create",
+		},
+		{
+			name: "granted to another user",
+			grants: []repositories.PermissionGrant{
+				{UserID: "bob", PermID: roomCreate.PermID, RoleName: "player", GrantedAs: "room:create"},
+			},
+			query:      AuthorizationQuery{UserID: "alice", PermissionName: "room:create"},
+			wantReason: DecisionNoGrant,
+		},
+		{
+			name: "inherited from a parent role",
+			grants: []repositories.PermissionGrant{
+				{UserID: "alice", PermID: roomJoin.PermID, RoleID: 2, RoleName: "moderator", SourceRoleID: 1, SourceRoleName: "player", GrantedAs: "room:join"},
+			},
+			query:             AuthorizationQuery{UserID: "alice", PermissionName: "room:join"},
+			wantAllowed:       true,
+			wantReason:        DecisionGranted,
+			wantRole:          "moderator",
+			wantGrantedAs:     "room:join",
+			wantInheritedFrom: "player",
+		},
+		{
+			name: "family wildcard",
+			grants: []repositories.PermissionGrant{
+				{UserID: "alice", PermID: roomJoin.PermID, RoleName: "host", GrantedAs: "room:*"},
+			},
+			query:         AuthorizationQuery{UserID: "alice", PermissionName: "room:join"},
+			wantAllowed:   true,
+			wantReason:    DecisionGranted,
+			wantRole:      "host",
+			wantGrantedAs: "room:*",
+		},
+		{
+			name: "global wildcard",
+			grants: []repositories.PermissionGrant{
+				{UserID: "alice", PermID: moderationBan.PermID, RoleName: "admin", GrantedAs: "*"},
+			},
+			query:         AuthorizationQuery{UserID: "alice", PermissionName: "moderation:ban"},
+			wantAllowed:   true,
+			wantReason:    DecisionGranted,
+			wantRole:      "admin",
+			wantGrantedAs: "*",
+		},
+		{
+			name: "ban overrides grant",
+			grants: []repositories.PermissionGrant{
+				{UserID: "alice", PermID: roomCreate.PermID, RoleName: "player", GrantedAs: "room:create"},
+			},
+			bans: []repositories.MatchedBan{
+				{PermID: roomCreate.PermID, Ban: models.UserBan{ID: 1, UserID: "alice", Kind: models.BanKindSuspension}},
+			},
+			query:      AuthorizationQuery{UserID: "alice", PermissionName: "room:create"},
+			wantReason: DecisionBanned,
+		},
+		{
+			name: "grant scoped to the resource",
+			grants: []repositories.PermissionGrant{
+				{UserID: "alice", PermID: roomJoin.PermID, RoleName: "host", GrantedAs: "room:join", ResourceType: "room", ResourceID: "7"},
+			},
+			query:         AuthorizationQuery{UserID: "alice", PermissionName: "room:join", Scope: room7},
+			wantAllowed:   true,
+			wantReason:    DecisionGranted,
+			wantRole:      "host",
+			wantGrantedAs: "room:join",
+		},
+		{
+			name: "grant scoped to another resource",
+			grants: []repositories.PermissionGrant{
+				{UserID: "alice", PermID: roomJoin.PermID, RoleName: "host", GrantedAs: "room:join", ResourceType: "room", ResourceID: "8"},
+			},
+			query:      AuthorizationQuery{UserID: "alice", PermissionName: "room:join", Scope: room7},
+			wantReason: DecisionNoGrant,
+		},
+		{
+			name: "scoped grant does not apply globally",
+			grants: []repositories.PermissionGrant{
+				{UserID: "alice", PermID: roomJoin.PermID, RoleName: "host", GrantedAs: "room:join", ResourceType: "room", ResourceID: "7"},
+			},
+			query:      AuthorizationQuery{UserID: "alice", PermissionName: "room:join"},
+			wantReason: DecisionNoGrant,
+		},
+		{
+			name: "ban scoped to another resource",
+			grants: []repositories.PermissionGrant{
+				{UserID: "alice", PermID: roomJoin.PermID, RoleName: "player", GrantedAs: "room:join"},
+			},
+			bans: []repositories.MatchedBan{
+				{PermID: roomJoin.PermID, Ban: models.UserBan{ID: 1, UserID: "alice", Kind: models.BanKindSuspension, ResourceType: "room", ResourceID: "8"}},
+			},
+			query:         AuthorizationQuery{UserID: "alice", PermissionName: "room:join", Scope: room7},
+			wantAllowed:   true,
+			wantReason:    DecisionGranted,
+			wantRole:      "player",
+			wantGrantedAs: "room:join",
+		},
+		{
+			name: "global ban covers every resource",
+			grants: []repositories.PermissionGrant{
+				{UserID: "alice", PermID: roomJoin.PermID, RoleName: "player", GrantedAs: "room:join"},
+			},
+			bans: []repositories.MatchedBan{
+				{PermID: roomJoin.PermID, Ban: models.UserBan{ID: 1, UserID: "alice", Kind: models.BanKindSuspension}},
+			},
+			query:      AuthorizationQuery{UserID: "alice", PermissionName: "room:join", Scope: room7},
+			wantReason: DecisionBanned,
+		},
+		{
+			name: "condition met by a stored attribute",
+			grants: []repositories.PermissionGrant{
+				{UserID: "alice", PermID: roomCreate.PermID, RoleName: "player", GrantedAs: "room:create", Condition: "attrs.account_age_days >= 3"},
+			},
+			attributes:    []models.UserAttribute{{UserID: "alice", Key: "account_age_days", Value: "5"}},
+			query:         AuthorizationQuery{UserID: "alice", PermissionName: "room:create"},
+			wantAllowed:   true,
+			wantReason:    DecisionGranted,
+			wantRole:      "player",
+			wantGrantedAs: "room:create",
+		},
+		{
+			name: "request attribute overrides a stored one",
+			grants: []repositories.PermissionGrant{
+				{UserID: "alice", PermID: roomCreate.PermID, RoleName: "player", GrantedAs: "room:create", Condition: "attrs.account_age_days >= 3"},
+			},
+			attributes: []models.UserAttribute{{UserID: "alice", Key: "account_age_days", Value: "5"}},
+			query: AuthorizationQuery{
+				UserID:         "alice",
+				PermissionName: "room:create",
+				Attributes:     map[string]interface{}{"account_age_days": float64(1)},
+			},
+			wantReason: DecisionConditionNotMet,
+		},
+		{
+			name: "condition on a missing attribute",
+			grants: []repositories.PermissionGrant{
+				{UserID: "alice", PermID: roomCreate.PermID, RoleName: "player", GrantedAs: "room:create", Condition: "attrs.account_age_days >= 3"},
+			},
+			query:      AuthorizationQuery{UserID: "alice", PermissionName: "room:create"},
+			wantReason: DecisionConditionNotMet,
+		},
+		{
+			name: "unconditional grant besides an unmet one",
+			grants: []repositories.PermissionGrant{
+				{UserID: "alice", PermID: roomCreate.PermID, RoleName: "player", GrantedAs: "room:create", Condition: "attrs.account_age_days >= 3"},
+				{UserID: "alice", PermID: roomCreate.PermID, RoleName: "host", GrantedAs: "room:*"},
+			},
+			query:         AuthorizationQuery{UserID: "alice", PermissionName: "room:create"},
+			wantAllowed:   true,
+			wantReason:    DecisionGranted,
+			wantRole:      "host",
+			wantGrantedAs: "room:*",
+		},
+		{
+			name: "deny overrides allow",
+			grants: []repositories.PermissionGrant{
+				{UserID: "alice", PermID: roomCreate.PermID, RoleName: "admin", GrantedAs: "*"},
+				{UserID: "alice", PermID: roomCreate.PermID, RoleName: "restricted", GrantedAs: "room:create", Effect: models.GrantEffectDeny},
+			},
+			query:         AuthorizationQuery{UserID: "alice", PermissionName: "room:create"},
+			wantReason:    DecisionDeniedByRole,
+			wantRole:      "restricted",
+			wantGrantedAs: "room:create",
+		},
+		{
+			name: "deny of a wildcard",
+			grants: []repositories.PermissionGrant{
+				{UserID: "alice", PermID: roomJoin.PermID, RoleName: "player", GrantedAs: "room:join"},
+				{UserID: "alice", PermID: roomJoin.PermID, RoleName: "restricted", GrantedAs: "room:*", Effect: models.GrantEffectDeny},
+			},
+			query:         AuthorizationQuery{UserID: "alice", PermissionName: "room:join"},
+			wantReason:    DecisionDeniedByRole,
+			wantRole:      "restricted",
+			wantGrantedAs: "room:*",
+		},
+		{
+			name: "deny scoped to another resource",
+			grants: []repositories.PermissionGrant{
+				{UserID: "alice", PermID: roomJoin.PermID, RoleName: "player", GrantedAs: "room:join"},
+				{UserID: "alice", PermID: roomJoin.PermID, RoleName: "restricted", GrantedAs: "room:join", ResourceType: "room", ResourceID: "8", Effect: models.GrantEffectDeny},
+			},
+			query:         AuthorizationQuery{UserID: "alice", PermissionName: "room:join", Scope: room7},
+			wantAllowed:   true,
+			wantReason:    DecisionGranted,
+			wantRole:      "player",
+			wantGrantedAs: "room:join",
+		},
+		{
+			name: "deny with an unmet condition",
+			grants: []repositories.PermissionGrant{
+				{UserID: "alice", PermID: roomJoin.PermID, RoleName: "player", GrantedAs: "room:join"},
+				{UserID: "alice", PermID: roomJoin.PermID, RoleName: "restricted", GrantedAs: "room:join", Condition: `attrs.region == "eu"`, Effect: models.GrantEffectDeny},
+			},
+			attributes:    []models.UserAttribute{{UserID: "alice", Key: "region", Value: `"us"`}},
+			query:         AuthorizationQuery{UserID: "alice", PermissionName: "room:join"},
+			wantAllowed:   true,
+			wantReason:    DecisionGranted,
+			wantRole:      "player",
+			wantGrantedAs: "room:join",
+		},
+		{
+			name: "deny whose condition fails to evaluate",
+			grants: []repositories.PermissionGrant{
+				{UserID: "alice", PermID: roomJoin.PermID, RoleName: "player", GrantedAs: "room:join"},
+				{UserID: "alice", PermID: roomJoin.PermID, RoleName: "restricted", GrantedAs: "room:join", Condition: `attrs.region == "eu"`, Effect: models.GrantEffectDeny},
+			},
+			query:         AuthorizationQuery{UserID: "alice", PermissionName: "room:join"},
+			wantReason:    DecisionDeniedByRole,
+			wantRole:      "restricted",
+			wantGrantedAs: "room:join",
+		},
+		{
+			name: "ban overrides deny",
+			grants: []repositories.PermissionGrant{
+				{UserID: "alice", PermID: roomCreate.PermID, RoleName: "restricted", GrantedAs: "room:create", Effect: models.GrantEffectDeny},
+			},
+			bans: []repositories.MatchedBan{
+				{PermID: roomCreate.PermID, Ban: models.UserBan{ID: 1, UserID: "alice", Kind: models.BanKindSuspension}},
+			},
+			query:      AuthorizationQuery{UserID: "alice", PermissionName: "room:create"},
+			wantReason: DecisionBanned,
+		},
+		{
+			name: "mute withholds",
+			grants: []repositories.PermissionGrant{
+				{UserID: "alice", PermID: roomJoin.PermID, RoleName: "player", GrantedAs: "room:join"},
+			},
+			bans: []repositories.MatchedBan{
+				{PermID: roomJoin.PermID, Ban: models.UserBan{ID: 1, UserID: "alice", Kind: models.BanKindMute}},
+			},
+			query:      AuthorizationQuery{UserID: "alice", PermissionName: "room:join"},
+			wantReason: DecisionBanned,
+		},
+		{
+			name: "warning, restriction and shadow ban leave the decision to the grants",
+			grants: []repositories.PermissionGrant{
+				{UserID: "alice", PermID: roomJoin.PermID, RoleName: "player", GrantedAs: "room:join"},
+			},
+			bans: []repositories.MatchedBan{
+				{PermID: roomJoin.PermID, Ban: models.UserBan{ID: 1, UserID: "alice", Kind: models.BanKindWarning}},
+				{PermID: roomJoin.PermID, Ban: models.UserBan{ID: 2, UserID: "alice", Kind: models.BanKindRestriction}},
+				{PermID: roomJoin.PermID, Ban: models.UserBan{ID: 3, UserID: "alice", Kind: models.BanKindShadowBan}},
+			},
+			query:         AuthorizationQuery{UserID: "alice", PermissionName: "room:join"},
+			wantAllowed:   true,
+			wantReason:    DecisionGranted,
+			wantRole:      "player",
+			wantGrantedAs: "room:join",
+		},
+		{
+			name: "warning without a grant",
+			bans: []repositories.MatchedBan{
+				{PermID: roomJoin.PermID, Ban: models.UserBan{ID: 1, UserID: "alice", Kind: models.BanKindWarning}},
+			},
+			query:      AuthorizationQuery{UserID: "alice", PermissionName: "room:join"},
+			wantReason: DecisionNoGrant,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			service := newTestAuthorizationService(t, tt.grants, tt.bans, tt.attributes)
+
+			results, err := service.AuthorizeBatch([]AuthorizationQuery{tt.query})
+			if err != nil {
+				t.Fatalf("AuthorizeBatch() error = %v", err)
+			}
+			if results[0].Err != nil {
+				t.Fatalf("AuthorizeBatch() item error = %v", results[0].Err)
+			}
+
+			decision := results[0].Decision
+			if decision.Allowed != tt.wantAllowed || decision.Reason != tt.wantReason {
+				t.Errorf("decision = %v (%s), want %v (%s)", decision.Allowed, decision.Reason, tt.wantAllowed, tt.wantReason)
+			}
+			if (tt.wantReason == DecisionBanned) != (decision.Ban != nil) {
+				t.Errorf("decision ban = %v for reason %s", decision.Ban, decision.Reason)
+			}
+
+			role := ""
+			if decision.Role != nil {
+				role = decision.Role.Name
+			}
+			if role != tt.wantRole {
+				t.Errorf("decision role = %q, want %q", role, tt.wantRole)
+			}
+			if decision.GrantedAs != tt.wantGrantedAs {
+				t.Errorf("decision granted as = %q, want %q", decision.GrantedAs, tt.wantGrantedAs)
+			}
+
+			inheritedFrom := ""
+			if decision.InheritedFrom != nil {
+				inheritedFrom = decision.InheritedFrom.Name
+			}
+			if inheritedFrom != tt.wantInheritedFrom {
+				t.Errorf("decision inherited from = %q, want %q", inheritedFrom, tt.wantInheritedFrom)
+			}
+		})
+	}
+}
+
+func TestAuthorizeBatchAnswersEachQuery(t *testing.T) {
+	service := newTestAuthorizationService(t,
+		[]repositories.PermissionGrant{
+			{UserID: "alice", PermID: roomCreate.PermID, RoleName: "player", GrantedAs: "room:create"},
+			{UserID: "bob", PermID: roomJoin.PermID, RoleName: "player", GrantedAs: "room:join"},
+			{UserID: "bob", PermID: moderationBan.PermID, RoleName: "moderator", GrantedAs: "moderation:ban"},
+		},
+		[]repositories.MatchedBan{
+			{PermID: moderationBan.PermID, Ban: models.UserBan{ID: 1, UserID: "bob", Kind: models.BanKindSuspension}},
+		},
+		nil,
+	)
+
+	results, err := service.AuthorizeBatch([]AuthorizationQuery{
+		{UserID: "alice", PermissionName: "room:create"},
+		{UserID: "alice", PermissionName: "room:join"},
+		{UserID: "bob", PermissionID: roomJoin.PermID},
+		{UserID: "bob", PermissionName: "moderation:ban"},
+		{UserID: "bob", PermissionName: "room:delete"},
+		{UserID: "", PermissionName: "room:join"},
+		{UserID: "bob"},
+	})
+	if err != nil {
+		t.Fatalf("AuthorizeBatch() error = %v", err)
+	}
+
+	wantReasons := []string{DecisionGranted, DecisionNoGrant, DecisionGranted, DecisionBanned, "", "", ""}
+	for i, want := range wantReasons {
+		switch {
+		case want == "" && results[i].Err == nil:
+			t.Errorf("result %d error = nil, want an error", i)
+		case want != "" && results[i].Err != nil:
+			t.Errorf("result %d error = %v", i, results[i].Err)
+		case want != "" && results[i].Decision.Reason != want:
+			t.Errorf("result %d reason = %s, want %s", i, results[i].Decision.Reason, want)
+		}
+	}
+}
+
+func TestAuthorizeBatchLimits(t *testing.T) {
+	service := newTestAuthorizationService(t, nil, nil, nil)
+
+	if _, err := service.AuthorizeBatch(nil); err == nil {
+		t.Error("AuthorizeBatch(nil) error = nil, want an error")
+	}
+
+	queries := make([]AuthorizationQuery, MaxBatchAuthorizationChecks+1)
+	for i := range queries {
+		queries[i] = AuthorizationQuery{UserID: "alice", PermissionName: "room:join"}
+	}
+	if _, err := service.AuthorizeBatch(queries); err == nil {
+		t.Errorf("AuthorizeBatch() of %d checks error = nil, want an error", len(queries))
+	}
+}
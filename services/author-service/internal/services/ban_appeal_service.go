@@ -0,0 +1,245 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"gin/internal/models"
+	"gin/internal/repositories"
+	"gin/internal/requestctx"
+	"gin/pkg/changes"
+	"time"
+)
+
+// BanAppealServiceInterface lets banned users contest their bans and moderators review the appeals
+type BanAppealServiceInterface interface {
+	SubmitAppeal(ctx context.Context, userID string, banID uint, message string) (*models.BanAppeal, error)
+	GetAppeal(id uint) (*models.BanAppeal, error)
+	GetUserAppeals(userID string) ([]models.BanAppeal, error)
+	GetAppeals(status string) ([]models.BanAppeal, error)
+	AcceptAppeal(ctx context.Context, id uint, response string) (*models.BanAppeal, error)
+	RejectAppeal(ctx context.Context, id uint, response string) (*models.BanAppeal, error)
+}
+
+type BanAppealService struct {
+	banAppealRepo repositories.BanAppealRepositoryInterface
+	userBanRepo   repositories.UserBanRepositoryInterface
+	transactor    repositories.Transactor
+	cache         AuthorizationCacheInvalidator
+	publisher     ChangePublisher
+}
+
+func NewBanAppealService(
+	banAppealRepo repositories.BanAppealRepositoryInterface,
+	userBanRepo repositories.UserBanRepositoryInterface,
+	transactor repositories.Transactor,
+	cache AuthorizationCacheInvalidator,
+	publisher ChangePublisher,
+) BanAppealServiceInterface {
+	return &BanAppealService{
+		banAppealRepo: banAppealRepo,
+		userBanRepo:   userBanRepo,
+		transactor:    transactor,
+		cache:         cache,
+		publisher:     publisher,
+	}
+}
+
+// SubmitAppeal files the single appeal a user may make against one of their active bans
+func (s *BanAppealService) SubmitAppeal(ctx context.Context, userID string, banID uint, message string) (*models.BanAppeal, error) {
+	if userID == "" {
+		return nil, fmt.Errorf("user ID cannot be empty")
+	}
+
+	if banID == 0 {
+		return nil, fmt.Errorf("invalid ban ID")
+	}
+
+	if message == "" {
+		return nil, fmt.Errorf("appeal message cannot be empty")
+	}
+
+	// Shadow bans are not disclosed to the user, so they cannot be appealed either
+	ban, err := s.userBanRepo.GetByID(banID)
+	if err != nil || ban.UserID != userID || ban.Kind == models.BanKindShadowBan {
+		return nil, fmt.Errorf("ban not found")
+	}
+
+	now := time.Now()
+	if !ban.IsActive(now) {
+		return nil, fmt.Errorf("only active bans can be appealed")
+	}
+
+	if existing, err := s.banAppealRepo.GetByBanID(banID); err == nil && existing != nil {
+		return nil, fmt.Errorf("an appeal was already submitted for this ban")
+	}
+
+	appeal := &models.BanAppeal{
+		BanID:     banID,
+		UserID:    userID,
+		Message:   message,
+		Status:    models.AppealStatusPending,
+		CreatedAt: now,
+		UpdatedAt: now,
+		Transitions: []models.BanAppealTransition{
+			{ToStatus: models.AppealStatusPending, Actor: userID, CreatedAt: now},
+		},
+	}
+
+	err = s.transactor.Transaction(func(tx *repositories.Repositories) error {
+		if err := tx.BanAppeal.Create(appeal); err != nil {
+			return fmt.Errorf("failed to submit appeal: %w", err)
+		}
+		if err := recordEvent(ctx, tx, models.EventBanAppealSubmitted, models.AggregateBanAppeal, auditID(appeal.ID), appeal); err != nil {
+			return err
+		}
+		return recordChange(ctx, tx, models.AuditActionAppealSubmit, models.AuditTargetBanAppeal, auditID(appeal.ID), nil, appeal)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return appeal, nil
+}
+
+func (s *BanAppealService) GetAppeal(id uint) (*models.BanAppeal, error) {
+	if id == 0 {
+		return nil, fmt.Errorf("invalid appeal ID")
+	}
+
+	appeal, err := s.banAppealRepo.GetByID(id)
+	if err != nil {
+		return nil, fmt.Errorf("appeal not found: %w", err)
+	}
+
+	return appeal, nil
+}
+
+func (s *BanAppealService) GetUserAppeals(userID string) ([]models.BanAppeal, error) {
+	if userID == "" {
+		return nil, fmt.Errorf("user ID cannot be empty")
+	}
+
+	return s.banAppealRepo.GetByUserID(userID)
+}
+
+// GetAppeals lists the appeals with the given status, or every appeal when status is empty
+func (s *BanAppealService) GetAppeals(status string) ([]models.BanAppeal, error) {
+	switch status {
+	case "", models.AppealStatusPending, models.AppealStatusAccepted, models.AppealStatusRejected:
+	default:
+		return nil, fmt.Errorf("invalid appeal status '%s'", status)
+	}
+
+	return s.banAppealRepo.GetByStatus(status)
+}
+
+// AcceptAppeal closes the appeal and lifts the appealed ban, if it is still in force, in one
+// transaction, so that the ban is lifted if and only if the appeal is accepted. The actor of ctx
+// is recorded as the reviewing moderator.
+func (s *BanAppealService) AcceptAppeal(ctx context.Context, id uint, response string) (*models.BanAppeal, error) {
+	appeal, err := s.pendingAppeal(id)
+	if err != nil {
+		return nil, err
+	}
+
+	var lifted *models.UserBan
+	err = s.transactor.Transaction(func(tx *repositories.Repositories) error {
+		if err := s.review(ctx, tx, appeal, models.AppealStatusAccepted, response); err != nil {
+			return err
+		}
+
+		// A ban that has since expired or been lifted needs no further action
+		ban, err := tx.UserBan.GetByID(appeal.BanID)
+		if err != nil || !ban.IsActive(time.Now()) {
+			return nil
+		}
+
+		lifted, err = liftBan(ctx, tx, ban.ID, fmt.Sprintf("appeal %d accepted", appeal.ID))
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	if lifted != nil {
+		s.cache.InvalidateBans(ctx, lifted.UserID)
+		s.publisher.Publish(ctx, changeEvent(changes.EntityUserBan, lifted.ID, lifted.UserID, changes.OperationDelete))
+	}
+
+	return appeal, nil
+}
+
+// RejectAppeal closes the appeal and leaves the ban in place; the response tells the user why
+func (s *BanAppealService) RejectAppeal(ctx context.Context, id uint, response string) (*models.BanAppeal, error) {
+	if response == "" {
+		return nil, fmt.Errorf("a response is required to reject an appeal")
+	}
+
+	appeal, err := s.pendingAppeal(id)
+	if err != nil {
+		return nil, err
+	}
+
+	err = s.transactor.Transaction(func(tx *repositories.Repositories) error {
+		return s.review(ctx, tx, appeal, models.AppealStatusRejected, response)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return appeal, nil
+}
+
+func (s *BanAppealService) pendingAppeal(id uint) (*models.BanAppeal, error) {
+	appeal, err := s.GetAppeal(id)
+	if err != nil {
+		return nil, err
+	}
+
+	if appeal.Status != models.AppealStatusPending {
+		return nil, repositories.ErrAppealAlreadyReviewed
+	}
+
+	return appeal, nil
+}
+
+// appealReviews maps the status an appeal is reviewed to onto the event and audit action recording it
+var appealReviews = map[string]struct{ event, action string }{
+	models.AppealStatusAccepted: {models.EventBanAppealAccepted, models.AuditActionAppealAccept},
+	models.AppealStatusRejected: {models.EventBanAppealRejected, models.AuditActionAppealReject},
+}
+
+// review moves the appeal to status within the transaction of tx on behalf of the actor of ctx
+// and records the change. The transition only applies while the appeal is still pending, so of
+// two concurrent reviews the later one fails.
+func (s *BanAppealService) review(ctx context.Context, tx *repositories.Repositories, appeal *models.BanAppeal, status, response string) error {
+	now := time.Now()
+	moderatorID := requestctx.Actor(ctx)
+	fromStatus := appeal.Status
+	before := *appeal
+
+	appeal.Status = status
+	appeal.Response = response
+	appeal.ReviewedBy = moderatorID
+	appeal.ReviewedAt = &now
+	appeal.UpdatedAt = now
+
+	transition := &models.BanAppealTransition{
+		FromStatus: fromStatus,
+		ToStatus:   status,
+		Actor:      moderatorID,
+		CreatedAt:  now,
+	}
+
+	if err := tx.BanAppeal.Transition(appeal, fromStatus, transition); err != nil {
+		return fmt.Errorf("failed to review appeal: %w", err)
+	}
+
+	appeal.Transitions = append(appeal.Transitions, *transition)
+
+	recorded := appealReviews[status]
+	if err := recordEvent(ctx, tx, recorded.event, models.AggregateBanAppeal, auditID(appeal.ID), appeal); err != nil {
+		return err
+	}
+	return recordChange(ctx, tx, recorded.action, models.AuditTargetBanAppeal, auditID(appeal.ID), before, appeal)
+}
@@ -0,0 +1,128 @@
+package services
+
+import (
+	"fmt"
+	"gin/internal/models"
+	"gin/internal/repositories"
+)
+
+// BanReasonServiceInterface manages the catalogue of ban reason codes
+type BanReasonServiceInterface interface {
+	CreateReason(reason *models.BanReason) error
+	GetReason(code string) (*models.BanReason, error)
+	GetAllReasons() ([]models.BanReason, error)
+	UpdateReason(reason *models.BanReason) error
+	DeleteReason(code string) error
+}
+
+type BanReasonService struct {
+	banReasonRepo repositories.BanReasonRepositoryInterface
+}
+
+func NewBanReasonService(banReasonRepo repositories.BanReasonRepositoryInterface) BanReasonServiceInterface {
+	return &BanReasonService{
+		banReasonRepo: banReasonRepo,
+	}
+}
+
+func validateBanReason(reason *models.BanReason) error {
+	if reason == nil {
+		return fmt.Errorf("ban reason cannot be nil")
+	}
+
+	if !models.IsValidBanReasonCode(reason.Code) {
+		return fmt.Errorf("reason code '%s' must be a lowercase identifier such as 'afk_abuse'", reason.Code)
+	}
+
+	if reason.Label == "" {
+		return fmt.Errorf("reason label cannot be empty")
+	}
+
+	if reason.DefaultKind == "" {
+		reason.DefaultKind = models.BanKindSuspension
+	}
+	if !models.IsValidBanKind(reason.DefaultKind) {
+		return fmt.Errorf("invalid ban kind '%s'", reason.DefaultKind)
+	}
+
+	if reason.DefaultDurationSeconds < 0 {
+		return fmt.Errorf("default duration cannot be negative")
+	}
+
+	return nil
+}
+
+func (s *BanReasonService) CreateReason(reason *models.BanReason) error {
+	if err := validateBanReason(reason); err != nil {
+		return err
+	}
+
+	if existing, err := s.banReasonRepo.GetByCode(reason.Code); err == nil && existing != nil {
+		return fmt.Errorf("reason code '%s' already exists", reason.Code)
+	}
+
+	if err := s.banReasonRepo.Create(reason); err != nil {
+		return fmt.Errorf("failed to create ban reason: %w", err)
+	}
+
+	return nil
+}
+
+func (s *BanReasonService) GetReason(code string) (*models.BanReason, error) {
+	if code == "" {
+		return nil, fmt.Errorf("reason code cannot be empty")
+	}
+
+	reason, err := s.banReasonRepo.GetByCode(code)
+	if err != nil {
+		return nil, fmt.Errorf("ban reason not found: %w", err)
+	}
+
+	return reason, nil
+}
+
+func (s *BanReasonService) GetAllReasons() ([]models.BanReason, error) {
+	return s.banReasonRepo.GetAll()
+}
+
+// UpdateReason replaces the label, player text and defaults of an existing reason code
+func (s *BanReasonService) UpdateReason(reason *models.BanReason) error {
+	if err := validateBanReason(reason); err != nil {
+		return err
+	}
+
+	existing, err := s.banReasonRepo.GetByCode(reason.Code)
+	if err != nil {
+		return fmt.Errorf("ban reason not found: %w", err)
+	}
+
+	existing.Label = reason.Label
+	existing.PlayerText = reason.PlayerText
+	existing.DefaultKind = reason.DefaultKind
+	existing.DefaultDurationSeconds = reason.DefaultDurationSeconds
+
+	if err := s.banReasonRepo.Update(existing); err != nil {
+		return fmt.Errorf("failed to update ban reason: %w", err)
+	}
+
+	*reason = *existing
+	return nil
+}
+
+// DeleteReason removes a reason code that no ban has been issued with; codes in use are kept
+// so that reports on past bans stay complete
+func (s *BanReasonService) DeleteReason(code string) error {
+	if _, err := s.GetReason(code); err != nil {
+		return err
+	}
+
+	inUse, err := s.banReasonRepo.CountBans(code)
+	if err != nil {
+		return fmt.Errorf("failed to check ban reason usage: %w", err)
+	}
+	if inUse > 0 {
+		return fmt.Errorf("reason code '%s' is used by %d bans", code, inUse)
+	}
+
+	return s.banReasonRepo.Delete(code)
+}
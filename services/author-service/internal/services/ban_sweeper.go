@@ -0,0 +1,40 @@
+package services
+
+import (
+	"context"
+	"gin/internal/requestctx"
+	"log"
+	"time"
+)
+
+// DefaultBanSweepInterval is how often expired bans are finalised when no interval is configured
+const DefaultBanSweepInterval = time.Minute
+
+// RunBanSweeper finalises expired bans every interval until ctx is cancelled.
+// Expired bans stop applying as soon as they run out; the sweeper only records that they ended.
+func RunBanSweeper(ctx context.Context, userBanService UserBanServiceInterface, interval time.Duration) {
+	if interval <= 0 {
+		interval = DefaultBanSweepInterval
+	}
+
+	ctx = requestctx.WithActor(ctx, requestctx.ActorSystem)
+
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			expired, err := userBanService.ExpireBans(ctx)
+			if err != nil {
+				log.Printf("Ban sweeper: %v", err)
+				continue
+			}
+			if expired > 0 {
+				log.Printf("Ban sweeper: finalised %d expired bans", expired)
+			}
+		}
+	}
+}
@@ -0,0 +1,21 @@
+package services
+
+import (
+	"context"
+	"gin/pkg/changes"
+)
+
+// ChangePublisher announces committed changes to other services, so that they can drop the
+// permissions they cached
+type ChangePublisher interface {
+	Publish(ctx context.Context, events ...changes.Event)
+}
+
+// noChanges is the publisher used when changes are not announced
+type noChanges struct{}
+
+func (noChanges) Publish(ctx context.Context, events ...changes.Event) {}
+
+func changeEvent(entity string, id uint, userID, operation string) changes.Event {
+	return changes.Event{Entity: entity, ID: auditID(id), UserID: userID, Operation: operation}
+}
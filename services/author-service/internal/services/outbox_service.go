@@ -0,0 +1,271 @@
+package services
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"gin/internal/models"
+	"gin/internal/outbox"
+	"gin/internal/repositories"
+	"gin/internal/requestctx"
+	"log"
+	"time"
+)
+
+// Outbox defaults used when none are configured
+const (
+	DefaultOutboxDispatchInterval = time.Second
+	DefaultOutboxMaxAttempts      = 10
+	DefaultOutboxBaseBackoff      = 5 * time.Second
+	DefaultOutboxMaxBackoff       = time.Hour
+	DefaultOutboxBatchSize        = 100
+)
+
+// MaxOutboxPageSize caps the number of outbox events or dead letters returned at once
+const MaxOutboxPageSize = 100
+
+// outboxLease is how long a claimed delivery is left alone by other dispatchers; it outlasts
+// any sink's own timeout
+const outboxLease = time.Minute
+
+// OutboxConfig holds the sinks events are delivered to and how failed deliveries are retried
+type OutboxConfig struct {
+	Sinks []outbox.Sink
+	// MaxAttempts is how many times a delivery is attempted before it becomes a dead letter
+	MaxAttempts int
+	// BaseBackoff is the wait after the first failed attempt; it doubles with every further
+	// failure up to MaxBackoff
+	BaseBackoff time.Duration
+	MaxBackoff  time.Duration
+	BatchSize   int
+}
+
+// OutboxServiceInterface delivers outbox events to their sinks and reports on their delivery.
+// Events are written by the services whose changes they report.
+type OutboxServiceInterface interface {
+	Dispatch(ctx context.Context) (int, error)
+	GetEvents(filter repositories.OutboxEventFilter) ([]models.OutboxEvent, int64, error)
+	GetEvent(id uint) (*models.OutboxEvent, error)
+	GetDeadLetters(includeRetried bool, limit, offset int) ([]models.OutboxDeadLetter, int64, error)
+	RetryDeadLetter(id uint) error
+}
+
+type OutboxService struct {
+	outboxRepo repositories.OutboxRepositoryInterface
+	sinks      map[string]outbox.Sink
+	sinkNames  []string
+	config     OutboxConfig
+}
+
+// NewOutboxService creates an outbox service delivering to the configured sinks
+func NewOutboxService(outboxRepo repositories.OutboxRepositoryInterface, config OutboxConfig) OutboxServiceInterface {
+	if config.MaxAttempts <= 0 {
+		config.MaxAttempts = DefaultOutboxMaxAttempts
+	}
+	if config.BaseBackoff <= 0 {
+		config.BaseBackoff = DefaultOutboxBaseBackoff
+	}
+	if config.MaxBackoff <= 0 {
+		config.MaxBackoff = DefaultOutboxMaxBackoff
+	}
+	if config.BatchSize <= 0 {
+		config.BatchSize = DefaultOutboxBatchSize
+	}
+
+	sinks := make(map[string]outbox.Sink, len(config.Sinks))
+	sinkNames := make([]string, 0, len(config.Sinks))
+	for _, sink := range config.Sinks {
+		sinks[sink.Name()] = sink
+		sinkNames = append(sinkNames, sink.Name())
+	}
+
+	return &OutboxService{
+		outboxRepo: outboxRepo,
+		sinks:      sinks,
+		sinkNames:  sinkNames,
+		config:     config,
+	}
+}
+
+// Dispatch gives new events a delivery for every sink, then attempts the deliveries that are
+// due and returns how many succeeded. A failed delivery is retried with exponential backoff
+// until it runs out of attempts and becomes a dead letter.
+func (s *OutboxService) Dispatch(ctx context.Context) (int, error) {
+	now := time.Now()
+
+	if _, err := s.outboxRepo.FanOut(s.sinkNames, s.config.BatchSize, now); err != nil {
+		return 0, fmt.Errorf("failed to fan out outbox events: %w", err)
+	}
+
+	deliveries, err := s.outboxRepo.ClaimDeliveries(s.config.BatchSize, now, outboxLease)
+	if err != nil {
+		return 0, fmt.Errorf("failed to claim outbox deliveries: %w", err)
+	}
+
+	delivered := 0
+	for _, delivery := range deliveries {
+		if ctx.Err() != nil {
+			// Unattempted deliveries are picked up again once their lease runs out
+			return delivered, ctx.Err()
+		}
+
+		err := s.deliver(ctx, delivery)
+		if err == nil {
+			if err := s.outboxRepo.MarkDelivered(delivery.ID, time.Now()); err != nil {
+				return delivered, fmt.Errorf("failed to record outbox delivery %d: %w", delivery.ID, err)
+			}
+			delivered++
+			continue
+		}
+
+		attempts := delivery.Attempts + 1
+		if attempts >= s.config.MaxAttempts {
+			log.Printf("Outbox: giving up on event %d for %s after %d attempts: %v", delivery.EventID, delivery.Sink, attempts, err)
+			err = s.outboxRepo.MarkDead(delivery.ID, attempts, err.Error(), time.Now())
+		} else {
+			err = s.outboxRepo.MarkFailed(delivery.ID, attempts, time.Now().Add(s.backoff(attempts)), err.Error())
+		}
+		if err != nil {
+			return delivered, fmt.Errorf("failed to record outbox delivery %d: %w", delivery.ID, err)
+		}
+	}
+
+	return delivered, nil
+}
+
+func (s *OutboxService) deliver(ctx context.Context, delivery models.OutboxDelivery) error {
+	sink, ok := s.sinks[delivery.Sink]
+	if !ok {
+		return fmt.Errorf("sink %s is not configured", delivery.Sink)
+	}
+	return sink.Deliver(ctx, delivery.Event)
+}
+
+// backoff is the wait after the given number of failed attempts
+func (s *OutboxService) backoff(attempts int) time.Duration {
+	wait := s.config.BaseBackoff
+	for i := 1; i < attempts && wait < s.config.MaxBackoff; i++ {
+		wait *= 2
+	}
+	if wait > s.config.MaxBackoff {
+		wait = s.config.MaxBackoff
+	}
+	return wait
+}
+
+// GetEvents returns a page of the events matching the filter with their deliveries, newest
+// first, and the number of matching events
+func (s *OutboxService) GetEvents(filter repositories.OutboxEventFilter) ([]models.OutboxEvent, int64, error) {
+	if filter.Limit <= 0 || filter.Limit > MaxOutboxPageSize {
+		return nil, 0, fmt.Errorf("page size must be between 1 and %d", MaxOutboxPageSize)
+	}
+
+	if filter.Offset < 0 {
+		return nil, 0, fmt.Errorf("page cannot be negative")
+	}
+
+	switch filter.DeliveryStatus {
+	case "", models.DeliveryStatusPending, models.DeliveryStatusDelivered, models.DeliveryStatusDead:
+	default:
+		return nil, 0, fmt.Errorf("delivery status must be '%s', '%s' or '%s'",
+			models.DeliveryStatusPending, models.DeliveryStatusDelivered, models.DeliveryStatusDead)
+	}
+
+	events, total, err := s.outboxRepo.FindEvents(filter)
+	if err != nil {
+		return nil, 0, fmt.Errorf("failed to get outbox events: %w", err)
+	}
+
+	return events, total, nil
+}
+
+func (s *OutboxService) GetEvent(id uint) (*models.OutboxEvent, error) {
+	if id == 0 {
+		return nil, fmt.Errorf("invalid event ID")
+	}
+
+	event, err := s.outboxRepo.GetEvent(id)
+	if err != nil {
+		return nil, fmt.Errorf("outbox event not found: %w", err)
+	}
+
+	return event, nil
+}
+
+// GetDeadLetters returns a page of dead letters, newest first, and the number of dead letters.
+// Retried dead letters are left out unless includeRetried is set.
+func (s *OutboxService) GetDeadLetters(includeRetried bool, limit, offset int) ([]models.OutboxDeadLetter, int64, error) {
+	if limit <= 0 || limit > MaxOutboxPageSize {
+		return nil, 0, fmt.Errorf("page size must be between 1 and %d", MaxOutboxPageSize)
+	}
+
+	if offset < 0 {
+		return nil, 0, fmt.Errorf("page cannot be negative")
+	}
+
+	deadLetters, total, err := s.outboxRepo.FindDeadLetters(includeRetried, limit, offset)
+	if err != nil {
+		return nil, 0, fmt.Errorf("failed to get dead letters: %w", err)
+	}
+
+	return deadLetters, total, nil
+}
+
+// RetryDeadLetter queues the delivery of a dead letter again with a fresh set of attempts
+func (s *OutboxService) RetryDeadLetter(id uint) error {
+	if id == 0 {
+		return fmt.Errorf("invalid dead letter ID")
+	}
+
+	if err := s.outboxRepo.RetryDeadLetter(id, time.Now()); err != nil {
+		return fmt.Errorf("dead letter not found or already retried: %w", err)
+	}
+
+	return nil
+}
+
+// recordEvent writes a domain event to the outbox, attributed to the actor and request of ctx.
+// It must be given the repositories of the transaction that makes the change, so that the
+// event is published if and only if the change commits.
+func recordEvent(ctx context.Context, tx *repositories.Repositories, eventType, aggregateType, aggregateID string, data interface{}) error {
+	payload, err := json.Marshal(data)
+	if err != nil {
+		return fmt.Errorf("failed to encode %s event: %w", eventType, err)
+	}
+
+	event := &models.OutboxEvent{
+		Type:          eventType,
+		AggregateType: aggregateType,
+		AggregateID:   aggregateID,
+		Payload:       string(payload),
+		Actor:         requestctx.Actor(ctx),
+		RequestID:     requestctx.RequestID(ctx),
+		CreatedAt:     time.Now(),
+	}
+	if err := tx.Outbox.CreateEvent(event); err != nil {
+		return fmt.Errorf("failed to write %s event: %w", eventType, err)
+	}
+
+	return nil
+}
+
+// RunOutboxDispatcher delivers outbox events every interval until ctx is cancelled
+func RunOutboxDispatcher(ctx context.Context, outboxService OutboxServiceInterface, interval time.Duration) {
+	if interval <= 0 {
+		interval = DefaultOutboxDispatchInterval
+	}
+
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			if _, err := outboxService.Dispatch(ctx); err != nil && ctx.Err() == nil {
+				log.Printf("Outbox dispatcher: %v", err)
+			}
+		}
+	}
+}
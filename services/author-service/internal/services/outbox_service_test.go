@@ -0,0 +1,39 @@
+package services
+
+import (
+	"math"
+	"testing"
+	"time"
+)
+
+func TestOutboxBackoff(t *testing.T) {
+	tests := []struct {
+		name        string
+		baseBackoff time.Duration
+		maxBackoff  time.Duration
+		attempts    int
+		want        time.Duration
+	}{
+		{name: "before any attempt", baseBackoff: 5 * time.Second, maxBackoff: time.Hour, attempts: 0, want: 5 * time.Second},
+		{name: "first failure", baseBackoff: 5 * time.Second, maxBackoff: time.Hour, attempts: 1, want: 5 * time.Second},
+		{name: "second failure doubles", baseBackoff: 5 * time.Second, maxBackoff: time.Hour, attempts: 2, want: 10 * time.Second},
+		{name: "fifth failure", baseBackoff: 5 * time.Second, maxBackoff: time.Hour, attempts: 5, want: 80 * time.Second},
+		{name: "capped at the maximum", baseBackoff: 5 * time.Second, maxBackoff: time.Hour, attempts: 12, want: time.Hour},
+		{name: "many attempts do not overflow", baseBackoff: 5 * time.Second, maxBackoff: time.Hour, attempts: math.MaxInt32, want: time.Hour},
+		{name: "base above the maximum", baseBackoff: 2 * time.Hour, maxBackoff: time.Hour, attempts: 1, want: time.Hour},
+		{name: "defaults", attempts: 3, want: 4 * DefaultOutboxBaseBackoff},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			service := NewOutboxService(nil, OutboxConfig{
+				BaseBackoff: tt.baseBackoff,
+				MaxBackoff:  tt.maxBackoff,
+			}).(*OutboxService)
+
+			if got := service.backoff(tt.attempts); got != tt.want {
+				t.Errorf("backoff(%d) = %v, want %v", tt.attempts, got, tt.want)
+			}
+		})
+	}
+}
@@ -1,36 +1,48 @@
 package services
 
 import (
+	"context"
 	"fmt"
 	"gin/internal/models"
 	"gin/internal/repositories"
+	"gin/pkg/changes"
 )
 
 type PermissionServiceInterface interface {
-	CreatePermission(name string) (*models.Permission, error)
+	CreatePermission(ctx context.Context, name string) (*models.Permission, error)
 	GetPermissionByID(id uint) (*models.Permission, error)
 	GetPermissionByName(name string) (*models.Permission, error)
 	GetAllPermissions() ([]models.Permission, error)
-	UpdatePermission(permission *models.Permission) error
-	DeletePermission(id uint) error
+	UpdatePermission(ctx context.Context, permission *models.Permission) error
+	DeletePermission(ctx context.Context, id uint) error
 	GetPermissionWithRoles(id uint) (*models.Permission, error)
 }
 
 type PermissionService struct {
 	permissionRepo repositories.PermissionRepositoryInterface
+	transactor     repositories.Transactor
+	cache          AuthorizationCacheInvalidator
+	publisher      ChangePublisher
 }
 
-func NewPermissionService(permissionRepo repositories.PermissionRepositoryInterface) PermissionServiceInterface {
+func NewPermissionService(permissionRepo repositories.PermissionRepositoryInterface, transactor repositories.Transactor, cache AuthorizationCacheInvalidator, publisher ChangePublisher) PermissionServiceInterface {
 	return &PermissionService{
 		permissionRepo: permissionRepo,
+		transactor:     transactor,
+		cache:          cache,
+		publisher:      publisher,
 	}
 }
 
-func (s *PermissionService) CreatePermission(name string) (*models.Permission, error) {
+func (s *PermissionService) CreatePermission(ctx context.Context, name string) (*models.Permission, error) {
 	if name == "" {
 		return nil, fmt.Errorf("permission name cannot be empty")
 	}
 
+	if !models.IsValidPermissionName(name) {
+		return nil, fmt.Errorf("permission name '%s' must follow the resource:action scheme, e.g. 'room:create' or 'room:*'", name)
+	}
+
 	existingPermission, err := s.permissionRepo.GetByName(name)
 	if err == nil && existingPermission != nil {
 		return nil, fmt.Errorf("permission with name '%s' already exists", name)
@@ -40,10 +52,20 @@ func (s *PermissionService) CreatePermission(name string) (*models.Permission, e
 		Name: name,
 	}
 
-	if err := s.permissionRepo.Create(permission); err != nil {
-		return nil, fmt.Errorf("failed to create permission: %w", err)
+	err = s.transactor.Transaction(func(tx *repositories.Repositories) error {
+		if err := tx.Permission.Create(permission); err != nil {
+			return fmt.Errorf("failed to create permission: %w", err)
+		}
+		if err := recordEvent(ctx, tx, models.EventPermissionCreated, models.AggregatePermission, auditID(permission.PermID), permission); err != nil {
+			return err
+		}
+		return recordChange(ctx, tx, models.AuditActionPermissionCreate, models.AuditTargetPermission, auditID(permission.PermID), nil, permission)
+	})
+	if err != nil {
+		return nil, err
 	}
 
+	s.publisher.Publish(ctx, changeEvent(changes.EntityPermission, permission.PermID, "", changes.OperationCreate))
 	return permission, nil
 }
 
@@ -77,7 +99,7 @@ func (s *PermissionService) GetAllPermissions() ([]models.Permission, error) {
 	return s.permissionRepo.GetAll()
 }
 
-func (s *PermissionService) UpdatePermission(permission *models.Permission) error {
+func (s *PermissionService) UpdatePermission(ctx context.Context, permission *models.Permission) error {
 	if permission == nil {
 		return fmt.Errorf("permission cannot be nil")
 	}
@@ -86,6 +108,10 @@ func (s *PermissionService) UpdatePermission(permission *models.Permission) erro
 		return fmt.Errorf("permission name cannot be empty")
 	}
 
+	if !models.IsValidPermissionName(permission.Name) {
+		return fmt.Errorf("permission name '%s' must follow the resource:action scheme, e.g. 'room:create' or 'room:*'", permission.Name)
+	}
+
 	existingPermission, err := s.permissionRepo.GetByID(permission.PermID)
 	if err != nil {
 		return fmt.Errorf("permission not found: %w", err)
@@ -95,21 +121,55 @@ func (s *PermissionService) UpdatePermission(permission *models.Permission) erro
 		return fmt.Errorf("permission with name '%s' already exists", permission.Name)
 	}
 
+	before := *existingPermission
 	existingPermission.Name = permission.Name
-	return s.permissionRepo.Update(existingPermission)
+
+	err = s.transactor.Transaction(func(tx *repositories.Repositories) error {
+		if err := tx.Permission.Update(existingPermission); err != nil {
+			return err
+		}
+		if err := recordEvent(ctx, tx, models.EventPermissionUpdated, models.AggregatePermission, auditID(permission.PermID), existingPermission); err != nil {
+			return err
+		}
+		return recordChange(ctx, tx, models.AuditActionPermissionUpdate, models.AuditTargetPermission, auditID(permission.PermID), before, existingPermission)
+	})
+	if err != nil {
+		return err
+	}
+
+	// A rename changes which wildcard grants and bans cover the permission, for every user
+	s.cache.InvalidateAll(ctx)
+	s.publisher.Publish(ctx, changeEvent(changes.EntityPermission, permission.PermID, "", changes.OperationUpdate))
+	return nil
 }
 
-func (s *PermissionService) DeletePermission(id uint) error {
+func (s *PermissionService) DeletePermission(ctx context.Context, id uint) error {
 	if id == 0 {
 		return fmt.Errorf("invalid permission ID")
 	}
 
-	_, err := s.permissionRepo.GetByID(id)
+	permission, err := s.permissionRepo.GetByID(id)
 	if err != nil {
 		return fmt.Errorf("permission not found: %w", err)
 	}
 
-	return s.permissionRepo.Delete(id)
+	err = s.transactor.Transaction(func(tx *repositories.Repositories) error {
+		if err := tx.Permission.Delete(id); err != nil {
+			return err
+		}
+		if err := recordEvent(ctx, tx, models.EventPermissionDeleted, models.AggregatePermission, auditID(id), permission); err != nil {
+			return err
+		}
+		return recordChange(ctx, tx, models.AuditActionPermissionDelete, models.AuditTargetPermission, auditID(id), permission, nil)
+	})
+	if err != nil {
+		return err
+	}
+
+	// The grants, bans and ban exemptions of the permission go with it, for every user
+	s.cache.InvalidateAll(ctx)
+	s.publisher.Publish(ctx, changeEvent(changes.EntityPermission, id, "", changes.OperationDelete))
+	return nil
 }
 
 func (s *PermissionService) GetPermissionWithRoles(id uint) (*models.Permission, error) {
@@ -0,0 +1,201 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"gin/internal/auth"
+	"gin/internal/models"
+	"gin/internal/repositories"
+	"log"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+)
+
+// Defaults for permission tokens and their signing keys
+const (
+	DefaultPermissionTokenTTL    = 5 * time.Minute
+	DefaultPermissionTokenIssuer = "authorization-service"
+	DefaultSigningKeyRotation    = 24 * time.Hour
+	DefaultSigningKeyOverlap     = time.Hour
+)
+
+// signingKeyRotationCheck is how often the signing keys are checked for a due rotation
+const signingKeyRotationCheck = time.Minute
+
+// PermissionTokenConfig configures the tokens minted for offline permission checks
+type PermissionTokenConfig struct {
+	Keys   *auth.KeyStore
+	Issuer string
+	TTL    time.Duration
+}
+
+// PermissionTokenBan is an active ban as carried in a permission token
+type PermissionTokenBan struct {
+	ID           uint     `json:"id"`
+	Kind         string   `json:"kind"`
+	Blocks       bool     `json:"blocks"`
+	Permission   string   `json:"permission,omitempty"` // empty for a global ban
+	ResourceType string   `json:"resource_type,omitempty"`
+	ResourceID   string   `json:"resource_id,omitempty"`
+	ExpiresAt    int64    `json:"expires_at,omitempty"`
+	Exemptions   []string `json:"exemptions,omitempty"`
+}
+
+// PermissionTokenClaims are the claims of a permission token. Permissions are the names, possibly
+// wildcards, the user holds in the token's scope at issue time, after bans and deny grants. A
+// wildcard is only included when no permission it covers is withheld.
+type PermissionTokenClaims struct {
+	jwt.RegisteredClaims
+	ResourceType string               `json:"resource_type,omitempty"`
+	ResourceID   string               `json:"resource_id,omitempty"`
+	Permissions  []string             `json:"permissions"`
+	Bans         []PermissionTokenBan `json:"bans"`
+}
+
+// IssuedPermissionToken is a signed permission token and when it expires
+type IssuedPermissionToken struct {
+	Token     string
+	ExpiresAt time.Time
+}
+
+// PermissionTokenServiceInterface mints short-lived tokens that let other services check a
+// user's permissions offline, and publishes the keys to verify them with
+type PermissionTokenServiceInterface interface {
+	IssueToken(userID string, scope models.ResourceScope) (*IssuedPermissionToken, error)
+	PublicKeys() []auth.JWK
+}
+
+type PermissionTokenService struct {
+	authorizationService AuthorizationServiceInterface
+	userBanRepo          repositories.UserBanRepositoryInterface
+	config               PermissionTokenConfig
+}
+
+func NewPermissionTokenService(
+	authorizationService AuthorizationServiceInterface,
+	userBanRepo repositories.UserBanRepositoryInterface,
+	config PermissionTokenConfig,
+) PermissionTokenServiceInterface {
+	if config.Issuer == "" {
+		config.Issuer = DefaultPermissionTokenIssuer
+	}
+	if config.TTL <= 0 {
+		config.TTL = DefaultPermissionTokenTTL
+	}
+
+	return &PermissionTokenService{
+		authorizationService: authorizationService,
+		userBanRepo:          userBanRepo,
+		config:               config,
+	}
+}
+
+// IssueToken signs the user's effective permissions in the scope and their active bans, other
+// than shadow bans, with the current signing key
+func (s *PermissionTokenService) IssueToken(userID string, scope models.ResourceScope) (*IssuedPermissionToken, error) {
+	if s.config.Keys == nil {
+		return nil, fmt.Errorf("permission tokens are not configured")
+	}
+
+	effective, err := s.authorizationService.GetEffectivePermissions(userID, scope)
+	if err != nil {
+		return nil, err
+	}
+
+	bans, err := s.userBanRepo.GetActiveUserBans(userID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get user bans: %w", err)
+	}
+
+	now := time.Now()
+	expiresAt := now.Add(s.config.TTL)
+	claims := PermissionTokenClaims{
+		RegisteredClaims: jwt.RegisteredClaims{
+			Issuer:    s.config.Issuer,
+			Subject:   userID,
+			IssuedAt:  jwt.NewNumericDate(now),
+			NotBefore: jwt.NewNumericDate(now),
+			ExpiresAt: jwt.NewNumericDate(expiresAt),
+		},
+		ResourceType: scope.ResourceType,
+		ResourceID:   scope.ResourceID,
+		Permissions:  make([]string, 0, len(effective)),
+		Bans:         make([]PermissionTokenBan, 0, len(bans)),
+	}
+
+	for _, permission := range effective {
+		claims.Permissions = append(claims.Permissions, permission.Permission.Name)
+	}
+
+	for _, ban := range bans {
+		// The token is handed to the user, who must not learn of a shadow ban
+		if ban.Kind == models.BanKindShadowBan {
+			continue
+		}
+		tokenBan := PermissionTokenBan{
+			ID:           ban.ID,
+			Kind:         ban.Kind,
+			Blocks:       ban.Blocks(),
+			ResourceType: ban.ResourceType,
+			ResourceID:   ban.ResourceID,
+		}
+		if !ban.IsGlobal() {
+			tokenBan.Permission = ban.Permission.Name
+		}
+		if ban.ExpiresAt != nil {
+			tokenBan.ExpiresAt = ban.ExpiresAt.Unix()
+		}
+		for _, exemption := range ban.Exemptions {
+			tokenBan.Exemptions = append(tokenBan.Exemptions, exemption.Name)
+		}
+		claims.Bans = append(claims.Bans, tokenBan)
+	}
+
+	key := s.config.Keys.Current()
+	token := jwt.NewWithClaims(jwt.SigningMethodES256, claims)
+	token.Header["kid"] = key.ID
+
+	signed, err := token.SignedString(key.Private)
+	if err != nil {
+		return nil, fmt.Errorf("failed to sign permission token: %w", err)
+	}
+
+	return &IssuedPermissionToken{Token: signed, ExpiresAt: expiresAt}, nil
+}
+
+// PublicKeys returns the previous, current and upcoming signing keys
+func (s *PermissionTokenService) PublicKeys() []auth.JWK {
+	if s.config.Keys == nil {
+		return []auth.JWK{}
+	}
+
+	published := s.config.Keys.Published()
+	keys := make([]auth.JWK, 0, len(published))
+	for _, key := range published {
+		keys = append(keys, key.PublicJWK())
+	}
+	return keys
+}
+
+// RunSigningKeyRotation rotates the permission token signing keys as they come due until ctx is cancelled
+func RunSigningKeyRotation(ctx context.Context, keys *auth.KeyStore) {
+	ticker := time.NewTicker(signingKeyRotationCheck)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case now := <-ticker.C:
+			rotated, err := keys.RotateIfDue(now)
+			if err != nil {
+				log.Printf("Signing key rotation: %v", err)
+				continue
+			}
+			if rotated {
+				log.Printf("Signing key rotation: published a new permission token key")
+			}
+		}
+	}
+}
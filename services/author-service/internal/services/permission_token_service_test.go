@@ -0,0 +1,172 @@
+package services
+
+import (
+	"testing"
+	"time"
+
+	"gin/internal/auth"
+	"gin/internal/models"
+	"gin/internal/repositories"
+
+	"github.com/golang-jwt/jwt/v5"
+)
+
+var tokenTestPermissions = []models.Permission{
+	{PermID: 1, Name: "*"},
+	{PermID: 2, Name: "room:*"},
+	{PermID: 3, Name: "room:create"},
+	{PermID: 4, Name: "room:join"},
+	{PermID: 5, Name: "moderation:*"},
+	{PermID: 6, Name: "moderation:ban"},
+	{PermID: 7, Name: "moderation:unban"},
+}
+
+// grantRows returns the rows of a grant of permission through role to alice, one for every
+// permission it covers
+func grantRows(role, permission, effect string, scope models.ResourceScope) []repositories.PermissionGrant {
+	var rows []repositories.PermissionGrant
+	for _, covered := range tokenTestPermissions {
+		if models.PermissionMatches(permission, covered.Name) {
+			rows = append(rows, repositories.PermissionGrant{
+				UserID:       "alice",
+				PermID:       covered.PermID,
+				PermName:     covered.Name,
+				RoleName:     role,
+				GrantedAs:    permission,
+				ResourceType: scope.ResourceType,
+				ResourceID:   scope.ResourceID,
+				Effect:       effect,
+			})
+		}
+	}
+	return rows
+}
+
+// banRow returns the row of a ban of alice from permission
+func banRow(permission string, kind string) repositories.MatchedBan {
+	for _, candidate := range tokenTestPermissions {
+		if candidate.Name == permission {
+			return repositories.MatchedBan{
+				PermID: candidate.PermID,
+				Ban:    models.UserBan{ID: 1, UserID: "alice", PermID: &candidate.PermID, Permission: candidate, Kind: kind},
+			}
+		}
+	}
+	panic("unknown permission " + permission)
+}
+
+func issueTestToken(t *testing.T, grants []repositories.PermissionGrant, bans []repositories.MatchedBan, scope models.ResourceScope) PermissionTokenClaims {
+	keys, err := auth.OpenKeyStore(t.TempDir(), 24*time.Hour, time.Hour)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	userBanRepo := &fakeUserBanRepository{bans: bans}
+	authorizationService := NewAuthorizationService(userBanRepo, &fakeUserRoleRepository{grants: grants}, nil, &fakeUserAttributeRepository{}, nil)
+	tokens := NewPermissionTokenService(authorizationService, userBanRepo, PermissionTokenConfig{Keys: keys})
+
+	issued, err := tokens.IssueToken("alice", scope)
+	if err != nil {
+		t.Fatalf("IssueToken() error = %v", err)
+	}
+
+	var claims PermissionTokenClaims
+	_, err = jwt.ParseWithClaims(issued.Token, &claims, func(*jwt.Token) (interface{}, error) {
+		return &keys.Current().Private.PublicKey, nil
+	})
+	if err != nil {
+		t.Fatalf("failed to parse token: %v", err)
+	}
+	return claims
+}
+
+func TestIssueTokenLeavesOutWildcardsCoveringWithheldPermissions(t *testing.T) {
+	global := models.ResourceScope{}
+	room := models.ResourceScope{ResourceType: "room", ResourceID: "7"}
+
+	tests := []struct {
+		name    string
+		grants  [][]repositories.PermissionGrant
+		bans    []repositories.MatchedBan
+		scope   models.ResourceScope
+		want    []string
+		notWant []string
+	}{
+		{
+			name: "admin also holding restricted",
+			grants: [][]repositories.PermissionGrant{
+				grantRows("admin", "*", models.GrantEffectAllow, global),
+				grantRows("restricted", "room:create", models.GrantEffectDeny, global),
+			},
+			scope:   global,
+			want:    []string{"moderation:*", "moderation:ban", "room:join"},
+			notWant: []string{"*", "room:*", "room:create"},
+		},
+		{
+			name: "banned from a permission a family wildcard covers",
+			grants: [][]repositories.PermissionGrant{
+				grantRows("moderator", "moderation:*", models.GrantEffectAllow, global),
+			},
+			bans:    []repositories.MatchedBan{banRow("moderation:ban", models.BanKindSuspension)},
+			scope:   global,
+			want:    []string{"moderation:unban"},
+			notWant: []string{"moderation:*", "moderation:ban"},
+		},
+		{
+			name: "denial outside the token scope",
+			grants: [][]repositories.PermissionGrant{
+				grantRows("admin", "*", models.GrantEffectAllow, global),
+				grantRows("restricted", "room:create", models.GrantEffectDeny, models.ResourceScope{ResourceType: "room", ResourceID: "8"}),
+			},
+			scope: room,
+			want:  []string{"*", "room:*", "room:create"},
+		},
+		{
+			name: "ban that does not withhold the permission",
+			grants: [][]repositories.PermissionGrant{
+				grantRows("admin", "*", models.GrantEffectAllow, global),
+			},
+			bans:  []repositories.MatchedBan{banRow("room:create", models.BanKindWarning)},
+			scope: global,
+			want:  []string{"*", "room:create"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var grants []repositories.PermissionGrant
+			for _, rows := range tt.grants {
+				grants = append(grants, rows...)
+			}
+			claims := issueTestToken(t, grants, tt.bans, tt.scope)
+
+			held := map[string]bool{}
+			for _, permission := range claims.Permissions {
+				held[permission] = true
+			}
+			for _, permission := range tt.want {
+				if !held[permission] {
+					t.Errorf("permissions %v lack %s", claims.Permissions, permission)
+				}
+			}
+			for _, permission := range tt.notWant {
+				if held[permission] {
+					t.Errorf("permissions %v include %s", claims.Permissions, permission)
+				}
+			}
+		})
+	}
+}
+
+func TestIssueTokenHidesShadowBans(t *testing.T) {
+	grants := grantRows("player", "room:*", models.GrantEffectAllow, models.ResourceScope{})
+	shadowBan := banRow("room:create", models.BanKindShadowBan)
+	mute := banRow("room:join", models.BanKindMute)
+	mute.Ban.ID = 2
+
+	claims := issueTestToken(t, grants, []repositories.MatchedBan{shadowBan, mute}, models.ResourceScope{})
+
+	if len(claims.Bans) != 1 || claims.Bans[0].ID != mute.Ban.ID {
+		t.Errorf("bans = %+v, want only the mute", claims.Bans)
+	}
+}
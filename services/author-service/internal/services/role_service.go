@@ -1,40 +1,79 @@
 package services
 
 import (
+	"context"
+	"errors"
 	"fmt"
+	"gin/internal/conditions"
 	"gin/internal/models"
 	"gin/internal/repositories"
+	"gin/pkg/changes"
 )
 
 // RoleServiceInterface defines business logic for roles
 type RoleServiceInterface interface {
-	CreateRole(name string) (*models.Role, error)
+	CreateRole(ctx context.Context, name string) (*models.Role, error)
 	GetRoleByID(id uint) (*models.Role, error)
 	GetRoleByName(name string) (*models.Role, error)
 	GetAllRoles() ([]models.Role, error)
-	UpdateRole(role *models.Role) error
-	DeleteRole(id uint) error
+	UpdateRole(ctx context.Context, role *models.Role) error
+	DeleteRole(ctx context.Context, id uint) error
 	GetRoleWithPermissions(id uint) (*models.Role, error)
-	AddPermissionToRole(roleID, permissionID uint) error
-	RemovePermissionFromRole(roleID, permissionID uint) error
+	AddPermissionToRole(ctx context.Context, grant *models.RolePermission) error
+	RemovePermissionFromRole(ctx context.Context, roleID, permissionID uint, scope models.ResourceScope) error
+	GetInheritedPermissions(roleID uint) ([]repositories.InheritedPermission, error)
+	GetParentRoles(roleID uint) ([]models.Role, error)
+	AddParentRole(ctx context.Context, roleID, parentID uint) error
+	RemoveParentRole(ctx context.Context, roleID, parentID uint) error
 }
 
 // RoleService implements RoleServiceInterface
 type RoleService struct {
 	roleRepo       repositories.RoleRepositoryInterface
 	permissionRepo repositories.PermissionRepositoryInterface
+	evaluator      *conditions.Evaluator
+	transactor     repositories.Transactor
+	cache          AuthorizationCacheInvalidator
+	publisher      ChangePublisher
 }
 
-// NewRoleService creates a new role service
-func NewRoleService(roleRepo repositories.RoleRepositoryInterface, permissionRepo repositories.PermissionRepositoryInterface) RoleServiceInterface {
+// NewRoleService creates a new role service; changes are made and audited through transactor.
+// Once they commit, the cached grants of the users they affect are dropped and they are
+// announced through publisher.
+func NewRoleService(roleRepo repositories.RoleRepositoryInterface, permissionRepo repositories.PermissionRepositoryInterface, evaluator *conditions.Evaluator, transactor repositories.Transactor, cache AuthorizationCacheInvalidator, publisher ChangePublisher) RoleServiceInterface {
 	return &RoleService{
 		roleRepo:       roleRepo,
 		permissionRepo: permissionRepo,
+		evaluator:      evaluator,
+		transactor:     transactor,
+		cache:          cache,
+		publisher:      publisher,
 	}
 }
 
+// changeRole runs change in a transaction and then drops the cached grants of every user who
+// holds the role or inherits from it, and announces the change. The users are looked up in the
+// transaction before change runs, so that a deleted role's members are still found.
+func (s *RoleService) changeRole(ctx context.Context, roleID uint, operation string, change func(tx *repositories.Repositories) error) error {
+	var affected []string
+	err := s.transactor.Transaction(func(tx *repositories.Repositories) error {
+		var err error
+		if affected, err = tx.Role.GetInheritingUserIDs(roleID); err != nil {
+			return fmt.Errorf("failed to find users of role: %w", err)
+		}
+		return change(tx)
+	})
+	if err != nil {
+		return err
+	}
+
+	s.cache.InvalidateGrants(ctx, affected...)
+	s.publisher.Publish(ctx, changeEvent(changes.EntityRole, roleID, "", operation))
+	return nil
+}
+
 // CreateRole creates a new role with validation
-func (s *RoleService) CreateRole(name string) (*models.Role, error) {
+func (s *RoleService) CreateRole(ctx context.Context, name string) (*models.Role, error) {
 	if name == "" {
 		return nil, fmt.Errorf("role name cannot be empty")
 	}
@@ -49,10 +88,20 @@ func (s *RoleService) CreateRole(name string) (*models.Role, error) {
 		Name: name,
 	}
 
-	if err := s.roleRepo.Create(role); err != nil {
-		return nil, fmt.Errorf("failed to create role: %w", err)
+	err = s.transactor.Transaction(func(tx *repositories.Repositories) error {
+		if err := tx.Role.Create(role); err != nil {
+			return fmt.Errorf("failed to create role: %w", err)
+		}
+		if err := recordEvent(ctx, tx, models.EventRoleCreated, models.AggregateRole, auditID(role.RoleID), role); err != nil {
+			return err
+		}
+		return recordChange(ctx, tx, models.AuditActionRoleCreate, models.AuditTargetRole, auditID(role.RoleID), nil, role)
+	})
+	if err != nil {
+		return nil, err
 	}
 
+	s.publisher.Publish(ctx, changeEvent(changes.EntityRole, role.RoleID, "", changes.OperationCreate))
 	return role, nil
 }
 
@@ -90,7 +139,7 @@ func (s *RoleService) GetAllRoles() ([]models.Role, error) {
 }
 
 // UpdateRole updates an existing role
-func (s *RoleService) UpdateRole(role *models.Role) error {
+func (s *RoleService) UpdateRole(ctx context.Context, role *models.Role) error {
 	if role == nil {
 		return fmt.Errorf("role cannot be nil")
 	}
@@ -110,23 +159,41 @@ func (s *RoleService) UpdateRole(role *models.Role) error {
 		return fmt.Errorf("role with name '%s' already exists", role.Name)
 	}
 
+	before := *existingRole
 	existingRole.Name = role.Name
-	return s.roleRepo.Update(existingRole)
+
+	return s.changeRole(ctx, role.RoleID, changes.OperationUpdate, func(tx *repositories.Repositories) error {
+		if err := tx.Role.Update(existingRole); err != nil {
+			return err
+		}
+		if err := recordEvent(ctx, tx, models.EventRoleUpdated, models.AggregateRole, auditID(role.RoleID), existingRole); err != nil {
+			return err
+		}
+		return recordChange(ctx, tx, models.AuditActionRoleUpdate, models.AuditTargetRole, auditID(role.RoleID), before, existingRole)
+	})
 }
 
 // DeleteRole deletes a role
-func (s *RoleService) DeleteRole(id uint) error {
+func (s *RoleService) DeleteRole(ctx context.Context, id uint) error {
 	if id == 0 {
 		return fmt.Errorf("invalid role ID")
 	}
 
-	// Check if role exists
-	_, err := s.roleRepo.GetByID(id)
+	// Check if role exists; its grants are kept in the audit log
+	role, err := s.roleRepo.GetWithPermissions(id)
 	if err != nil {
 		return fmt.Errorf("role not found: %w", err)
 	}
 
-	return s.roleRepo.Delete(id)
+	return s.changeRole(ctx, id, changes.OperationDelete, func(tx *repositories.Repositories) error {
+		if err := tx.Role.Delete(id); err != nil {
+			return err
+		}
+		if err := recordEvent(ctx, tx, models.EventRoleDeleted, models.AggregateRole, auditID(id), role); err != nil {
+			return err
+		}
+		return recordChange(ctx, tx, models.AuditActionRoleDelete, models.AuditTargetRole, auditID(id), role, nil)
+	})
 }
 
 // GetRoleWithPermissions retrieves a role with its permissions
@@ -143,71 +210,177 @@ func (s *RoleService) GetRoleWithPermissions(id uint) (*models.Role, error) {
 	return role, nil
 }
 
-// AddPermissionToRole adds a permission to a role
-func (s *RoleService) AddPermissionToRole(roleID, permissionID uint) error {
-	if roleID == 0 || permissionID == 0 {
+// AddPermissionToRole grants a permission to a role, globally or within a resource scope.
+// A condition, if given, must compile before the grant is stored. Grants allow unless their
+// effect is deny.
+func (s *RoleService) AddPermissionToRole(ctx context.Context, grant *models.RolePermission) error {
+	if grant == nil {
+		return fmt.Errorf("grant cannot be nil")
+	}
+
+	if grant.RoleID == 0 || grant.PermID == 0 {
 		return fmt.Errorf("invalid role ID or permission ID")
 	}
 
+	if err := grant.Scope().Validate(); err != nil {
+		return err
+	}
+
+	if grant.Effect == "" {
+		grant.Effect = models.GrantEffectAllow
+	}
+	if !models.IsValidGrantEffect(grant.Effect) {
+		return fmt.Errorf("grant effect must be '%s' or '%s'", models.GrantEffectAllow, models.GrantEffectDeny)
+	}
+
+	if grant.Condition != "" {
+		if err := s.evaluator.Validate(grant.Condition); err != nil {
+			return err
+		}
+	}
+
 	// Verify role exists
-	_, err := s.roleRepo.GetByID(roleID)
+	_, err := s.roleRepo.GetByID(grant.RoleID)
 	if err != nil {
 		return fmt.Errorf("role not found: %w", err)
 	}
 
 	// Verify permission exists
-	_, err = s.permissionRepo.GetByID(permissionID)
+	_, err = s.permissionRepo.GetByID(grant.PermID)
 	if err != nil {
 		return fmt.Errorf("permission not found: %w", err)
 	}
 
-	// Check if permission is already assigned to role
-	roleWithPermissions, err := s.roleRepo.GetWithPermissions(roleID)
-	if err != nil {
-		return fmt.Errorf("failed to get role permissions: %w", err)
+	// Check if permission is already assigned to role in this scope
+	existingGrant, err := s.roleRepo.GetGrant(grant.RoleID, grant.PermID, grant.Scope())
+	if err == nil && existingGrant != nil {
+		return fmt.Errorf("permission already assigned to role")
 	}
 
-	for _, permission := range roleWithPermissions.Permissions {
-		if permission.PermID == permissionID {
-			return fmt.Errorf("permission already assigned to role")
+	return s.changeRole(ctx, grant.RoleID, changes.OperationUpdate, func(tx *repositories.Repositories) error {
+		if err := tx.Role.AddPermission(grant); err != nil {
+			return fmt.Errorf("failed to add permission to role: %w", err)
 		}
-	}
-
-	// Add permission using GORM association
-	// Since we removed AddPermission from repository, we'll use a different approach
-	permission, _ := s.permissionRepo.GetByID(permissionID)
-	roleWithPermissions.Permissions = append(roleWithPermissions.Permissions, *permission)
-	
-	return s.roleRepo.Update(roleWithPermissions)
+		if err := recordEvent(ctx, tx, models.EventRolePermissionAdded, models.AggregateRole, auditID(grant.RoleID), grant); err != nil {
+			return err
+		}
+		return recordChange(ctx, tx, models.AuditActionRoleGrant, models.AuditTargetRole, auditID(grant.RoleID), nil, grant)
+	})
 }
 
-// RemovePermissionFromRole removes a permission from a role
-func (s *RoleService) RemovePermissionFromRole(roleID, permissionID uint) error {
+// RemovePermissionFromRole removes a permission grant in the given scope from a role
+func (s *RoleService) RemovePermissionFromRole(ctx context.Context, roleID, permissionID uint, scope models.ResourceScope) error {
 	if roleID == 0 || permissionID == 0 {
 		return fmt.Errorf("invalid role ID or permission ID")
 	}
 
 	// Verify role exists
-	roleWithPermissions, err := s.roleRepo.GetWithPermissions(roleID)
+	if _, err := s.roleRepo.GetByID(roleID); err != nil {
+		return fmt.Errorf("role not found: %w", err)
+	}
+
+	grant, err := s.roleRepo.GetGrant(roleID, permissionID, scope)
+	if err != nil {
+		return fmt.Errorf("permission not assigned to role: %w", err)
+	}
+
+	return s.changeRole(ctx, roleID, changes.OperationUpdate, func(tx *repositories.Repositories) error {
+		if err := tx.Role.RemovePermission(roleID, permissionID, scope); err != nil {
+			return fmt.Errorf("permission not assigned to role: %w", err)
+		}
+		if err := recordEvent(ctx, tx, models.EventRolePermissionRemoved, models.AggregateRole, auditID(roleID), grant); err != nil {
+			return err
+		}
+		return recordChange(ctx, tx, models.AuditActionRoleRevoke, models.AuditTargetRole, auditID(roleID), grant, nil)
+	})
+}
+
+// GetInheritedPermissions retrieves the permissions a role receives from its ancestors
+func (s *RoleService) GetInheritedPermissions(roleID uint) ([]repositories.InheritedPermission, error) {
+	if roleID == 0 {
+		return nil, fmt.Errorf("invalid role ID")
+	}
+
+	permissions, err := s.roleRepo.GetInheritedPermissions(roleID)
 	if err != nil {
+		return nil, fmt.Errorf("failed to get inherited permissions: %w", err)
+	}
+
+	return permissions, nil
+}
+
+// GetParentRoles retrieves the roles a role directly inherits from
+func (s *RoleService) GetParentRoles(roleID uint) ([]models.Role, error) {
+	if roleID == 0 {
+		return nil, fmt.Errorf("invalid role ID")
+	}
+
+	if _, err := s.roleRepo.GetByID(roleID); err != nil {
+		return nil, fmt.Errorf("role not found: %w", err)
+	}
+
+	return s.roleRepo.GetParents(roleID)
+}
+
+// AddParentRole makes a role inherit every permission of another role
+func (s *RoleService) AddParentRole(ctx context.Context, roleID, parentID uint) error {
+	if roleID == 0 || parentID == 0 {
+		return fmt.Errorf("invalid role ID or parent role ID")
+	}
+
+	// Verify both roles exist
+	if _, err := s.roleRepo.GetByID(roleID); err != nil {
 		return fmt.Errorf("role not found: %w", err)
 	}
 
-	// Find and remove the permission
-	var updatedPermissions []models.Permission
-	found := false
-	for _, permission := range roleWithPermissions.Permissions {
-		if permission.PermID != permissionID {
-			updatedPermissions = append(updatedPermissions, permission)
-		} else {
-			found = true
+	if _, err := s.roleRepo.GetByID(parentID); err != nil {
+		return fmt.Errorf("parent role not found: %w", err)
+	}
+
+	// Check if the parent is already assigned
+	parents, err := s.roleRepo.GetParents(roleID)
+	if err != nil {
+		return fmt.Errorf("failed to get parent roles: %w", err)
+	}
+
+	for _, parent := range parents {
+		if parent.RoleID == parentID {
+			return fmt.Errorf("parent role already assigned to role")
 		}
 	}
 
-	if !found {
-		return fmt.Errorf("permission not assigned to role")
+	parent := map[string]uint{"role_id": roleID, "parent_id": parentID}
+	return s.changeRole(ctx, roleID, changes.OperationUpdate, func(tx *repositories.Repositories) error {
+		if err := tx.Role.AddParent(roleID, parentID); err != nil {
+			if errors.Is(err, repositories.ErrRoleHierarchyCycle) {
+				return fmt.Errorf("role cannot inherit from itself or one of its descendants")
+			}
+			if errors.Is(err, repositories.ErrRoleHierarchyTooDeep) {
+				return fmt.Errorf("role hierarchy cannot be deeper than %d levels", repositories.MaxRoleHierarchyDepth)
+			}
+			return fmt.Errorf("failed to add parent role: %w", err)
+		}
+		if err := recordEvent(ctx, tx, models.EventRoleParentAdded, models.AggregateRole, auditID(roleID), parent); err != nil {
+			return err
+		}
+		return recordChange(ctx, tx, models.AuditActionRoleAddParent, models.AuditTargetRole, auditID(roleID), nil, parent)
+	})
+}
+
+// RemoveParentRole stops a role from inheriting from another role
+func (s *RoleService) RemoveParentRole(ctx context.Context, roleID, parentID uint) error {
+	if roleID == 0 || parentID == 0 {
+		return fmt.Errorf("invalid role ID or parent role ID")
 	}
 
-	roleWithPermissions.Permissions = updatedPermissions
-	return s.roleRepo.Update(roleWithPermissions)
-}
\ No newline at end of file
+	parent := map[string]uint{"role_id": roleID, "parent_id": parentID}
+	return s.changeRole(ctx, roleID, changes.OperationUpdate, func(tx *repositories.Repositories) error {
+		if err := tx.Role.RemoveParent(roleID, parentID); err != nil {
+			return fmt.Errorf("parent role not assigned to role: %w", err)
+		}
+		if err := recordEvent(ctx, tx, models.EventRoleParentRemoved, models.AggregateRole, auditID(roleID), parent); err != nil {
+			return err
+		}
+		return recordChange(ctx, tx, models.AuditActionRoleRemoveParent, models.AuditTargetRole, auditID(roleID), parent, nil)
+	})
+}
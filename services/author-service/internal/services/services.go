@@ -1,21 +1,84 @@
 package services
 
 import (
+	"context"
+	"crypto/ed25519"
+	"gin/internal/conditions"
 	"gin/internal/repositories"
 )
 
 // Services holds all service instances
 type Services struct {
-	Role       RoleServiceInterface
-	Permission PermissionServiceInterface
-	UserBan    UserBanServiceInterface
+	Role            RoleServiceInterface
+	Permission      PermissionServiceInterface
+	UserBan         UserBanServiceInterface
+	UserRole        UserRoleServiceInterface
+	Authorization   AuthorizationServiceInterface
+	UserAttribute   UserAttributeServiceInterface
+	BanReason       BanReasonServiceInterface
+	BanAppeal       BanAppealServiceInterface
+	AuditLog        AuditLogServiceInterface
+	APIKey          APIKeyServiceInterface
+	PermissionToken PermissionTokenServiceInterface
+	Outbox          OutboxServiceInterface
 }
 
+// Config holds the settings services are created with
+type Config struct {
+	StrikePolicy StrikePolicy
+	// AuditSigningKey signs audit checkpoints; nil disables them
+	AuditSigningKey  ed25519.PrivateKey
+	PermissionTokens PermissionTokenConfig
+	// AuthorizationCache is told about changes that make cached ban checks and grants stale;
+	// nil when nothing is cached
+	AuthorizationCache AuthorizationCacheInvalidator
+	// Changes announces committed changes to other services; nil announces nothing
+	Changes ChangePublisher
+	Outbox  OutboxConfig
+}
+
+// AuthorizationCacheInvalidator drops cached authorization data once a change to it commits
+type AuthorizationCacheInvalidator interface {
+	InvalidateBans(ctx context.Context, userIDs ...string)
+	InvalidateGrants(ctx context.Context, userIDs ...string)
+	// InvalidateAll drops everything, for changes to the permission catalogue that affect how
+	// wildcards match
+	InvalidateAll(ctx context.Context)
+}
+
+// noCache is the invalidator used when nothing is cached
+type noCache struct{}
+
+func (noCache) InvalidateBans(ctx context.Context, userIDs ...string)   {}
+func (noCache) InvalidateGrants(ctx context.Context, userIDs ...string) {}
+func (noCache) InvalidateAll(ctx context.Context)                       {}
+
 // NewServices creates and returns all service instances
-func NewServices(repos *repositories.Repositories) *Services {
+func NewServices(repos *repositories.Repositories, evaluator *conditions.Evaluator, config Config) *Services {
+	cache := config.AuthorizationCache
+	if cache == nil {
+		cache = noCache{}
+	}
+	publisher := config.Changes
+	if publisher == nil {
+		publisher = noChanges{}
+	}
+
+	userBan := NewUserBanService(repos.UserBan, repos.Permission, repos.BanReason, config.StrikePolicy, repos, cache, publisher)
+	authorization := NewAuthorizationService(repos.UserBan, repos.UserRole, repos.Permission, repos.UserAttribute, evaluator)
+
 	return &Services{
-		Role:       NewRoleService(repos.Role, repos.Permission),
-		Permission: NewPermissionService(repos.Permission),
-		UserBan:    NewUserBanService(repos.UserBan, repos.Permission),
+		Role:            NewRoleService(repos.Role, repos.Permission, evaluator, repos, cache, publisher),
+		Permission:      NewPermissionService(repos.Permission, repos, cache, publisher),
+		UserBan:         userBan,
+		UserRole:        NewUserRoleService(repos.UserRole, repos.Role, repos, cache, publisher),
+		Authorization:   authorization,
+		UserAttribute:   NewUserAttributeService(repos.UserAttribute),
+		BanReason:       NewBanReasonService(repos.BanReason),
+		BanAppeal:       NewBanAppealService(repos.BanAppeal, repos.UserBan, repos, cache, publisher),
+		AuditLog:        NewAuditLogService(repos.AuditLog, config.AuditSigningKey),
+		APIKey:          NewAPIKeyService(repos.APIKey, authorization, repos),
+		PermissionToken: NewPermissionTokenService(authorization, repos.UserBan, config.PermissionTokens),
+		Outbox:          NewOutboxService(repos.Outbox, config.Outbox),
 	}
-}
\ No newline at end of file
+}
@@ -0,0 +1,161 @@
+package services
+
+import (
+	"encoding/json"
+	"fmt"
+	"gin/internal/models"
+	"math"
+	"sort"
+	"time"
+)
+
+// DefaultStrikeHalfLife is how long it takes the strike points of a ban to lose half their weight
+const DefaultStrikeHalfLife = 30 * 24 * time.Hour
+
+// strikeHorizonHalfLives bounds the strike history read for a user; after ten half-lives a
+// ban's points have decayed below a thousandth of their original weight
+const strikeHorizonHalfLives = 10
+
+// DefaultStrikePoints is the number of strike points each ban kind adds
+var DefaultStrikePoints = map[string]int{
+	models.BanKindWarning:     1,
+	models.BanKindRestriction: 2,
+	models.BanKindShadowBan:   3,
+	models.BanKindMute:        3,
+	models.BanKindSuspension:  5,
+}
+
+// EscalationRule turns a ban into a harsher one once the user's strike total reaches Threshold
+type EscalationRule struct {
+	Name      string
+	Threshold float64
+	// Kind is the least severe kind the escalated ban can have
+	Kind string
+	// Duration is the shortest duration the escalated ban can have; zero keeps the requested one
+	Duration time.Duration
+	// Permanent removes the expiry of the escalated ban
+	Permanent bool
+	// Global turns the escalated ban into a global ban
+	Global bool
+}
+
+// StrikePolicy decides how strike points decay and how accumulated strikes escalate bans
+type StrikePolicy struct {
+	HalfLife time.Duration
+	Points   map[string]int
+	// Rules are ordered by ascending threshold; the highest rule reached fires
+	Rules []EscalationRule
+}
+
+// DefaultStrikePolicy returns the policy used when none is configured
+func DefaultStrikePolicy() StrikePolicy {
+	return StrikePolicy{
+		HalfLife: DefaultStrikeHalfLife,
+		Points:   DefaultStrikePoints,
+		Rules: []EscalationRule{
+			{Name: "repeat_offender", Threshold: 10, Kind: models.BanKindSuspension, Duration: 7 * 24 * time.Hour},
+			{Name: "habitual_offender", Threshold: 20, Kind: models.BanKindSuspension, Permanent: true, Global: true},
+		},
+	}
+}
+
+type escalationRuleConfig struct {
+	Name      string  `json:"name"`
+	Threshold float64 `json:"threshold"`
+	Kind      string  `json:"kind"`
+	Duration  string  `json:"duration"`
+	Permanent bool    `json:"permanent"`
+	Global    bool    `json:"global"`
+}
+
+// ParseStrikePolicy builds a policy from a half-life such as "30d" and a JSON array of rules like
+// [{"name":"repeat_offender","threshold":10,"kind":"suspension","duration":"7d"}].
+// Empty values keep the defaults.
+func ParseStrikePolicy(halfLife, rulesJSON string) (StrikePolicy, error) {
+	policy := DefaultStrikePolicy()
+
+	if halfLife != "" {
+		duration, err := ParseBanDuration(halfLife)
+		if err != nil {
+			return policy, fmt.Errorf("invalid strike half-life: %w", err)
+		}
+		policy.HalfLife = duration
+	}
+
+	if rulesJSON == "" {
+		return policy, nil
+	}
+
+	var configs []escalationRuleConfig
+	if err := json.Unmarshal([]byte(rulesJSON), &configs); err != nil {
+		return policy, fmt.Errorf("invalid escalation rules: %w", err)
+	}
+
+	rules := make([]EscalationRule, 0, len(configs))
+	for _, config := range configs {
+		rule := EscalationRule{
+			Name:      config.Name,
+			Threshold: config.Threshold,
+			Kind:      config.Kind,
+			Permanent: config.Permanent,
+			Global:    config.Global,
+		}
+
+		if rule.Name == "" {
+			return policy, fmt.Errorf("escalation rule name cannot be empty")
+		}
+		if rule.Threshold <= 0 {
+			return policy, fmt.Errorf("escalation rule %s must have a positive threshold", rule.Name)
+		}
+		if rule.Kind != "" && !models.IsValidBanKind(rule.Kind) {
+			return policy, fmt.Errorf("escalation rule %s has invalid ban kind '%s'", rule.Name, rule.Kind)
+		}
+		if config.Duration != "" {
+			duration, err := ParseBanDuration(config.Duration)
+			if err != nil {
+				return policy, fmt.Errorf("escalation rule %s: %w", rule.Name, err)
+			}
+			rule.Duration = duration
+		}
+
+		rules = append(rules, rule)
+	}
+
+	sort.SliceStable(rules, func(i, j int) bool { return rules[i].Threshold < rules[j].Threshold })
+	policy.Rules = rules
+	return policy, nil
+}
+
+// PointsFor returns the strike points a ban of the given kind adds
+func (p StrikePolicy) PointsFor(kind string) int {
+	return p.Points[kind]
+}
+
+// Horizon is the oldest ban time whose strike points still count
+func (p StrikePolicy) Horizon(now time.Time) time.Time {
+	return now.Add(-strikeHorizonHalfLives * p.HalfLife)
+}
+
+// Total sums the strike points of the given bans, each halved for every half-life it has aged
+func (p StrikePolicy) Total(bans []models.UserBan, now time.Time) float64 {
+	total := 0.0
+	for _, ban := range bans {
+		age := now.Sub(ban.CreatedAt)
+		if age < 0 {
+			age = 0
+		}
+		total += float64(ban.StrikePoints) * math.Pow(0.5, float64(age)/float64(p.HalfLife))
+	}
+	return total
+}
+
+// RuleFor returns the rule with the highest threshold the total reaches, or nil
+func (p StrikePolicy) RuleFor(total float64) *EscalationRule {
+	var fired *EscalationRule
+	for i := range p.Rules {
+		if total >= p.Rules[i].Threshold {
+			fired = &p.Rules[i]
+		}
+	}
+	return fired
+}
@@ -0,0 +1,104 @@
+package services
+
+import (
+	"encoding/json"
+	"fmt"
+	"gin/internal/models"
+	"gin/internal/repositories"
+	"regexp"
+	"time"
+)
+
+// attributeKeyPattern keeps keys usable as field selections in conditions, e.g. attrs.account_age_days
+var attributeKeyPattern = regexp.MustCompile(`^[a-z_][a-z0-9_]{0,99}$`)
+
+type UserAttributeServiceInterface interface {
+	GetAttributes(userID string) (map[string]interface{}, error)
+	SetAttributes(userID string, attributes map[string]interface{}) error
+	DeleteAttribute(userID, key string) error
+}
+
+type UserAttributeService struct {
+	userAttributeRepo repositories.UserAttributeRepositoryInterface
+}
+
+func NewUserAttributeService(userAttributeRepo repositories.UserAttributeRepositoryInterface) UserAttributeServiceInterface {
+	return &UserAttributeService{
+		userAttributeRepo: userAttributeRepo,
+	}
+}
+
+func (s *UserAttributeService) GetAttributes(userID string) (map[string]interface{}, error) {
+	if userID == "" {
+		return nil, fmt.Errorf("user ID cannot be empty")
+	}
+
+	attributes, err := s.userAttributeRepo.GetByUserID(userID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get user attributes: %w", err)
+	}
+
+	return decodeAttributes(attributes), nil
+}
+
+// SetAttributes creates or replaces the given attributes, leaving the others untouched
+func (s *UserAttributeService) SetAttributes(userID string, attributes map[string]interface{}) error {
+	if userID == "" {
+		return fmt.Errorf("user ID cannot be empty")
+	}
+
+	if len(attributes) == 0 {
+		return fmt.Errorf("at least one attribute is required")
+	}
+
+	now := time.Now()
+	rows := make([]models.UserAttribute, 0, len(attributes))
+	for key, value := range attributes {
+		if !attributeKeyPattern.MatchString(key) {
+			return fmt.Errorf("invalid attribute key '%s'", key)
+		}
+
+		encoded, err := json.Marshal(value)
+		if err != nil {
+			return fmt.Errorf("invalid value for attribute '%s': %w", key, err)
+		}
+
+		rows = append(rows, models.UserAttribute{
+			UserID:    userID,
+			Key:       key,
+			Value:     string(encoded),
+			UpdatedAt: now,
+		})
+	}
+
+	if err := s.userAttributeRepo.Upsert(rows); err != nil {
+		return fmt.Errorf("failed to save user attributes: %w", err)
+	}
+
+	return nil
+}
+
+func (s *UserAttributeService) DeleteAttribute(userID, key string) error {
+	if userID == "" {
+		return fmt.Errorf("user ID cannot be empty")
+	}
+
+	if err := s.userAttributeRepo.Delete(userID, key); err != nil {
+		return fmt.Errorf("attribute not found: %w", err)
+	}
+
+	return nil
+}
+
+// decodeAttributes turns stored attribute rows back into their JSON values, skipping corrupt ones
+func decodeAttributes(attributes []models.UserAttribute) map[string]interface{} {
+	decoded := make(map[string]interface{}, len(attributes))
+	for _, attribute := range attributes {
+		var value interface{}
+		if err := json.Unmarshal([]byte(attribute.Value), &value); err != nil {
+			continue
+		}
+		decoded[attribute.Key] = value
+	}
+	return decoded
+}
@@ -1,75 +1,314 @@
 package services
 
 import (
+	"context"
 	"fmt"
 	"gin/internal/models"
 	"gin/internal/repositories"
+	"gin/internal/requestctx"
+	"gin/pkg/changes"
+	"strconv"
+	"strings"
 	"time"
 )
 
+// BanOptions holds the optional settings of a new ban
+type BanOptions struct {
+	// Scope limits the ban to a resource type or a single resource; the zero value bans globally
+	Scope models.ResourceScope
+	// Duration makes the ban temporary; the zero value bans until the user is unbanned
+	Duration time.Duration
+	// Global bans every permission instead of a single one
+	Global bool
+	// Exemptions lists the permissions a global ban leaves available, such as appealing the ban
+	Exemptions []uint
+	// Kind grades the ban; the zero value is a suspension
+	Kind string
+	// StrikePoints overrides the strike points the policy assigns to the ban's kind
+	StrikePoints *int
+	// ReasonCode picks a catalogued reason whose defaults fill in an unset kind and duration
+	ReasonCode string
+	// Notes is the moderator's free text accompanying a reason code
+	Notes string
+}
+
+// UnbanOptions selects the ban to lift and records why it was lifted
+type UnbanOptions struct {
+	Scope models.ResourceScope
+	// Kind selects the ban to lift when the user has several; empty lifts the latest
+	Kind       string
+	LiftReason string
+}
+
+// BanResult is a new ban together with the strike total it brought the user to and the
+// escalation rule that total fired, if any
+type BanResult struct {
+	Ban         *models.UserBan
+	StrikeTotal float64
+	Escalation  *EscalationRule
+}
+
+// ParseBanDuration parses a ban duration such as "90m", "24h" or "7d"
+func ParseBanDuration(value string) (time.Duration, error) {
+	var duration time.Duration
+	if days, found := strings.CutSuffix(value, "d"); found {
+		n, err := strconv.Atoi(days)
+		if err != nil {
+			return 0, fmt.Errorf("invalid ban duration '%s'", value)
+		}
+		duration = time.Duration(n) * 24 * time.Hour
+	} else {
+		var err error
+		duration, err = time.ParseDuration(value)
+		if err != nil {
+			return 0, fmt.Errorf("invalid ban duration '%s'", value)
+		}
+	}
+
+	if duration <= 0 {
+		return 0, fmt.Errorf("ban duration must be positive")
+	}
+	return duration, nil
+}
+
+// FormatBanDuration formats a duration the way ParseBanDuration reads it, using days when whole
+func FormatBanDuration(duration time.Duration) string {
+	day := 24 * time.Hour
+	if duration > 0 && duration%day == 0 {
+		return strconv.FormatInt(int64(duration/day), 10) + "d"
+	}
+	return duration.String()
+}
+
 type UserBanServiceInterface interface {
-	BanUser(userID string, permissionID uint, reason string) (*models.UserBan, error)
-	UnbanUser(userID string, permissionID uint) error
+	BanUser(ctx context.Context, userID string, permissionID uint, reason string, opts BanOptions) (*BanResult, error)
+	UnbanUser(ctx context.Context, userID string, permissionID uint, opts UnbanOptions) error
+	UnbanUserGlobally(ctx context.Context, userID string, opts UnbanOptions) error
+	LiftBan(ctx context.Context, id uint, reason string) error
 	GetUserBan(id uint) (*models.UserBan, error)
-	GetUserBans(userID string) ([]models.UserBan, error)
-	GetAllUserBans() ([]models.UserBan, error)
-	IsUserBanned(userID string, permissionID uint) (bool, error)
+	GetUserBans(userID string, includeHistory, hideShadowBans bool) ([]models.UserBan, error)
+	GetAllUserBans(filter repositories.UserBanFilter) ([]models.UserBan, error)
+	IsUserBanned(userID string, permissionID uint, scope models.ResourceScope) (bool, error)
+	CheckUserBan(userID string, permissionID uint, scope models.ResourceScope, hideShadowBans bool) (*models.UserBan, error)
 	GetActiveUserBans(userID string) ([]models.UserBan, error)
 	GetRecentBans(days int, limit int) ([]models.UserBan, error)
-	UpdateBanReason(id uint, reason string) error
+	UpdateBanReason(ctx context.Context, id uint, reason string) error
+	ExpireBans(ctx context.Context) (int64, error)
 }
 
 type UserBanService struct {
 	userBanRepo    repositories.UserBanRepositoryInterface
 	permissionRepo repositories.PermissionRepositoryInterface
+	banReasonRepo  repositories.BanReasonRepositoryInterface
+	strikePolicy   StrikePolicy
+	transactor     repositories.Transactor
+	cache          AuthorizationCacheInvalidator
+	publisher      ChangePublisher
 }
 
-func NewUserBanService(userBanRepo repositories.UserBanRepositoryInterface, permissionRepo repositories.PermissionRepositoryInterface) UserBanServiceInterface {
+func NewUserBanService(
+	userBanRepo repositories.UserBanRepositoryInterface,
+	permissionRepo repositories.PermissionRepositoryInterface,
+	banReasonRepo repositories.BanReasonRepositoryInterface,
+	strikePolicy StrikePolicy,
+	transactor repositories.Transactor,
+	cache AuthorizationCacheInvalidator,
+	publisher ChangePublisher,
+) UserBanServiceInterface {
 	return &UserBanService{
 		userBanRepo:    userBanRepo,
 		permissionRepo: permissionRepo,
+		banReasonRepo:  banReasonRepo,
+		strikePolicy:   strikePolicy,
+		transactor:     transactor,
+		cache:          cache,
+		publisher:      publisher,
 	}
 }
 
-func (s *UserBanService) BanUser(userID string, permissionID uint, reason string) (*models.UserBan, error) {
+// BanUser bans a user from a permission, or from every permission when opts.Global is set.
+// The reason is free text, or defaults to the label of opts.ReasonCode.
+// The ban adds strike points to the user's decayed strike total, and the highest escalation
+// rule the new total reaches makes the ban at least as harsh as the rule demands.
+func (s *UserBanService) BanUser(ctx context.Context, userID string, permissionID uint, reason string, opts BanOptions) (*BanResult, error) {
 	if userID == "" {
 		return nil, fmt.Errorf("user ID cannot be empty")
 	}
 
-	if permissionID == 0 {
+	if opts.Global && permissionID != 0 {
+		return nil, fmt.Errorf("a global ban cannot name a permission")
+	}
+
+	if !opts.Global && permissionID == 0 {
 		return nil, fmt.Errorf("invalid permission ID")
 	}
 
+	if !opts.Global && len(opts.Exemptions) > 0 {
+		return nil, fmt.Errorf("only global bans can have exemptions")
+	}
+
+	if opts.ReasonCode != "" {
+		catalogued, err := s.banReasonRepo.GetByCode(opts.ReasonCode)
+		if err != nil {
+			return nil, fmt.Errorf("reason code '%s' not found: %w", opts.ReasonCode, err)
+		}
+		if reason == "" {
+			reason = catalogued.Label
+		}
+		if opts.Kind == "" {
+			opts.Kind = catalogued.DefaultKind
+		}
+		if opts.Duration == 0 {
+			opts.Duration = catalogued.DefaultDuration()
+		}
+	}
+
 	if reason == "" {
-		return nil, fmt.Errorf("ban reason cannot be empty")
+		return nil, fmt.Errorf("ban reason or reason code is required")
 	}
 
-	_, err := s.permissionRepo.GetByID(permissionID)
+	if err := opts.Scope.Validate(); err != nil {
+		return nil, err
+	}
+
+	if opts.Duration < 0 {
+		return nil, fmt.Errorf("ban duration must be positive")
+	}
+
+	if opts.Kind == "" {
+		opts.Kind = models.BanKindSuspension
+	}
+	if !models.IsValidBanKind(opts.Kind) {
+		return nil, fmt.Errorf("invalid ban kind '%s'", opts.Kind)
+	}
+
+	points := s.strikePolicy.PointsFor(opts.Kind)
+	if opts.StrikePoints != nil {
+		if *opts.StrikePoints < 0 {
+			return nil, fmt.Errorf("strike points cannot be negative")
+		}
+		points = *opts.StrikePoints
+	}
+
+	if !opts.Global {
+		if _, err := s.permissionRepo.GetByID(permissionID); err != nil {
+			return nil, fmt.Errorf("permission not found: %w", err)
+		}
+	}
+
+	exemptions, err := s.permissionRepo.GetByIDs(opts.Exemptions)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get exempted permissions: %w", err)
+	}
+	if len(exemptions) != len(uniqueIDs(opts.Exemptions)) {
+		return nil, fmt.Errorf("exempted permission not found")
+	}
+
+	var result *BanResult
+	var userBan *models.UserBan
+	err = s.transactor.Transaction(func(tx *repositories.Repositories) error {
+		// Strike totals and the duplicate check must see every other ban issued to the user
+		if err := tx.UserBan.LockUser(userID); err != nil {
+			return fmt.Errorf("failed to lock bans of user: %w", err)
+		}
+
+		now := time.Now()
+		history, err := tx.UserBan.GetStrikeHistory(userID, s.strikePolicy.Horizon(now))
+		if err != nil {
+			return fmt.Errorf("failed to get strike history: %w", err)
+		}
+
+		result = &BanResult{StrikeTotal: s.strikePolicy.Total(history, now) + float64(points)}
+		result.Escalation = s.strikePolicy.RuleFor(result.StrikeTotal)
+		banOpts := opts
+		if result.Escalation != nil {
+			banOpts = escalate(opts, *result.Escalation)
+		}
+
+		var existingBan *models.UserBan
+		if banOpts.Global {
+			existingBan, err = tx.UserBan.GetGlobalBan(userID, banOpts.Scope, banOpts.Kind)
+		} else {
+			existingBan, err = tx.UserBan.GetByUserIDAndPermission(userID, permissionID, banOpts.Scope, banOpts.Kind)
+		}
+		if err == nil && existingBan != nil {
+			if banOpts.Global {
+				return fmt.Errorf("user already has a global %s", banOpts.Kind)
+			}
+			return fmt.Errorf("user already has a %s for this permission", banOpts.Kind)
+		}
+
+		userBan = &models.UserBan{
+			UserID:       userID,
+			Reason:       reason,
+			Notes:        banOpts.Notes,
+			IssuedBy:     requestctx.Actor(ctx),
+			Kind:         banOpts.Kind,
+			StrikePoints: points,
+			ResourceType: banOpts.Scope.ResourceType,
+			ResourceID:   banOpts.Scope.ResourceID,
+			Exemptions:   exemptions,
+			CreatedAt:    now,
+			UpdatedAt:    now,
+		}
+
+		if result.Escalation != nil {
+			userBan.EscalationRule = result.Escalation.Name
+		}
+
+		if !banOpts.Global {
+			userBan.PermID = &permissionID
+		}
+
+		if banOpts.ReasonCode != "" {
+			userBan.ReasonCode = &banOpts.ReasonCode
+		}
+
+		if banOpts.Duration > 0 {
+			expiresAt := now.Add(banOpts.Duration)
+			userBan.ExpiresAt = &expiresAt
+		}
+
+		if err := tx.UserBan.Create(userBan); err != nil {
+			return fmt.Errorf("failed to create user ban: %w", err)
+		}
+		if err := recordEvent(ctx, tx, models.EventUserBanned, models.AggregateUserBan, auditID(userBan.ID), userBan); err != nil {
+			return err
+		}
+		return recordChange(ctx, tx, models.AuditActionBanCreate, models.AuditTargetUserBan, auditID(userBan.ID), nil, userBan)
+	})
 	if err != nil {
-		return nil, fmt.Errorf("permission not found: %w", err)
+		return nil, err
 	}
+	s.cache.InvalidateBans(ctx, userID)
+	s.publisher.Publish(ctx, changeEvent(changes.EntityUserBan, userBan.ID, userID, changes.OperationCreate))
 
-	existingBan, err := s.userBanRepo.GetByUserIDAndPermission(userID, permissionID)
-	if err == nil && existingBan != nil {
-		return nil, fmt.Errorf("user is already banned for this permission")
+	result.Ban = userBan
+	return result, nil
+}
+
+// escalate makes the ban described by opts at least as harsh as the rule demands
+func escalate(opts BanOptions, rule EscalationRule) BanOptions {
+	if rule.Kind != "" && models.BanKindSeverity(rule.Kind) > models.BanKindSeverity(opts.Kind) {
+		opts.Kind = rule.Kind
 	}
 
-	userBan := &models.UserBan{
-		UserID:    userID,
-		PermID:    permissionID,
-		Reason:    reason,
-		CreatedAt: time.Now(),
-		UpdatedAt: time.Now(),
+	switch {
+	case rule.Permanent:
+		opts.Duration = 0
+	case rule.Duration > 0 && opts.Duration > 0 && opts.Duration < rule.Duration:
+		opts.Duration = rule.Duration
 	}
 
-	if err := s.userBanRepo.Create(userBan); err != nil {
-		return nil, fmt.Errorf("failed to create user ban: %w", err)
+	if rule.Global {
+		opts.Global = true
 	}
 
-	return userBan, nil
+	return opts
 }
 
-func (s *UserBanService) UnbanUser(userID string, permissionID uint) error {
+// UnbanUser lifts a ban of a user on a permission in the given scope; the ban is kept as history
+func (s *UserBanService) UnbanUser(ctx context.Context, userID string, permissionID uint, opts UnbanOptions) error {
 	if userID == "" {
 		return fmt.Errorf("user ID cannot be empty")
 	}
@@ -78,12 +317,75 @@ func (s *UserBanService) UnbanUser(userID string, permissionID uint) error {
 		return fmt.Errorf("invalid permission ID")
 	}
 
-	existingBan, err := s.userBanRepo.GetByUserIDAndPermission(userID, permissionID)
+	existingBan, err := s.userBanRepo.GetByUserIDAndPermission(userID, permissionID, opts.Scope, opts.Kind)
 	if err != nil {
 		return fmt.Errorf("ban not found: %w", err)
 	}
 
-	return s.userBanRepo.Delete(existingBan.ID)
+	return s.LiftBan(ctx, existingBan.ID, opts.LiftReason)
+}
+
+// UnbanUserGlobally lifts a global ban of a user in the given scope, selected like UnbanUser
+func (s *UserBanService) UnbanUserGlobally(ctx context.Context, userID string, opts UnbanOptions) error {
+	if userID == "" {
+		return fmt.Errorf("user ID cannot be empty")
+	}
+
+	existingBan, err := s.userBanRepo.GetGlobalBan(userID, opts.Scope, opts.Kind)
+	if err != nil {
+		return fmt.Errorf("ban not found: %w", err)
+	}
+
+	return s.LiftBan(ctx, existingBan.ID, opts.LiftReason)
+}
+
+// LiftBan ends an active ban identified by its ID, recording the actor of ctx as its lifter and why
+func (s *UserBanService) LiftBan(ctx context.Context, id uint, reason string) error {
+	if id == 0 {
+		return fmt.Errorf("invalid ban ID")
+	}
+
+	var lifted *models.UserBan
+	err := s.transactor.Transaction(func(tx *repositories.Repositories) error {
+		var err error
+		lifted, err = liftBan(ctx, tx, id, reason)
+		return err
+	})
+	if err != nil {
+		return err
+	}
+
+	s.cache.InvalidateBans(ctx, lifted.UserID)
+	s.publisher.Publish(ctx, changeEvent(changes.EntityUserBan, id, lifted.UserID, changes.OperationDelete))
+	return nil
+}
+
+// liftBan lifts a ban within the transaction of tx on behalf of the actor of ctx and records the
+// change. The caller invalidates the cached bans of the returned ban's user and announces the
+// change once tx commits.
+func liftBan(ctx context.Context, tx *repositories.Repositories, id uint, reason string) (*models.UserBan, error) {
+	before, err := tx.UserBan.GetByID(id)
+	if err != nil {
+		return nil, fmt.Errorf("failed to lift ban: %w", err)
+	}
+
+	if err := tx.UserBan.Lift(id, requestctx.Actor(ctx), reason, time.Now()); err != nil {
+		return nil, fmt.Errorf("failed to lift ban: %w", err)
+	}
+
+	after, err := tx.UserBan.GetByID(id)
+	if err != nil {
+		return nil, fmt.Errorf("failed to lift ban: %w", err)
+	}
+
+	if err := recordEvent(ctx, tx, models.EventUserUnbanned, models.AggregateUserBan, auditID(id), after); err != nil {
+		return nil, err
+	}
+	if err := recordChange(ctx, tx, models.AuditActionBanLift, models.AuditTargetUserBan, auditID(id), before, after); err != nil {
+		return nil, err
+	}
+
+	return after, nil
 }
 
 func (s *UserBanService) GetUserBan(id uint) (*models.UserBan, error) {
@@ -99,33 +401,85 @@ func (s *UserBanService) GetUserBan(id uint) (*models.UserBan, error) {
 	return userBan, nil
 }
 
-func (s *UserBanService) GetUserBans(userID string) ([]models.UserBan, error) {
+// GetUserBans returns the active bans of a user, or every ban they have had when includeHistory is set.
+// hideShadowBans leaves out shadow bans, for showing a user their own bans.
+func (s *UserBanService) GetUserBans(userID string, includeHistory, hideShadowBans bool) ([]models.UserBan, error) {
 	if userID == "" {
 		return nil, fmt.Errorf("user ID cannot be empty")
 	}
 
-	return s.userBanRepo.GetByUserID(userID)
+	var userBans []models.UserBan
+	var err error
+	if includeHistory {
+		userBans, err = s.userBanRepo.GetByUserID(userID)
+	} else {
+		userBans, err = s.userBanRepo.GetActiveUserBans(userID)
+	}
+	if err != nil || !hideShadowBans {
+		return userBans, err
+	}
+
+	visible := make([]models.UserBan, 0, len(userBans))
+	for _, userBan := range userBans {
+		if userBan.Kind != models.BanKindShadowBan {
+			visible = append(visible, userBan)
+		}
+	}
+
+	return visible, nil
 }
 
-func (s *UserBanService) GetAllUserBans() ([]models.UserBan, error) {
-	return s.userBanRepo.GetAll()
+// GetAllUserBans returns the bans matching the filter, e.g. only global bans or only bans issued
+// with a given reason code
+func (s *UserBanService) GetAllUserBans(filter repositories.UserBanFilter) ([]models.UserBan, error) {
+	switch filter.Type {
+	case "", models.BanTypeGlobal, models.BanTypePermission:
+	default:
+		return nil, fmt.Errorf("ban type must be '%s' or '%s'", models.BanTypeGlobal, models.BanTypePermission)
+	}
+
+	return s.userBanRepo.Find(filter)
 }
 
-func (s *UserBanService) IsUserBanned(userID string, permissionID uint) (bool, error) {
+// IsUserBanned reports whether a ban that withholds the permission applies to it in the given scope
+func (s *UserBanService) IsUserBanned(userID string, permissionID uint, scope models.ResourceScope) (bool, error) {
+	ban, err := s.CheckUserBan(userID, permissionID, scope, false)
+	if err != nil {
+		return false, err
+	}
+
+	return ban != nil && ban.Blocks(), nil
+}
+
+// CheckUserBan returns the most severe ban of any kind that applies to the permission in the
+// given scope, or nil if there is none. hideShadowBans passes over shadow bans, for telling a
+// user about their own bans.
+func (s *UserBanService) CheckUserBan(userID string, permissionID uint, scope models.ResourceScope, hideShadowBans bool) (*models.UserBan, error) {
 	if userID == "" {
-		return false, fmt.Errorf("user ID cannot be empty")
+		return nil, fmt.Errorf("user ID cannot be empty")
 	}
 
 	if permissionID == 0 {
-		return false, fmt.Errorf("invalid permission ID")
+		return nil, fmt.Errorf("invalid permission ID")
 	}
 
-	_, err := s.userBanRepo.GetByUserIDAndPermission(userID, permissionID)
+	bans, err := s.userBanRepo.GetMatchingBans([]string{userID}, []uint{permissionID})
 	if err != nil {
-		return false, nil
+		return nil, fmt.Errorf("failed to check user bans: %w", err)
+	}
+
+	var mostSevere *models.UserBan
+	for i := range bans {
+		ban := &bans[i].Ban
+		if !ban.Scope().Covers(scope) || (hideShadowBans && ban.Kind == models.BanKindShadowBan) {
+			continue
+		}
+		if mostSevere == nil || models.BanKindSeverity(ban.Kind) > models.BanKindSeverity(mostSevere.Kind) {
+			mostSevere = ban
+		}
 	}
 
-	return true, nil
+	return mostSevere, nil
 }
 
 func (s *UserBanService) GetActiveUserBans(userID string) ([]models.UserBan, error) {
@@ -133,7 +487,7 @@ func (s *UserBanService) GetActiveUserBans(userID string) ([]models.UserBan, err
 		return nil, fmt.Errorf("user ID cannot be empty")
 	}
 
-	return s.userBanRepo.GetByUserID(userID)
+	return s.userBanRepo.GetActiveUserBans(userID)
 }
 
 func (s *UserBanService) GetRecentBans(days int, limit int) ([]models.UserBan, error) {
@@ -162,7 +516,7 @@ func (s *UserBanService) GetRecentBans(days int, limit int) ([]models.UserBan, e
 	return recentBans, nil
 }
 
-func (s *UserBanService) UpdateBanReason(id uint, reason string) error {
+func (s *UserBanService) UpdateBanReason(ctx context.Context, id uint, reason string) error {
 	if id == 0 {
 		return fmt.Errorf("invalid ban ID")
 	}
@@ -176,8 +530,67 @@ func (s *UserBanService) UpdateBanReason(id uint, reason string) error {
 		return fmt.Errorf("user ban not found: %w", err)
 	}
 
+	before := *userBan
 	userBan.Reason = reason
 	userBan.UpdatedAt = time.Now()
 
-	return s.userBanRepo.Update(userBan)
-}
\ No newline at end of file
+	err = s.transactor.Transaction(func(tx *repositories.Repositories) error {
+		if err := tx.UserBan.Update(userBan); err != nil {
+			return err
+		}
+		if err := recordEvent(ctx, tx, models.EventUserBanUpdated, models.AggregateUserBan, auditID(id), userBan); err != nil {
+			return err
+		}
+		return recordChange(ctx, tx, models.AuditActionBanUpdateReason, models.AuditTargetUserBan, auditID(id), before, userBan)
+	})
+	if err != nil {
+		return err
+	}
+
+	s.cache.InvalidateBans(ctx, userBan.UserID)
+	s.publisher.Publish(ctx, changeEvent(changes.EntityUserBan, id, userBan.UserID, changes.OperationUpdate))
+	return nil
+}
+
+// ExpireBans finalises the temporary bans that have run out, keeping them as history.
+// A sweep that finalises any bans is audited as a single entry carrying the count, while each
+// finalised ban gets its own outbox event and is announced as deleted. Cached bans are left
+// alone; the cache drops bans that have run out when it reads them.
+func (s *UserBanService) ExpireBans(ctx context.Context) (int64, error) {
+	now := time.Now()
+
+	var expired []models.UserBan
+	err := s.transactor.Transaction(func(tx *repositories.Repositories) error {
+		var err error
+		expired, err = tx.UserBan.ExpireBans(now)
+		if err != nil || len(expired) == 0 {
+			return err
+		}
+		for i := range expired {
+			if err := recordEvent(ctx, tx, models.EventUserBanExpired, models.AggregateUserBan, auditID(expired[i].ID), expired[i]); err != nil {
+				return err
+			}
+		}
+		return recordChange(ctx, tx, models.AuditActionBanExpire, models.AuditTargetUserBan, "", nil,
+			map[string]interface{}{"expired": len(expired), "expired_at": now})
+	})
+	if err != nil {
+		return 0, fmt.Errorf("failed to expire bans: %w", err)
+	}
+
+	events := make([]changes.Event, 0, len(expired))
+	for _, ban := range expired {
+		events = append(events, changeEvent(changes.EntityUserBan, ban.ID, ban.UserID, changes.OperationDelete))
+	}
+	s.publisher.Publish(ctx, events...)
+
+	return int64(len(expired)), nil
+}
+
+func uniqueIDs(ids []uint) map[uint]bool {
+	unique := make(map[uint]bool, len(ids))
+	for _, id := range ids {
+		unique[id] = true
+	}
+	return unique
+}
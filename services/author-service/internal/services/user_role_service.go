@@ -0,0 +1,137 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"gin/internal/models"
+	"gin/internal/repositories"
+	"gin/pkg/changes"
+	"time"
+)
+
+type UserRoleServiceInterface interface {
+	AssignRole(ctx context.Context, userID string, roleID uint) (*models.UserRole, error)
+	RemoveRole(ctx context.Context, userID string, roleID uint) error
+	GetUserRoles(userID string) ([]models.UserRole, error)
+	GetRoleUsers(roleID uint) ([]models.UserRole, error)
+}
+
+type UserRoleService struct {
+	userRoleRepo repositories.UserRoleRepositoryInterface
+	roleRepo     repositories.RoleRepositoryInterface
+	transactor   repositories.Transactor
+	cache        AuthorizationCacheInvalidator
+	publisher    ChangePublisher
+}
+
+// NewUserRoleService creates a user role service; assignments are made through transactor
+// together with their outbox events
+func NewUserRoleService(userRoleRepo repositories.UserRoleRepositoryInterface, roleRepo repositories.RoleRepositoryInterface, transactor repositories.Transactor, cache AuthorizationCacheInvalidator, publisher ChangePublisher) UserRoleServiceInterface {
+	return &UserRoleService{
+		userRoleRepo: userRoleRepo,
+		roleRepo:     roleRepo,
+		transactor:   transactor,
+		cache:        cache,
+		publisher:    publisher,
+	}
+}
+
+func (s *UserRoleService) AssignRole(ctx context.Context, userID string, roleID uint) (*models.UserRole, error) {
+	if userID == "" {
+		return nil, fmt.Errorf("user ID cannot be empty")
+	}
+
+	if roleID == 0 {
+		return nil, fmt.Errorf("invalid role ID")
+	}
+
+	role, err := s.roleRepo.GetByID(roleID)
+	if err != nil {
+		return nil, fmt.Errorf("role not found: %w", err)
+	}
+
+	existingUserRole, err := s.userRoleRepo.GetByUserIDAndRole(userID, roleID)
+	if err == nil && existingUserRole != nil {
+		return nil, fmt.Errorf("role already assigned to user")
+	}
+
+	userRole := &models.UserRole{
+		UserID:    userID,
+		RoleID:    roleID,
+		CreatedAt: time.Now(),
+	}
+
+	err = s.transactor.Transaction(func(tx *repositories.Repositories) error {
+		if err := tx.UserRole.Create(userRole); err != nil {
+			return fmt.Errorf("failed to assign role: %w", err)
+		}
+		return recordEvent(ctx, tx, models.EventUserRoleAssigned, models.AggregateUserRole, auditID(userRole.ID), userRoleEvent(userRole.ID, userID, role))
+	})
+	if err != nil {
+		return nil, err
+	}
+	s.cache.InvalidateGrants(ctx, userID)
+	s.publisher.Publish(ctx, changeEvent(changes.EntityUserRole, userRole.ID, userID, changes.OperationCreate))
+
+	userRole.Role = *role
+	return userRole, nil
+}
+
+func (s *UserRoleService) RemoveRole(ctx context.Context, userID string, roleID uint) error {
+	if userID == "" {
+		return fmt.Errorf("user ID cannot be empty")
+	}
+
+	if roleID == 0 {
+		return fmt.Errorf("invalid role ID")
+	}
+
+	existingUserRole, err := s.userRoleRepo.GetByUserIDAndRole(userID, roleID)
+	if err != nil {
+		return fmt.Errorf("role not assigned to user: %w", err)
+	}
+
+	role, err := s.roleRepo.GetByID(roleID)
+	if err != nil {
+		return fmt.Errorf("role not found: %w", err)
+	}
+
+	err = s.transactor.Transaction(func(tx *repositories.Repositories) error {
+		if err := tx.UserRole.Delete(existingUserRole.ID); err != nil {
+			return err
+		}
+		return recordEvent(ctx, tx, models.EventUserRoleRemoved, models.AggregateUserRole, auditID(existingUserRole.ID), userRoleEvent(existingUserRole.ID, userID, role))
+	})
+	if err != nil {
+		return err
+	}
+
+	s.cache.InvalidateGrants(ctx, userID)
+	s.publisher.Publish(ctx, changeEvent(changes.EntityUserRole, existingUserRole.ID, userID, changes.OperationDelete))
+	return nil
+}
+
+func (s *UserRoleService) GetUserRoles(userID string) ([]models.UserRole, error) {
+	if userID == "" {
+		return nil, fmt.Errorf("user ID cannot be empty")
+	}
+
+	return s.userRoleRepo.GetByUserID(userID)
+}
+
+func (s *UserRoleService) GetRoleUsers(roleID uint) ([]models.UserRole, error) {
+	if roleID == 0 {
+		return nil, fmt.Errorf("invalid role ID")
+	}
+
+	if _, err := s.roleRepo.GetByID(roleID); err != nil {
+		return nil, fmt.Errorf("role not found: %w", err)
+	}
+
+	return s.userRoleRepo.GetByRoleID(roleID)
+}
+
+// userRoleEvent is the payload of the outbox events of a role assignment
+func userRoleEvent(id uint, userID string, role *models.Role) map[string]interface{} {
+	return map[string]interface{}{"id": id, "user_id": userID, "role_id": role.RoleID, "role_name": role.Name}
+}
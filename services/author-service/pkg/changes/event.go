@@ -0,0 +1,57 @@
+// Package changes carries the change events the authorization service publishes over Redis
+// pub/sub after every committed change to roles, permissions, bans and role assignments, so that
+// services caching permissions can drop stale entries.
+//
+// Consumers run a Subscriber:
+//
+//	subscriber := changes.NewSubscriber(client, changes.DefaultChannel)
+//	subscriber.OnEvent = func(event changes.Event) {
+//		if event.AffectsAllUsers() {
+//			permissionCache.Clear()
+//			return
+//		}
+//		permissionCache.Drop(event.UserID)
+//	}
+//	subscriber.OnGap = permissionCache.Clear
+//	go subscriber.Run(ctx)
+package changes
+
+// DefaultChannel is the Redis channel events are published on
+const DefaultChannel = "authorization:changes"
+
+// versionKeySuffix names the key counting the events published on a channel
+const versionKeySuffix = ":version"
+
+// Entities an event can be about
+const (
+	EntityRole       = "role"
+	EntityPermission = "permission"
+	EntityUserBan    = "user_ban"
+	EntityUserRole   = "user_role"
+)
+
+// Operations an event can report. A ban that is lifted or runs out is reported as deleted,
+// since it no longer applies even though it is kept as history.
+const (
+	OperationCreate = "create"
+	OperationUpdate = "update"
+	OperationDelete = "delete"
+)
+
+// Event reports a committed change. Version increases by one with every event published on
+// the channel, so a consumer that sees it skip has missed events.
+type Event struct {
+	Entity string `json:"entity"`
+	ID     string `json:"id"`
+	// UserID is the user whose permissions changed; it is empty for changes to roles and
+	// permissions, which affect every user holding them
+	UserID    string `json:"user_id,omitempty"`
+	Operation string `json:"operation"`
+	Version   int64  `json:"version"`
+}
+
+// AffectsAllUsers reports whether the change can affect the permissions of any user, rather
+// than those of UserID only
+func (e Event) AffectsAllUsers() bool {
+	return e.UserID == ""
+}
@@ -0,0 +1,52 @@
+package changes
+
+import (
+	"context"
+	"encoding/json"
+	"log"
+
+	"github.com/redis/go-redis/v9"
+)
+
+// publishScript numbers an event and publishes it in one step, so that events reach
+// subscribers in version order whichever instance publishes them
+var publishScript = redis.NewScript(`
+local event = cjson.decode(ARGV[1])
+event.version = redis.call('INCR', KEYS[1])
+redis.call('PUBLISH', ARGV[2], cjson.encode(event))
+return event.version
+`)
+
+// Publisher publishes change events on a Redis channel.
+// A nil Publisher publishes nothing.
+type Publisher struct {
+	client  *redis.Client
+	channel string
+}
+
+// NewPublisher creates a publisher for the given channel
+func NewPublisher(client *redis.Client, channel string) *Publisher {
+	return &Publisher{client: client, channel: channel}
+}
+
+// Publish numbers and publishes the events in order. Delivery is best effort: failures are
+// logged, and subscribers notice the events they missed from the gap in versions.
+func (p *Publisher) Publish(ctx context.Context, events ...Event) {
+	if p == nil || len(events) == 0 {
+		return
+	}
+
+	pipe := p.client.Pipeline()
+	for _, event := range events {
+		encoded, err := json.Marshal(event)
+		if err != nil {
+			log.Printf("Failed to encode change event: %v", err)
+			return
+		}
+		publishScript.Eval(ctx, pipe, []string{p.channel + versionKeySuffix}, encoded, p.channel)
+	}
+
+	if _, err := pipe.Exec(ctx); err != nil {
+		log.Printf("Failed to publish %d change events: %v", len(events), err)
+	}
+}
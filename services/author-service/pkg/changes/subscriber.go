@@ -0,0 +1,84 @@
+package changes
+
+import (
+	"context"
+	"encoding/json"
+	"log"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+)
+
+// retryDelay spaces out attempts to receive after the connection failed
+const retryDelay = time.Second
+
+// Subscriber receives change events from a Redis channel
+type Subscriber struct {
+	client  *redis.Client
+	channel string
+
+	// OnEvent is called with every event, in version order
+	OnEvent func(event Event)
+	// OnGap is called whenever events may have been missed: when the subscription is
+	// established or re-established after a dropped connection, and when versions skip.
+	// Consumers should drop everything they cached.
+	OnGap func()
+}
+
+// NewSubscriber creates a subscriber for the given channel
+func NewSubscriber(client *redis.Client, channel string) *Subscriber {
+	return &Subscriber{client: client, channel: channel}
+}
+
+// Run receives events until ctx is done. The connection is re-established as needed.
+func (s *Subscriber) Run(ctx context.Context) error {
+	pubsub := s.client.Subscribe(ctx, s.channel)
+	defer pubsub.Close()
+
+	var lastVersion int64
+	for {
+		message, err := pubsub.Receive(ctx)
+		if err != nil {
+			if ctx.Err() != nil {
+				return ctx.Err()
+			}
+			// The client reconnects and resubscribes on the next Receive
+			log.Printf("Change subscription to %s failed: %v", s.channel, err)
+			select {
+			case <-ctx.Done():
+				return ctx.Err()
+			case <-time.After(retryDelay):
+			}
+			continue
+		}
+
+		switch message := message.(type) {
+		case *redis.Subscription:
+			if message.Kind == "subscribe" {
+				lastVersion = 0
+				s.gap()
+			}
+		case *redis.Message:
+			var event Event
+			if err := json.Unmarshal([]byte(message.Payload), &event); err != nil {
+				log.Printf("Ignoring malformed change event: %v", err)
+				continue
+			}
+
+			if lastVersion != 0 && event.Version != lastVersion+1 {
+				s.gap()
+			}
+			lastVersion = event.Version
+
+			if s.OnEvent != nil {
+				s.OnEvent(event)
+			}
+		}
+	}
+}
+
+func (s *Subscriber) gap() {
+	if s.OnGap != nil {
+		s.OnGap()
+	}
+}